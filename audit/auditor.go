@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Panicked when creating an auditor with a nil writer.
+var ErrNilWriter = errors.New("audit writer is nil")
+
+// A writer chains and appends records (e.g. a *FileLog).
+type Writer interface {
+	Append(record Record) (Record, error)
+}
+
+// Describes a credential as an actor: its type and index (or
+// its identifier, if it is not indexed). Returns an empty
+// string for nil credentials.
+func Actor(credential credentials.Credential) string {
+	if credential == nil {
+		return ""
+	} else if indexedCred, ok := credential.(indexed.Indexed); ok {
+		return fmt.Sprintf("%T/%v", credential, indexedCred.Index())
+	} else if identifiedCred, ok := credential.(identified.Identified); ok {
+		return fmt.Sprintf("%T/%v", credential, identifiedCred.Identification())
+	} else {
+		return fmt.Sprintf("%T", credential)
+	}
+}
+
+// An auditor records the realm events (see Handle), and any
+// other action of the application (see Record), in a writer.
+// Since handlers cannot fail, errors from the writer are given
+// to OnError, if set.
+type Auditor struct {
+	Writer  Writer
+	OnError func(err error)
+}
+
+// Creates a new auditor.
+func NewAuditor(writer Writer) *Auditor {
+	if writer == nil {
+		panic(ErrNilWriter)
+	}
+	return &Auditor{Writer: writer}
+}
+
+// Makes a record for an action on a credential.
+func makeRecord(at time.Time, actor, action string, credential credentials.Credential, details map[string]string) Record {
+	record := Record{Time: at, Actor: actor, Action: action, Details: details}
+	if credential != nil {
+		record.Type = fmt.Sprintf("%T", credential)
+		if indexedCred, ok := credential.(indexed.Indexed); ok {
+			record.Index = fmt.Sprintf("%v", indexedCred.Index())
+		}
+		if identifiedCred, ok := credential.(identified.Identified); ok {
+			record.Identifier = fmt.Sprintf("%v", identifiedCred.Identification())
+		}
+	}
+	return record
+}
+
+// Records an action done now by an actor (see Actor) on a
+// credential (which may be nil).
+func (auditor *Auditor) Record(actor, action string, credential credentials.Credential, details map[string]string) error {
+	_, err := auditor.Writer.Append(makeRecord(time.Now(), actor, action, credential, details))
+	return err
+}
+
+// Adds the context of a login attempt to the details.
+func attemptDetails(details map[string]string, attempt *login.Attempt) map[string]string {
+	if attempt == nil {
+		return details
+	}
+	if attempt.ClientIP != nil {
+		details["ip"] = attempt.ClientIP.String()
+	}
+	if attempt.UserAgent != "" {
+		details["user_agent"] = attempt.UserAgent
+	}
+	if attempt.DeviceID != "" {
+		details["device_id"] = attempt.DeviceID
+	}
+	if attempt.Realm != "" {
+		details["realm"] = attempt.Realm
+	}
+	return details
+}
+
+// Records a realm event. Subscribe it to the realms to audit
+// (e.g. realm.Subscribe(auditor.Handle)). Actors are the
+// credentials themselves for logins, user password changes,
+// reset confirmations and TOTP enrollments, and the ones given
+// to the realm calls for the other changes (e.g. the punisher,
+// or none for lockouts). Reset tokens, recovery codes and the
+// anonymized data are never recorded.
+func (auditor *Auditor) Handle(event events.Event) {
+	var record Record
+	switch current := event.(type) {
+	case *events.LoginSucceeded:
+		record = makeRecord(current.Time, Actor(current.Credential), LoginSucceeded, current.Credential,
+			attemptDetails(map[string]string{}, current.Attempt))
+	case *events.LoginFailed:
+		details := attemptDetails(map[string]string{}, current.Attempt)
+		if current.Reason != nil {
+			details["reason"] = current.Reason.Error()
+		}
+		if current.Step != nil {
+			details["step"] = fmt.Sprintf("%T", current.Step)
+		}
+		record = makeRecord(current.Time, "", LoginFailed, current.Credential, details)
+		if current.Credential == nil && current.Identifier != nil {
+			record.Identifier = fmt.Sprintf("%v", current.Identifier)
+		}
+	case *events.PasswordChanged:
+		actor := Actor(current.By)
+		if current.ByUser {
+			actor = Actor(current.Credential)
+		}
+		record = makeRecord(current.Time, actor, PasswordChanged, current.Credential,
+			map[string]string{"by_user": strconv.FormatBool(current.ByUser)})
+	case *events.PasswordUnset:
+		record = makeRecord(current.Time, Actor(current.By), PasswordUnset, current.Credential, nil)
+	case *events.ResetPrepared:
+		record = makeRecord(current.Time, Actor(current.By), ResetPrepared, current.Credential,
+			map[string]string{"duration": current.Duration.String()})
+	case *events.ResetConfirmed:
+		record = makeRecord(current.Time, Actor(current.Credential), ResetConfirmed, current.Credential, nil)
+	case *events.ResetCancelled:
+		record = makeRecord(current.Time, Actor(current.By), ResetCancelled, current.Credential, nil)
+	case *events.Punished:
+		details := map[string]string{"duration": "permanent"}
+		if current.Duration != nil {
+			details["duration"] = current.Duration.String()
+		}
+		if current.Reason != nil {
+			details["reason"] = fmt.Sprintf("%v", current.Reason)
+		}
+		record = makeRecord(current.Time, Actor(current.By), Punished, current.Credential, details)
+	case *events.Unpunished:
+		record = makeRecord(current.Time, Actor(current.By), Unpunished, current.Credential, nil)
+	case *events.ActivationChanged:
+		record = makeRecord(current.Time, Actor(current.By), ActivationChanged, current.Credential,
+			map[string]string{"active": strconv.FormatBool(current.Active)})
+	case *events.ScopeGranted:
+		record = makeRecord(current.Time, Actor(current.By), ScopeGranted, current.Credential,
+			map[string]string{"scope": current.Scope})
+	case *events.ScopeRevoked:
+		record = makeRecord(current.Time, Actor(current.By), ScopeRevoked, current.Credential,
+			map[string]string{"scope": current.Scope})
+	case *events.TOTPEnrolled:
+		record = makeRecord(current.Time, Actor(current.Credential), TOTPEnrolled, current.Credential, nil)
+	case *events.TOTPDisabled:
+		record = makeRecord(current.Time, Actor(current.By), TOTPDisabled, current.Credential, nil)
+	case *events.RecoveryCodesGenerated:
+		record = makeRecord(current.Time, "", RecoveryCodesGenerated, current.Credential,
+			map[string]string{"count": strconv.Itoa(current.Count)})
+	case *events.WebAuthnRegistered:
+		record = makeRecord(current.Time, "", WebAuthnRegistered, current.Credential,
+			map[string]string{"id": base64.RawURLEncoding.EncodeToString(current.ID)})
+	case *events.Anonymized:
+		record = makeRecord(current.Time, "", Anonymized, current.Credential,
+			map[string]string{"erased": strings.Join(current.Erased, ",")})
+	default:
+		return
+	}
+	if _, err := auditor.Writer.Append(record); err != nil && auditor.OnError != nil {
+		auditor.OnError(err)
+	}
+}
@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// The suffix of the head file of a file log.
+const HeadSuffix = ".head"
+
+// Returned when a log has records but its head file does not
+// exist (e.g. it was removed to hide a truncation).
+var ErrHeadMissing = errors.New("audit log head is missing")
+
+// Returned when appending to a file log that could not be
+// truncated back to its last good record after a failure.
+var ErrUnusable = errors.New("audit log is unusable")
+
+// A file log appends the records to a file (one JSON per line),
+// and keeps its head in a separate file (the same path, with the
+// HeadSuffix), which is replaced atomically after each append.
+//
+// The head file only detects truncations made by someone who
+// cannot write to the log's directory: whoever can, may replace
+// both the log and its head. So the head (see Head) must also be
+// anchored outside that directory (e.g. periodically copied to
+// another system), and the log verified against that copy (see
+// Verify).
+type FileLog struct {
+	path  string
+	key   []byte
+	mutex sync.Mutex
+	file  *os.File
+	head  Head
+	// The size of the log up to its last good record.
+	offset int64
+	// Set when a failed append could not be undone.
+	broken error
+}
+
+// Reads the head file of a log, if it exists.
+func readHead(path string) (*Head, error) {
+	if content, err := os.ReadFile(path + HeadSuffix); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else {
+		head := &Head{}
+		if err := json.Unmarshal(content, head); err != nil {
+			return nil, ErrMalformed
+		}
+		return head, nil
+	}
+}
+
+// Verifies a file log against its head file, which must exist
+// unless the log is empty (or does not exist). Returns the head
+// of the log, ErrHeadMissing, or a *VerificationError.
+func VerifyFile(path string, key []byte) (Head, error) {
+	head, err := readHead(path)
+	if err != nil {
+		return Head{}, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if head != nil && head.Sequence > 0 {
+			return Head{}, &VerificationError{1, ErrTruncated}
+		}
+		return Head{}, nil
+	} else if err != nil {
+		return Head{}, err
+	}
+	defer file.Close()
+	if head == nil {
+		if info, err := file.Stat(); err != nil {
+			return Head{}, err
+		} else if info.Size() > 0 {
+			return Head{}, ErrHeadMissing
+		}
+	}
+	return Verify(file, key, head, nil)
+}
+
+// Opens a file log to append records, creating it if it does
+// not exist. The existing records are verified first, and the
+// log is not opened if they are not valid. The key is used to
+// compute the hashes (see ComputeHash), and may be nil.
+func OpenFile(path string, key []byte) (*FileLog, error) {
+	head, err := VerifyFile(path, key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileLog{path: path, key: key, file: file, head: head, offset: info.Size()}, nil
+}
+
+// Chains and appends a record, and replaces the head file.
+// Returns the chained record. If any of them fails, the log is
+// truncated back to its last good record, so it can still be
+// verified and appended to. If the truncation also fails, the
+// log becomes unusable: this and every later append fail with
+// an error wrapping ErrUnusable, and the log must be reopened
+// (which verifies it again).
+func (log *FileLog) Append(record Record) (Record, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	if log.broken != nil {
+		return record, log.broken
+	}
+	head := Chain(&record, log.head, log.key)
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return record, err
+	}
+	encoded = append(encoded, '\n')
+	if err := log.write(encoded, head); err != nil {
+		if truncateErr := log.file.Truncate(log.offset); truncateErr != nil {
+			log.broken = fmt.Errorf("%w: %v (after: %v)", ErrUnusable, truncateErr, err)
+			return record, log.broken
+		}
+		return record, err
+	}
+	log.head = head
+	log.offset += int64(len(encoded))
+	return record, nil
+}
+
+// Writes an encoded record and the new head.
+func (log *FileLog) write(encoded []byte, head Head) error {
+	if _, err := log.file.Write(encoded); err != nil {
+		return err
+	}
+	if err := log.file.Sync(); err != nil {
+		return err
+	}
+	return log.writeHead(head)
+}
+
+func (log *FileLog) writeHead(head Head) error {
+	encoded, _ := json.Marshal(head)
+	temporary := log.path + HeadSuffix + ".tmp"
+	if err := os.WriteFile(temporary, encoded, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, log.path+HeadSuffix)
+}
+
+// Returns the head of the log.
+func (log *FileLog) Head() Head {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.head
+}
+
+// Reads the records matching a filter, verifying the log.
+func (log *FileLog) Query(filter Filter) ([]Record, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	file, err := os.Open(log.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var result []Record
+	_, err = Verify(file, log.key, &log.head, func(record Record) {
+		if filter.Matches(record) {
+			result = append(result, record)
+		}
+	})
+	return result, err
+}
+
+// Closes the log file.
+func (log *FileLog) Close() error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.file.Close()
+}
@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// The actions recorded from the realm events. Applications may
+// record other actions by themselves.
+const (
+	LoginSucceeded         = "login_succeeded"
+	LoginFailed            = "login_failed"
+	PasswordChanged        = "password_changed"
+	PasswordUnset          = "password_unset"
+	ResetPrepared          = "reset_prepared"
+	ResetConfirmed         = "reset_confirmed"
+	ResetCancelled         = "reset_cancelled"
+	Punished               = "punished"
+	Unpunished             = "unpunished"
+	ActivationChanged      = "activation_changed"
+	ScopeGranted           = "scope_granted"
+	ScopeRevoked           = "scope_revoked"
+	TOTPEnrolled           = "totp_enrolled"
+	TOTPDisabled           = "totp_disabled"
+	RecoveryCodesGenerated = "recovery_codes_generated"
+	WebAuthnRegistered     = "webauthn_registered"
+	Anonymized             = "anonymized"
+)
+
+// Returned when a record cannot be parsed (e.g. a line was
+// partially written or edited).
+var ErrMalformed = errors.New("malformed audit record")
+
+// Returned when a record does not follow the previous one
+// (e.g. a record was removed, inserted or reordered).
+var ErrBrokenChain = errors.New("audit record does not follow the previous one")
+
+// Returned when the hash of a record does not match its
+// contents (e.g. a record was modified).
+var ErrModified = errors.New("audit record was modified")
+
+// Returned when the log has fewer records than its head
+// tells (e.g. it was truncated).
+var ErrTruncated = errors.New("audit log was truncated")
+
+// Returned by a verification, telling the sequence number of
+// the first invalid record (or the expected one, for truncated
+// logs) and the reason.
+type VerificationError struct {
+	Sequence uint64
+	Err      error
+}
+
+func (error *VerificationError) Error() string {
+	return fmt.Sprintf("audit record %d: %s", error.Sequence, error.Err)
+}
+
+func (error *VerificationError) Unwrap() error {
+	return error.Err
+}
+
+// An audit record tells who (the actor) did what (the action)
+// to which credential (its type, index and identifier), when,
+// and the details. Records are chained: each one holds the hash
+// of the previous one, and its own hash (covering all the other
+// fields), so removing or modifying any of them is detected.
+type Record struct {
+	Sequence   uint64            `json:"seq"`
+	Time       time.Time         `json:"time"`
+	Actor      string            `json:"actor,omitempty"`
+	Action     string            `json:"action"`
+	Type       string            `json:"type,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Identifier string            `json:"identifier,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	Previous   string            `json:"prev,omitempty"`
+	Hash       string            `json:"hash"`
+}
+
+// The head of a log: the sequence number and hash of its last
+// record. Keeping it apart from the log allows to detect the
+// truncation of the log.
+type Head struct {
+	Sequence uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+}
+
+// Computes the hash of a record: a SHA-256 of its contents, or
+// an HMAC-SHA-256 if a key is given (so records cannot be forged
+// without the key).
+func ComputeHash(record Record, key []byte) string {
+	record.Hash = ""
+	encoded, _ := json.Marshal(record)
+	var hasher hash.Hash
+	if len(key) > 0 {
+		hasher = hmac.New(sha256.New, key)
+	} else {
+		hasher = sha256.New()
+	}
+	hasher.Write(encoded)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Chains a record after the given head, setting its sequence
+// number, previous hash and hash. Returns the new head.
+func Chain(record *Record, head Head, key []byte) Head {
+	record.Sequence = head.Sequence + 1
+	record.Previous = head.Hash
+	record.Time = record.Time.UTC()
+	record.Hash = ComputeHash(*record, key)
+	return Head{record.Sequence, record.Hash}
+}
+
+// Reads the records of a log (one JSON per line), verifying
+// the chain and invoking the callback for each of them. If a
+// head is given, the log must reach it. Returns the head of
+// the log, or a *VerificationError.
+func Verify(reader io.Reader, key []byte, head *Head, callback func(Record)) (Head, error) {
+	current := Head{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	reached := head == nil || head.Sequence == 0
+	for scanner.Scan() {
+		expected := current.Sequence + 1
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return current, &VerificationError{expected, ErrMalformed}
+		}
+		if record.Sequence != expected || record.Previous != current.Hash {
+			return current, &VerificationError{expected, ErrBrokenChain}
+		}
+		if !hmac.Equal([]byte(ComputeHash(record, key)), []byte(record.Hash)) {
+			return current, &VerificationError{expected, ErrModified}
+		}
+		current = Head{record.Sequence, record.Hash}
+		if head != nil && current.Sequence == head.Sequence {
+			if current.Hash != head.Hash {
+				return current, &VerificationError{expected, ErrModified}
+			}
+			reached = true
+		}
+		if callback != nil {
+			callback(record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return current, err
+	}
+	if !reached {
+		return current, &VerificationError{current.Sequence + 1, ErrTruncated}
+	}
+	return current, nil
+}
+
+// A filter for audit records. Empty fields match any record,
+// and the time range includes From but excludes To.
+type Filter struct {
+	Type    string
+	Index   string
+	Actions []string
+	From    time.Time
+	To      time.Time
+}
+
+// Tells whether a record matches the filter.
+func (filter Filter) Matches(record Record) bool {
+	if filter.Type != "" && record.Type != filter.Type {
+		return false
+	}
+	if filter.Index != "" && record.Index != filter.Index {
+		return false
+	}
+	if !filter.From.IsZero() && record.Time.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !record.Time.Before(filter.To) {
+		return false
+	}
+	if len(filter.Actions) == 0 {
+		return true
+	}
+	for _, action := range filter.Actions {
+		if record.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads the records of a log matching a filter, verifying the
+// chain (see Verify).
+func Query(reader io.Reader, key []byte, filter Filter) ([]Record, error) {
+	var result []Record
+	_, err := Verify(reader, key, nil, func(record Record) {
+		if filter.Matches(record) {
+			result = append(result, record)
+		}
+	})
+	return result, err
+}
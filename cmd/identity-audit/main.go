@@ -0,0 +1,80 @@
+// This command verifies an audit log (see the package audit)
+// against its head file and prints the records matching the
+// given filters, one JSON record per line, e.g.:
+//
+//	identity-audit -log audit.jsonl -key-file audit.key -index 42 -from 2024-01-01T00:00:00Z
+//
+// The command exits with status 1 if the log is not valid
+// (e.g. it was truncated or modified).
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/universe-10th/identity/audit"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Parses an optional RFC 3339 time.
+func parseTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	} else if parsed, err := time.Parse(time.RFC3339, value); err != nil {
+		log.Fatal(err)
+		return time.Time{}
+	} else {
+		return parsed
+	}
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("identity-audit: ")
+	logPath := flag.String("log", "", "the audit log to verify")
+	keyPath := flag.String("key-file", "", "the file holding the key of the hashes (default: no key)")
+	filter := audit.Filter{}
+	flag.StringVar(&filter.Type, "type", "", "only print the records of this credential type")
+	flag.StringVar(&filter.Index, "index", "", "only print the records of this credential index")
+	actions := flag.String("actions", "", "only print the records of these comma-separated actions")
+	from := flag.String("from", "", "only print the records since this RFC 3339 time")
+	to := flag.String("to", "", "only print the records before this RFC 3339 time")
+	flag.Parse()
+
+	if *logPath == "" {
+		log.Fatal("the -log flag is required")
+	}
+	var key []byte
+	if *keyPath != "" {
+		content, err := os.ReadFile(*keyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key = []byte(strings.TrimSpace(string(content)))
+	}
+	if *actions != "" {
+		filter.Actions = strings.Split(*actions, ",")
+	}
+	filter.From, filter.To = parseTime(*from), parseTime(*to)
+
+	if _, err := audit.VerifyFile(*logPath, key); err != nil {
+		log.Fatal(err)
+	}
+	file, err := os.Open(*logPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	records, err := audit.Query(file, key, filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// This command generates the methods of the credential traits
+// for a struct type annotated with `identity:"..."` tags (the
+// same ones supported by the credentials/tagged package). It is
+// meant to be invoked via go generate, e.g.:
+//
+//     //go:generate go run github.com/universe-10th/identity/cmd/identity-gen -type User
+//
+// which reads the package in the current directory and writes
+// the user_identity.go file. The methods are generated for the
+// pointer type. The Hasher() method is only generated when the
+// -hasher flag is given (with the expression to return), so it
+// must be written by hand otherwise.
+//
+// The tagged fields must have the same types required by the
+// credentials/tagged package, except for the punished_by field,
+// which must be a pointer (to a credential type) or an interface
+// able to hold credentials. Like in that package, zero reasons
+// and nil punishers are returned as nil by PunishedFor.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The supported tags. See the credentials/tagged package.
+var knownTags = map[string]bool{
+	"password": true, "active": true, "recovery_token": true, "recovery_expiration": true,
+	"identifier": true, "index": true, "scopes": true, "staff": true, "superuser": true,
+	"punished_on": true, "punished_for": true, "punishment_reason": true, "punished_by": true,
+	"version": true,
+}
+
+// The expected field type for each tag, as written in the source.
+// Tags not listed here accept any type, except punished_by, which
+// must be able to hold a credential (see punisherTypes).
+var tagTypes = map[string]string{
+	"password": "string", "active": "bool", "recovery_token": "string", "recovery_expiration": "time.Time",
+	"scopes": "map[string]scoped.Scope", "staff": "bool", "superuser": "bool",
+	"punished_on": "*time.Time", "punished_for": "*time.Duration", "version": "uint64",
+}
+
+// The interface types a punished_by field may have, besides the
+// pointer types (which must implement credentials.Credential).
+var punisherTypes = map[string]bool{
+	"credentials.Credential": true, "interface{}": true, "any": true,
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+// Tells whether the field is of an interface type that holds nil
+// when unset, so it can be returned as it is. Used by the template.
+func (field field) Interface() bool {
+	return punisherTypes[field.Type]
+}
+
+type spec struct {
+	Package  string
+	Type     string
+	Receiver string
+	Hasher   string
+	Fields   map[string]field
+}
+
+// Tells whether a tagged field is present. Used by the template.
+func (spec *spec) Has(tag string) bool {
+	_, ok := spec.Fields[tag]
+	return ok
+}
+
+// Returns the tagged field. Used by the template.
+func (spec *spec) F(tag string) field {
+	return spec.Fields[tag]
+}
+
+// Tells whether the time package must be imported.
+func (spec *spec) NeedsTime() bool {
+	return spec.Has("punished_on") || spec.Has("recovery_token")
+}
+
+// Tells whether the credentials package must be imported.
+func (spec *spec) NeedsCredentials() bool {
+	return spec.Has("punished_on")
+}
+
+// Tells whether the reflect package must be imported (to tell
+// whether a punishment reason is the zero value).
+func (spec *spec) NeedsReflect() bool {
+	return spec.Has("punishment_reason") && !spec.F("punishment_reason").Interface()
+}
+
+func parse(directory, typeName string) (*spec, error) {
+	files, err := filepath.Glob(filepath.Join(directory, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fileSet := token.NewFileSet()
+	for _, filename := range files {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, filename, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, declaration := range file.Decls {
+			general, ok := declaration.(*ast.GenDecl)
+			if !ok || general.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range general.Specs {
+				typeSpec := s.(*ast.TypeSpec)
+				if typeSpec.Name.Name != typeName {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					return nil, fmt.Errorf("type %s is not a struct", typeName)
+				}
+				return parseStruct(fileSet, file.Name.Name, typeName, structType)
+			}
+		}
+	}
+	return nil, fmt.Errorf("type %s not found", typeName)
+}
+
+func parseStruct(fileSet *token.FileSet, packageName, typeName string, structType *ast.StructType) (*spec, error) {
+	result := &spec{
+		Package: packageName, Type: typeName,
+		Receiver: strings.ToLower(typeName[:1]), Fields: map[string]field{},
+	}
+	for _, item := range structType.Fields.List {
+		if item.Tag == nil || len(item.Names) == 0 {
+			continue
+		}
+		rawTag, _ := strconv.Unquote(item.Tag.Value)
+		tag, ok := reflect.StructTag(rawTag).Lookup("identity")
+		if !ok {
+			continue
+		} else if !knownTags[tag] {
+			return nil, fmt.Errorf("field %s: unknown tag %q", item.Names[0].Name, tag)
+		} else if _, ok := result.Fields[tag]; ok || len(item.Names) > 1 {
+			return nil, fmt.Errorf("field %s: duplicate tag %q", item.Names[0].Name, tag)
+		}
+		typeBuffer := &bytes.Buffer{}
+		if err := printer.Fprint(typeBuffer, fileSet, item.Type); err != nil {
+			return nil, err
+		}
+		fieldType := typeBuffer.String()
+		if expected, ok := tagTypes[tag]; ok && fieldType != expected {
+			return nil, fmt.Errorf("field %s: tag %q requires the type %s", item.Names[0].Name, tag, expected)
+		} else if _, pointer := item.Type.(*ast.StarExpr); tag == "punished_by" && !pointer && !punisherTypes[fieldType] {
+			return nil, fmt.Errorf("field %s: tag %q requires a pointer type or credentials.Credential", item.Names[0].Name, tag)
+		}
+		result.Fields[tag] = field{item.Names[0].Name, fieldType}
+	}
+
+	if !result.Has("password") {
+		return nil, errors.New("no field is tagged as password")
+	} else if result.Has("recovery_token") != result.Has("recovery_expiration") {
+		return nil, errors.New("recovery requires both the recovery_token and recovery_expiration fields")
+	} else if result.Has("punished_on") != result.Has("punished_for") ||
+		(result.Has("punishment_reason") || result.Has("punished_by")) && !result.Has("punished_on") {
+		return nil, errors.New("punishment requires both the punished_on and punished_for fields")
+	}
+	return result, nil
+}
+
+var output = template.Must(template.New("output").Parse(`// Code generated by identity-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .NeedsCredentials}}
+	"github.com/universe-10th/identity/credentials"
+{{- end}}
+{{- if .Has "scopes"}}
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+{{- end}}
+{{- if .Hasher}}
+	"github.com/universe-10th/identity/hashing"
+{{- end}}
+{{- if .NeedsReflect}}
+	"reflect"
+{{- end}}
+{{- if .NeedsTime}}
+	"time"
+{{- end}}
+)
+
+{{$r := .Receiver}}{{$t := .Type}}
+func ({{$r}} *{{$t}}) HashedPassword() string {
+	return {{$r}}.{{(.F "password").Name}}
+}
+
+func ({{$r}} *{{$t}}) SetHashedPassword(password string) {
+	{{$r}}.{{(.F "password").Name}} = password
+}
+{{if .Hasher}}
+func ({{$r}} *{{$t}}) Hasher() hashing.HashingEngine {
+	return {{.Hasher}}
+}
+{{end}}
+{{- if .Has "active"}}
+func ({{$r}} *{{$t}}) Active() bool {
+	return {{$r}}.{{(.F "active").Name}}
+}
+
+func ({{$r}} *{{$t}}) SetActive(active bool) {
+	{{$r}}.{{(.F "active").Name}} = active
+}
+{{end}}
+{{- if .Has "punished_on"}}
+func ({{$r}} *{{$t}}) PunishedFor() (punishedOn *time.Time, forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	punishedOn = {{$r}}.{{(.F "punished_on").Name}}
+	forTime = {{$r}}.{{(.F "punished_for").Name}}
+{{- if .Has "punishment_reason"}}
+{{- if (.F "punishment_reason").Interface}}
+	reason = {{$r}}.{{(.F "punishment_reason").Name}}
+{{- else}}
+	if !reflect.ValueOf(&{{$r}}.{{(.F "punishment_reason").Name}}).Elem().IsZero() {
+		reason = {{$r}}.{{(.F "punishment_reason").Name}}
+	}
+{{- end}}
+{{- end}}
+{{- if .Has "punished_by"}}
+{{- if (.F "punished_by").Interface}}
+	by, _ = interface{}({{$r}}.{{(.F "punished_by").Name}}).(credentials.Credential)
+{{- else}}
+	if {{$r}}.{{(.F "punished_by").Name}} != nil {
+		by = {{$r}}.{{(.F "punished_by").Name}}
+	}
+{{- end}}
+{{- end}}
+	return
+}
+
+func ({{$r}} *{{$t}}) Punish(forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	now := time.Now()
+	{{$r}}.{{(.F "punished_on").Name}} = &now
+	{{$r}}.{{(.F "punished_for").Name}} = nil
+	if forTime != nil {
+		duration := *forTime
+		{{$r}}.{{(.F "punished_for").Name}} = &duration
+	}
+{{- if .Has "punishment_reason"}}
+	{{$r}}.{{(.F "punishment_reason").Name}}, _ = reason.({{(.F "punishment_reason").Type}})
+{{- end}}
+{{- if .Has "punished_by"}}
+	{{$r}}.{{(.F "punished_by").Name}}, _ = by.({{(.F "punished_by").Type}})
+{{- end}}
+}
+
+func ({{$r}} *{{$t}}) Unpunish() {
+	{{$r}}.{{(.F "punished_on").Name}} = nil
+	{{$r}}.{{(.F "punished_for").Name}} = nil
+{{- if .Has "punishment_reason"}}
+	{{$r}}.{{(.F "punishment_reason").Name}} = *new({{(.F "punishment_reason").Type}})
+{{- end}}
+{{- if .Has "punished_by"}}
+	{{$r}}.{{(.F "punished_by").Name}} = nil
+{{- end}}
+}
+{{end}}
+{{- if .Has "recovery_token"}}
+func ({{$r}} *{{$t}}) SetRecoveryToken(token string, duration time.Duration) {
+	{{$r}}.{{(.F "recovery_token").Name}} = token
+	{{$r}}.{{(.F "recovery_expiration").Name}} = time.Now().Add(duration)
+}
+
+func ({{$r}} *{{$t}}) RecoveryToken() string {
+	if {{$r}}.{{(.F "recovery_token").Name}} != "" && {{$r}}.{{(.F "recovery_expiration").Name}}.Before(time.Now()) {
+		{{$r}}.{{(.F "recovery_token").Name}} = ""
+	}
+	return {{$r}}.{{(.F "recovery_token").Name}}
+}
+
+func ({{$r}} *{{$t}}) RecoveryTokenExpiration() time.Time {
+	return {{$r}}.{{(.F "recovery_expiration").Name}}
+}
+{{end}}
+{{- if .Has "identifier"}}
+func ({{$r}} *{{$t}}) Identification() interface{} {
+	return {{$r}}.{{(.F "identifier").Name}}
+}
+{{end}}
+{{- if .Has "index"}}
+func ({{$r}} *{{$t}}) Index() interface{} {
+	return {{$r}}.{{(.F "index").Name}}
+}
+{{end}}
+{{- if .Has "scopes"}}
+func ({{$r}} *{{$t}}) Scopes() map[string]scoped.Scope {
+	return {{$r}}.{{(.F "scopes").Name}}
+}
+{{end}}
+{{- if .Has "staff"}}
+func ({{$r}} *{{$t}}) Staff() bool {
+	return {{$r}}.{{(.F "staff").Name}}
+}
+{{end}}
+{{- if .Has "superuser"}}
+func ({{$r}} *{{$t}}) Superuser() bool {
+	return {{$r}}.{{(.F "superuser").Name}}
+}
+{{end}}
+{{- if .Has "version"}}
+func ({{$r}} *{{$t}}) Version() uint64 {
+	return {{$r}}.{{(.F "version").Name}}
+}
+
+func ({{$r}} *{{$t}}) SetVersion(version uint64) {
+	{{$r}}.{{(.F "version").Name}} = version
+}
+{{end}}`))
+
+func main() {
+	typeName := flag.String("type", "", "the name of the struct type to generate the methods for (mandatory)")
+	hasher := flag.String("hasher", "", "the expression to return in the Hasher() method (if empty, no such method is generated)")
+	outputName := flag.String("output", "", "the output file name (default: <type>_identity.go)")
+	flag.Parse()
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *outputName == "" {
+		*outputName = strings.ToLower(*typeName) + "_identity.go"
+	}
+
+	parsed, err := parse(".", *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	parsed.Hasher = *hasher
+
+	buffer := &bytes.Buffer{}
+	if err := output.Execute(buffer, parsed); err != nil {
+		log.Fatal(err)
+	}
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outputName, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,192 @@
+// This command imports a CSV file of users (see the package
+// credentials/csvimport for the columns) through a broker, e.g.:
+//
+//	identity-import -input users.csv -output users.jsonl
+//
+// The rows go through csvimport.Importer, like in any other
+// import: this command's broker creates each credential by
+// writing it as a portability document (one JSON document per
+// line), to be imported later via portability.Importer into the
+// final broker. Applications having their own broker can run the
+// same import directly into it, with csvimport.Importer.
+//
+// Known legacy hashes are prefixed with their format's name,
+// so they can be validated by a MultipleHashingEngine. Since
+// there is no hasher here, plain text passwords are reported
+// as errors. The errors are reported per row, and the command
+// exits with status 1 if any row failed.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/csvimport"
+	"github.com/universe-10th/identity/credentials/portability"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/hashing"
+	"io"
+	"log"
+	"os"
+)
+
+// A scope known only by its key.
+type scope string
+
+func (scope scope) Key() string {
+	return string(scope)
+}
+
+func (scope scope) Name() string {
+	return string(scope)
+}
+
+func (scope scope) Description() string {
+	return ""
+}
+
+// A credential created from a row. It has no hasher, so plain
+// text passwords cannot be imported into it.
+type user struct {
+	identification string
+	hashedPassword string
+	active         bool
+	scopes         map[string]scoped.Scope
+}
+
+func (user *user) Identification() interface{} {
+	return user.identification
+}
+
+func (user *user) HashedPassword() string {
+	return user.hashedPassword
+}
+
+func (user *user) SetHashedPassword(password string) {
+	user.hashedPassword = password
+}
+
+func (user *user) Hasher() hashing.HashingEngine {
+	return nil
+}
+
+func (user *user) Active() bool {
+	return user.active
+}
+
+func (user *user) SetActive(active bool) {
+	user.active = active
+}
+
+func (user *user) Scopes() map[string]scoped.Scope {
+	return user.scopes
+}
+
+// Creates a credential with the data of a row.
+func factory(row *csvimport.Row) (credentials.Credential, error) {
+	result := &user{identification: row.Identifier, scopes: map[string]scoped.Scope{}}
+	for _, key := range row.Scopes {
+		result.scopes[key] = scope(key)
+	}
+	return result, nil
+}
+
+// Returned when saving through the document broker, which can
+// only create credentials.
+var errSaveNotSupported = errors.New("the documents can only be created")
+
+// A broker creating credentials by writing them as portability
+// documents. It remembers the written identifications, so the
+// duplicated rows fail to be created.
+type documentBroker struct {
+	encoder *json.Encoder
+	written map[string]credentials.Credential
+}
+
+func (broker *documentBroker) Allows(template credentials.Credential) bool {
+	_, ok := template.(*user)
+	return ok
+}
+
+func (broker *documentBroker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	if identification, ok := identifier.(string); ok {
+		if credential, ok := broker.written[identification]; ok {
+			return credential, nil
+		}
+	}
+	return nil, nil
+}
+
+func (broker *documentBroker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	return nil, nil
+}
+
+func (broker *documentBroker) Save(credential credentials.Credential) error {
+	return errSaveNotSupported
+}
+
+func (broker *documentBroker) Create(credential credentials.Credential) error {
+	created := credential.(*user)
+	if _, ok := broker.written[created.identification]; ok {
+		return fmt.Errorf("the identifier %s is duplicated", created.identification)
+	}
+	if err := broker.encoder.Encode(portability.Export(created, true)); err != nil {
+		return err
+	}
+	broker.written[created.identification] = created
+	return nil
+}
+
+// Imports all the rows, writing the documents and reporting
+// the errors. Returns how many rows failed.
+func convert(input io.Reader, output io.Writer, failures io.Writer) (int, error) {
+	importer := &csvimport.Importer{
+		Broker:          &documentBroker{json.NewEncoder(output), map[string]credentials.Credential{}},
+		Factory:         factory,
+		RejectPlaintext: true,
+	}
+	report, err := importer.Import(input)
+	if err != nil {
+		return 0, err
+	}
+	for _, rowError := range report.Errors {
+		fmt.Fprintln(failures, rowError)
+	}
+	return len(report.Errors), nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("identity-import: ")
+	inputPath := flag.String("input", "", "the CSV file to read (default: standard input)")
+	outputPath := flag.String("output", "", "the file to write the documents to (default: standard output)")
+	flag.Parse()
+
+	input := io.Reader(os.Stdin)
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		input = file
+	}
+	output := io.Writer(os.Stdout)
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if failed, err := convert(input, output, os.Stderr); err != nil {
+		log.Fatal(err)
+	} else if failed > 0 {
+		log.Printf("%d row(s) failed", failed)
+		os.Exit(1)
+	}
+}
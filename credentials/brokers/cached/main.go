@@ -0,0 +1,428 @@
+package cached
+
+import (
+	"container/list"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Options to configure a caching broker. TTL is the time a
+// found credential is kept in the cache, and NegativeTTL the
+// time a not-found ((nil, nil)) result is kept (it should be
+// brief, and a value <= 0 disables negative caching). When
+// MaxEntries > 0, the least recently used entries are evicted
+// beyond that size. OnInvalidate, if not nil, is invoked on
+// each invalidation caused by a local Save, so the other
+// instances of a deployment can be told to invalidate.
+type Options struct {
+	TTL          time.Duration
+	NegativeTTL  time.Duration
+	MaxEntries   int
+	OnInvalidate func(invalidation Invalidation)
+}
+
+// An invalidation tells which entries of a given credential
+// type must be removed: the ones by identifier and/or index,
+// when they are not nil, or all the entries of that type if
+// All is true.
+type Invalidation struct {
+	Type       reflect.Type
+	Identifier interface{}
+	Index      interface{}
+	All        bool
+}
+
+const (
+	byIdentifier = iota
+	byIndex
+)
+
+type key struct {
+	kind     uint8
+	tmplType reflect.Type
+	value    interface{}
+}
+
+type entry struct {
+	key        key
+	credential credentials.Credential
+	expires    time.Time
+	// The index key of the credential, for the entries by
+	// identifier of indexed credentials.
+	index *key
+}
+
+// A broker decorator caching the lookups by identifier and by
+// index. Found credentials are cached under both keys (when
+// they implement the Identified and Indexed traits). Saving a
+// credential invalidates both of its keys, and also all the
+// identifiers it was cached by (e.g. the ones it had before
+// being renamed), or all the entries of its type if it does
+// not implement those traits. Errors are never cached, and
+// neither are identifiers and indices that are not of a
+// comparable type. A lookup racing with an invalidation does
+// not cache what it loaded, since it may be stale. Each lookup
+// returns a copy of the cached credential (see copyCredential),
+// so concurrent callers do not share it.
+type Broker struct {
+	credentials.Broker
+	options     Options
+	mutex       sync.Mutex
+	entries     map[key]*list.Element
+	order       *list.List
+	identifiers map[key]map[key]bool
+	// The generation is increased on each invalidation. While
+	// there are lookups in flight, the generation of the last
+	// invalidation of each key (and type, for the invalidations
+	// of all the entries of a type), and of the last purge, is
+	// kept, so the lookups that started before do not cache
+	// their results.
+	generation       uint64
+	inFlight         int
+	invalidated      map[key]uint64
+	invalidatedTypes map[reflect.Type]uint64
+	purged           uint64
+}
+
+// Creates a caching broker. Panics if the broker is nil.
+func NewBroker(broker credentials.Broker, options Options) *Broker {
+	if broker == nil {
+		panic(credentials.ErrNilBroker)
+	}
+
+	return &Broker{
+		Broker: broker, options: options, entries: make(map[key]*list.Element), order: list.New(),
+		identifiers: make(map[key]map[key]bool), invalidated: make(map[key]uint64),
+		invalidatedTypes: make(map[reflect.Type]uint64),
+	}
+}
+
+func cacheable(value interface{}) bool {
+	return value != nil && reflect.TypeOf(value).Comparable()
+}
+
+// Makes a copy of a credential, when it is a pointer to a
+// struct (otherwise, it is returned as is). The struct is
+// copied shallowly, and then the trait data held in maps
+// and slices is copied through the traits: the scopes, the
+// login history, the WebAuthn credentials, the recovery
+// codes and the TOTP secret. Any other map, slice or pointer
+// (e.g. custom fields, or the punisher) remains shared among
+// the copies, so it must not be changed in place.
+func copyCredential(credential credentials.Credential) credentials.Credential {
+	value := reflect.ValueOf(credential)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return credential
+	}
+	copied := reflect.New(value.Elem().Type())
+	copied.Elem().Set(value.Elem())
+	credential = copied.Interface().(credentials.Credential)
+
+	if editable, ok := credential.(scoped.Editable); ok && editable.Scopes() != nil {
+		scopes := make(map[string]scoped.Scope, len(editable.Scopes()))
+		for key, scope := range editable.Scopes() {
+			scopes[key] = scope
+		}
+		editable.SetScopes(scopes)
+	}
+	if tracked, ok := credential.(history.Tracked); ok && tracked.LoginHistory() != nil {
+		tracked.SetLoginHistory(tracked.LastLogin(), tracked.LastFailedLogin(),
+			append([]history.Entry(nil), tracked.LoginHistory()...))
+	}
+	if capable, ok := credential.(webauthn.WebAuthnCapable); ok && capable.WebAuthnCredentials() != nil {
+		keys := make([]webauthn.PublicKeyCredential, len(capable.WebAuthnCredentials()))
+		for index, key := range capable.WebAuthnCredentials() {
+			keys[index] = webauthn.PublicKeyCredential{
+				ID: append([]byte(nil), key.ID...), PublicKey: append([]byte(nil), key.PublicKey...),
+				SignCount: key.SignCount,
+			}
+		}
+		capable.SetWebAuthnCredentials(keys)
+	}
+	if capable, ok := credential.(otp.RecoveryCodesCapable); ok && capable.RecoveryCodes() != nil {
+		capable.SetRecoveryCodes(append([]string(nil), capable.RecoveryCodes()...))
+	}
+	if capable, ok := credential.(otp.TOTPCapable); ok {
+		if secret, enrolled := capable.TOTPSecret(); secret != nil {
+			capable.SetTOTPSecret(append([]byte(nil), secret...), enrolled)
+		}
+	}
+	return credential
+}
+
+// Removes an entry, and its reference from the index key
+// of its credential. The mutex must be held.
+func (broker *Broker) remove(element *list.Element) {
+	removed := element.Value.(*entry)
+	broker.order.Remove(element)
+	delete(broker.entries, removed.key)
+	if removed.index != nil {
+		if identifiers := broker.identifiers[*removed.index]; identifiers != nil {
+			delete(identifiers, removed.key)
+			if len(identifiers) == 0 {
+				delete(broker.identifiers, *removed.index)
+			}
+		}
+	}
+}
+
+func (broker *Broker) get(k key) (credentials.Credential, bool) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if element, ok := broker.entries[k]; !ok {
+		return nil, false
+	} else if cached := element.Value.(*entry); time.Now().After(cached.expires) {
+		broker.remove(element)
+		return nil, false
+	} else {
+		broker.order.MoveToFront(element)
+		return copyCredential(cached.credential), true
+	}
+}
+
+// Tells whether a key was invalidated after the given
+// generation. The mutex must be held.
+func (broker *Broker) stale(k key, generation uint64) bool {
+	return broker.purged > generation || broker.invalidatedTypes[k.tmplType] > generation ||
+		cacheable(k.value) && broker.invalidated[k] > generation
+}
+
+// Caches a credential under a key. The mutex must be held.
+func (broker *Broker) put(k key, credential credentials.Credential, index *key, ttl time.Duration) {
+	if ttl <= 0 || !cacheable(k.value) {
+		return
+	}
+
+	if element, ok := broker.entries[k]; ok {
+		broker.remove(element)
+	}
+	if index != nil && k.kind == byIdentifier {
+		if broker.identifiers[*index] == nil {
+			broker.identifiers[*index] = make(map[key]bool)
+		}
+		broker.identifiers[*index][k] = true
+	} else {
+		index = nil
+	}
+	broker.entries[k] = broker.order.PushFront(&entry{k, credential, time.Now().Add(ttl), index})
+
+	for broker.options.MaxEntries > 0 && broker.order.Len() > broker.options.MaxEntries {
+		broker.remove(broker.order.Back())
+	}
+}
+
+// Starts a lookup in flight, returning the current generation.
+func (broker *Broker) begin() uint64 {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.inFlight++
+	return broker.generation
+}
+
+// Ends a lookup in flight. The mutex must be held.
+func (broker *Broker) end() {
+	broker.inFlight--
+	if broker.inFlight == 0 {
+		broker.invalidated = make(map[key]uint64)
+		broker.invalidatedTypes = make(map[reflect.Type]uint64)
+		broker.purged = 0
+	}
+}
+
+// Caches the result of a lookup started on the given generation,
+// and ends it.
+func (broker *Broker) store(kind uint8, value interface{}, template, credential credentials.Credential, generation uint64) {
+	tmplType := reflect.TypeOf(template)
+	// The cache keeps its own copy, so the changes done by the
+	// caller to the returned credential do not affect it.
+	credential = copyCredential(credential)
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	defer broker.end()
+
+	k := key{kind, tmplType, value}
+	if credential == nil {
+		if !broker.stale(k, generation) {
+			broker.put(k, nil, nil, broker.options.NegativeTTL)
+		}
+		return
+	}
+
+	// Nothing is cached if any of the keys of the credential
+	// was invalidated meanwhile (e.g. its index, when it was
+	// renamed and saved after being loaded by its identifier).
+	keys := []key{k}
+	var index *key
+	if indexedCred, ok := credential.(indexed.Indexed); ok && cacheable(indexedCred.Index()) {
+		index = &key{byIndex, tmplType, indexedCred.Index()}
+		keys = append(keys, *index)
+	}
+	identifiedCred, isIdentified := credential.(identified.Identified)
+	if isIdentified {
+		keys = append(keys, key{byIdentifier, tmplType, identifiedCred.Identification()})
+	}
+	for _, k := range keys {
+		if broker.stale(k, generation) {
+			return
+		}
+	}
+
+	broker.put(k, credential, index, broker.options.TTL)
+	if isIdentified && kind != byIdentifier {
+		broker.put(key{byIdentifier, tmplType, identifiedCred.Identification()}, credential, index, broker.options.TTL)
+	}
+	if index != nil && kind != byIndex {
+		broker.put(*index, credential, nil, broker.options.TTL)
+	}
+}
+
+func (broker *Broker) lookup(kind uint8, value interface{}, template credentials.Credential,
+	load func(interface{}, credentials.Credential) (credentials.Credential, error)) (credentials.Credential, error) {
+	k := key{kind, reflect.TypeOf(template), value}
+	if cacheable(value) {
+		if credential, ok := broker.get(k); ok {
+			return credential, nil
+		}
+	}
+
+	generation := broker.begin()
+	if credential, err := load(value, template); err != nil {
+		broker.mutex.Lock()
+		broker.end()
+		broker.mutex.Unlock()
+		return nil, err
+	} else {
+		broker.store(kind, value, template, credential, generation)
+		return credential, nil
+	}
+}
+
+// Retrieves a credential by its identifier, from the cache if
+// present and not expired, or from the underlying broker.
+func (broker *Broker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	return broker.lookup(byIdentifier, identifier, template, broker.Broker.ByIdentifier)
+}
+
+// Retrieves a credential by its index, from the cache if
+// present and not expired, or from the underlying broker.
+func (broker *Broker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	return broker.lookup(byIndex, index, template, broker.Broker.ByIndex)
+}
+
+// Saves the credential through the underlying broker, and
+// invalidates its entries (regardless of the save result).
+func (broker *Broker) Save(credential credentials.Credential) error {
+	err := broker.Broker.Save(credential)
+	broker.invalidateCredential(credential)
+	return err
+}
+
+// Creates the credential through the underlying broker, and
+// invalidates its entries (e.g. cached not-found results).
+func (broker *Broker) Create(credential credentials.Credential) error {
+	err := credentials.Create(broker.Broker, credential)
+	broker.invalidateCredential(credential)
+	return err
+}
+
+// Deletes the credential through the underlying broker, and
+// invalidates its entries.
+func (broker *Broker) Delete(credential credentials.Credential) error {
+	err := credentials.Delete(broker.Broker, credential)
+	broker.invalidateCredential(credential)
+	return err
+}
+
+func (broker *Broker) invalidateCredential(credential credentials.Credential) {
+	if credential != nil {
+		invalidation := Invalidation{Type: reflect.TypeOf(credential)}
+		identifiedCred, isIdentified := credential.(identified.Identified)
+		indexedCred, isIndexed := credential.(indexed.Indexed)
+		if isIdentified {
+			invalidation.Identifier = identifiedCred.Identification()
+		}
+		if isIndexed {
+			invalidation.Index = indexedCred.Index()
+		}
+		invalidation.All = !isIdentified && !isIndexed
+		broker.Invalidate(invalidation)
+		if broker.options.OnInvalidate != nil {
+			broker.options.OnInvalidate(invalidation)
+		}
+	}
+}
+
+// Removes the entries told by the invalidation. This method is
+// meant to be invoked on invalidations received from other
+// instances, and does not trigger the OnInvalidate hook.
+func (broker *Broker) Invalidate(invalidation Invalidation) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.generation++
+	if invalidation.All {
+		if broker.inFlight > 0 {
+			broker.invalidatedTypes[invalidation.Type] = broker.generation
+		}
+		for k, element := range broker.entries {
+			if k.tmplType == invalidation.Type {
+				broker.remove(element)
+			}
+		}
+		return
+	}
+
+	keys := []key{
+		{byIdentifier, invalidation.Type, invalidation.Identifier},
+		{byIndex, invalidation.Type, invalidation.Index},
+	}
+	if cacheable(invalidation.Index) {
+		// Also the identifiers the credential was cached by,
+		// which may differ from its current one.
+		for k := range broker.identifiers[keys[1]] {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		if !cacheable(k.value) {
+			continue
+		}
+		if broker.inFlight > 0 {
+			broker.invalidated[k] = broker.generation
+		}
+		if element, ok := broker.entries[k]; ok {
+			broker.remove(element)
+		}
+	}
+}
+
+// Removes all the entries from the cache.
+func (broker *Broker) Purge() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.generation++
+	if broker.inFlight > 0 {
+		broker.purged = broker.generation
+	}
+	broker.entries = make(map[key]*list.Element)
+	broker.identifiers = make(map[key]map[key]bool)
+	broker.order.Init()
+}
+
+// Bypasses the listing to the underlying broker. Listings are
+// not cached.
+func (broker *Broker) List(template credentials.Credential, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	return credentials.List(broker.Broker, template, filter, cursor, limit)
+}
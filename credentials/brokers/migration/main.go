@@ -0,0 +1,309 @@
+package migration
+
+import (
+	"container/list"
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// The default time a credential loaded from the legacy broker
+// is kept as pending.
+const DefaultPendingTTL = 15 * time.Minute
+
+// The default maximum number of pending credentials.
+const DefaultMaxPending = 10000
+
+// Mappers convert a credential loaded from the legacy broker
+// into a new credential, suitable for the primary broker.
+// The new credential must implement the Identified trait, with
+// an identification of a comparable type.
+type Mapper func(legacy credentials.Credential) (credentials.Credential, error)
+
+// Panicked when creating a migration broker with a nil mapper.
+var ErrNilMapper = errors.New("the given mapper is nil")
+
+// Panicked when creating a migration broker whose primary
+// broker is not a credentials.Creator.
+var ErrPrimaryNotCreator = errors.New("the primary broker cannot create credentials")
+
+// Panicked when creating a migration broker that must delete
+// the migrated credentials, but whose legacy broker is not a
+// credentials.Deleter.
+var ErrLegacyNotDeleter = errors.New("the legacy broker cannot delete credentials")
+
+// Returned when the mapper returns a nil credential, or one
+// not implementing the Identified trait (or whose identification
+// is not of a comparable type).
+var ErrBadMappedCredential = errors.New("the mapped credential is nil or not identified")
+
+type pendingEntry struct {
+	key     interface{}
+	mapped  credentials.Credential
+	legacy  credentials.Credential
+	expires time.Time
+}
+
+// A broker that consults a primary broker and falls back to a
+// legacy one when a credential is not found. Legacy credentials
+// are converted via a mapper and kept as "pending", until they
+// are migrated: they are created in the primary broker (and,
+// optionally, deleted from the legacy one) after a successful
+// login (see MigrationStep) or when they are saved. This allows
+// moving users between stores with no downtime and no bulk
+// password reset. Other calls are bypassed to the primary broker.
+// Pending credentials are kept for a limited time, and up to a
+// limited number of them (the oldest ones are forgotten first):
+// forgotten credentials must be loaded again to be migrated.
+type Broker struct {
+	primary        credentials.Broker
+	legacy         credentials.Broker
+	legacyTemplate credentials.Credential
+	mapper         Mapper
+	deleteLegacy   bool
+	pendingTTL     time.Duration
+	maxPending     int
+	onDeleteError  func(legacy credentials.Credential, err error)
+	mutex          sync.Mutex
+	pending        map[interface{}]*list.Element
+	order          *list.List
+}
+
+// Creates a migration broker. The legacy template is the one to
+// use on the legacy broker lookups. Panics if any argument is nil,
+// the legacy broker does not allow the legacy template, the primary
+// broker cannot create credentials, or the legacy broker cannot
+// delete credentials (only when deleteLegacy is true).
+func NewBroker(primary, legacy credentials.Broker, legacyTemplate credentials.Credential, mapper Mapper, deleteLegacy bool) *Broker {
+	if primary == nil || legacy == nil {
+		panic(credentials.ErrNilBroker)
+	} else if legacyTemplate == nil || !legacy.Allows(legacyTemplate) {
+		panic(credentials.ErrBadTemplate)
+	} else if mapper == nil {
+		panic(ErrNilMapper)
+	} else if _, ok := primary.(credentials.Creator); !ok {
+		panic(ErrPrimaryNotCreator)
+	} else if _, ok := legacy.(credentials.Deleter); deleteLegacy && !ok {
+		panic(ErrLegacyNotDeleter)
+	}
+
+	return &Broker{
+		primary: primary, legacy: legacy, legacyTemplate: legacyTemplate, mapper: mapper,
+		deleteLegacy: deleteLegacy, pendingTTL: DefaultPendingTTL, maxPending: DefaultMaxPending,
+		pending: make(map[interface{}]*list.Element), order: list.New(),
+	}
+}
+
+// Sets how long the credentials loaded from the legacy broker are
+// kept as pending, and how many of them at most (by default:
+// DefaultPendingTTL and DefaultMaxPending). Values <= 0 keep the
+// current setting.
+func (broker *Broker) SetPendingLimits(ttl time.Duration, max int) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if ttl > 0 {
+		broker.pendingTTL = ttl
+	}
+	if max > 0 {
+		broker.maxPending = max
+	}
+}
+
+// Sets the function invoked when a migrated credential cannot be
+// deleted from the legacy broker. Such failures do not fail the
+// migration (the credential was already created in the primary
+// broker), and are logged by default.
+func (broker *Broker) SetOnDeleteError(handler func(legacy credentials.Credential, err error)) {
+	broker.onDeleteError = handler
+}
+
+// Tells whether the primary broker allows the template.
+func (broker *Broker) Allows(template credentials.Credential) bool {
+	return broker.primary.Allows(template)
+}
+
+func (broker *Broker) fallback(credential credentials.Credential, err error,
+	loadLegacy func() (credentials.Credential, error)) (credentials.Credential, error) {
+	if err != nil || credential != nil {
+		return credential, err
+	}
+
+	legacyCred, err := loadLegacy()
+	if err != nil || legacyCred == nil {
+		return nil, err
+	}
+	mapped, err := broker.mapper(legacyCred)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pendingKey(mapped)
+	if !ok {
+		return nil, ErrBadMappedCredential
+	}
+
+	broker.putPending(&pendingEntry{key: key, mapped: mapped, legacy: legacyCred})
+	return mapped, nil
+}
+
+// Removes a pending entry. The mutex must be held.
+func (broker *Broker) removePending(element *list.Element) {
+	broker.order.Remove(element)
+	delete(broker.pending, element.Value.(*pendingEntry).key)
+}
+
+// Adds (or replaces) a pending entry, forgetting the expired
+// ones and the oldest ones beyond the limit.
+func (broker *Broker) putPending(entry *pendingEntry) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	now := time.Now()
+	entry.expires = now.Add(broker.pendingTTL)
+	if element, ok := broker.pending[entry.key]; ok {
+		broker.removePending(element)
+	}
+	broker.pending[entry.key] = broker.order.PushFront(entry)
+	for oldest := broker.order.Back(); oldest != nil; oldest = broker.order.Back() {
+		if broker.order.Len() <= broker.maxPending && now.Before(oldest.Value.(*pendingEntry).expires) {
+			break
+		}
+		broker.removePending(oldest)
+	}
+}
+
+// Gets the pending entry of a credential (the very same instance),
+// if any and not expired. The mutex must be held.
+func (broker *Broker) getPending(credential credentials.Credential) (*list.Element, bool) {
+	key, ok := pendingKey(credential)
+	if !ok {
+		return nil, false
+	} else if element, ok := broker.pending[key]; !ok {
+		return nil, false
+	} else if entry := element.Value.(*pendingEntry); time.Now().After(entry.expires) {
+		broker.removePending(element)
+		return nil, false
+	} else {
+		return element, same(entry.mapped, credential)
+	}
+}
+
+// Retrieves a credential by its identifier from the primary
+// broker or, if not found there, from the legacy one.
+func (broker *Broker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	credential, err := broker.primary.ByIdentifier(identifier, template)
+	return broker.fallback(credential, err, func() (credentials.Credential, error) {
+		return broker.legacy.ByIdentifier(identifier, broker.legacyTemplate)
+	})
+}
+
+// Retrieves a credential by its index from the primary broker
+// or, if not found there, from the legacy one.
+func (broker *Broker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	credential, err := broker.primary.ByIndex(index, template)
+	return broker.fallback(credential, err, func() (credentials.Credential, error) {
+		return broker.legacy.ByIndex(index, broker.legacyTemplate)
+	})
+}
+
+func same(mapped, credential credentials.Credential) bool {
+	mappedType := reflect.TypeOf(mapped)
+	return mappedType == reflect.TypeOf(credential) && mappedType.Comparable() && mapped == credential
+}
+
+func pendingKey(credential credentials.Credential) (interface{}, bool) {
+	if identifiedCred, ok := credential.(identified.Identified); !ok {
+		return nil, false
+	} else if key := identifiedCred.Identification(); key == nil || !reflect.TypeOf(key).Comparable() {
+		return nil, false
+	} else {
+		return key, true
+	}
+}
+
+// Tells whether the credential was loaded from the legacy broker
+// and not migrated yet (it must be the very same instance).
+func (broker *Broker) isPending(credential credentials.Credential) bool {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	_, ok := broker.getPending(credential)
+	return ok
+}
+
+// Takes the pending entry for the credential, if any.
+func (broker *Broker) takePending(credential credentials.Credential) (*pendingEntry, bool) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if element, ok := broker.getPending(credential); !ok {
+		return nil, false
+	} else {
+		broker.removePending(element)
+		return element.Value.(*pendingEntry), true
+	}
+}
+
+// Migrates the credential if it was loaded from the legacy
+// broker and was not migrated yet: creates it in the primary
+// broker and, optionally, deletes the legacy one (deletion
+// errors are not returned, but given to the handler set by
+// SetOnDeleteError). Does nothing for other credentials.
+func (broker *Broker) Migrate(credential credentials.Credential) error {
+	if entry, ok := broker.takePending(credential); !ok {
+		return nil
+	} else if err := credentials.Create(broker.primary, entry.mapped); err != nil {
+		// It remains pending, so it can be migrated later.
+		broker.putPending(entry)
+		return err
+	} else if broker.deleteLegacy {
+		if err := credentials.Delete(broker.legacy, entry.legacy); err != nil {
+			if broker.onDeleteError != nil {
+				broker.onDeleteError(entry.legacy, err)
+			} else {
+				log.Printf("migration: the migrated credential %v could not be deleted from the legacy broker: %s",
+					entry.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Saves a credential into the primary broker. Credentials not
+// migrated yet are migrated instead.
+func (broker *Broker) Save(credential credentials.Credential) error {
+	if broker.isPending(credential) {
+		return broker.Migrate(credential)
+	} else {
+		return broker.primary.Save(credential)
+	}
+}
+
+// Bypasses the listing to the primary broker. Legacy credentials
+// are not listed.
+func (broker *Broker) List(template credentials.Credential, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	return credentials.List(broker.primary, template, filter, cursor, limit)
+}
+
+// Bypasses the creation to the primary broker.
+func (broker *Broker) Create(credential credentials.Credential) error {
+	return credentials.Create(broker.primary, credential)
+}
+
+// Bypasses the deletion to the primary broker.
+func (broker *Broker) Delete(credential credentials.Credential) error {
+	return credentials.Delete(broker.primary, credential)
+}
+
+// A login pipeline step that migrates the credential, if it
+// was loaded from the legacy broker. It must be the last step
+// in the pipeline, so only successful logins are migrated. The
+// migration errors, if any, are returned as login errors.
+type MigrationStep struct {
+	Broker *Broker
+}
+
+// Attempts the login step of migrating the credential.
+func (step *MigrationStep) Login(credential credentials.Credential, password string) error {
+	return step.Broker.Migrate(credential)
+}
@@ -0,0 +1,145 @@
+package multi
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"regexp"
+)
+
+// A lookup is a single strategy to retrieve a credential by
+// one of its handles (e.g. username, email or phone). It will
+// tell whether a given identifier looks like the handle it
+// knows how to look up, and perform the actual lookup. Like
+// brokers, lookups must return (nil, nil) if no credential
+// can be found by the given identifier.
+type Lookup interface {
+	Accepts(identifier interface{}) bool
+	ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error)
+}
+
+// A convenience implementation of a lookup in terms of two
+// functions. A nil matcher accepts every identifier.
+type LookupFunc struct {
+	Matcher func(identifier interface{}) bool
+	Finder  func(identifier interface{}, template credentials.Credential) (credentials.Credential, error)
+}
+
+// Tells whether the identifier is accepted by the matcher.
+func (lookup *LookupFunc) Accepts(identifier interface{}) bool {
+	return lookup.Matcher == nil || lookup.Matcher(identifier)
+}
+
+// Bypasses the lookup to the finder.
+func (lookup *LookupFunc) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	return lookup.Finder(identifier, template)
+}
+
+// Creates a lookup which bypasses the ByIdentifier call to
+// an existing broker, for identifiers accepted by the given
+// matcher (which may be nil to accept every identifier).
+func Direct(broker credentials.Broker, matcher func(identifier interface{}) bool) Lookup {
+	if broker == nil {
+		panic(credentials.ErrNilBroker)
+	}
+	return &LookupFunc{matcher, broker.ByIdentifier}
+}
+
+// Pattern matching identifiers that look like an email address.
+var EmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+
+// Pattern matching identifiers that look like a phone number.
+var PhonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{5,}$`)
+
+// Pattern matching identifiers that look like a plain username
+// (i.e. not an email address, and not starting with a "+").
+var UsernamePattern = regexp.MustCompile(`^[^@\s+][^@\s]*$`)
+
+// Creates a matcher that accepts string identifiers matching
+// the given pattern.
+func Matching(pattern *regexp.Regexp) func(identifier interface{}) bool {
+	return func(identifier interface{}) bool {
+		if str, ok := identifier.(string); ok {
+			return pattern.MatchString(str)
+		} else {
+			return false
+		}
+	}
+}
+
+// Panicked when creating a multi-lookup broker with no lookups.
+var ErrNoLookups = errors.New("no lookups were specified")
+
+// Panicked when creating a multi-lookup broker with a nil lookup.
+var ErrNilLookup = errors.New("a nil lookup was specified")
+
+// A broker decorator which resolves identifiers by trying
+// several lookups (e.g. username, then email, then phone).
+// Every lookup accepting the identifier is ALWAYS tried, even
+// after one of them found a credential, so the time spent does
+// not reveal which handle exists (it only depends on the shape
+// of the identifier, which is already known to the caller).
+// The first credential found, in lookup order, is returned.
+// Other calls are bypassed to the underlying broker.
+type Broker struct {
+	credentials.Broker
+	lookups []Lookup
+}
+
+// Creates a multi-lookup broker. Panics if the broker is nil,
+// no lookups are given, or any of them is nil.
+func NewBroker(broker credentials.Broker, lookups ...Lookup) *Broker {
+	if broker == nil {
+		panic(credentials.ErrNilBroker)
+	} else if len(lookups) == 0 {
+		panic(ErrNoLookups)
+	}
+
+	for _, lookup := range lookups {
+		if lookup == nil {
+			panic(ErrNilLookup)
+		}
+	}
+
+	return &Broker{broker, lookups}
+}
+
+// Tries every accepting lookup, in order, and returns the first
+// found credential. If none was found, the first error (if any)
+// is returned. Otherwise, (nil, nil) is returned.
+func (broker *Broker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	var result credentials.Credential
+	var firstErr error
+	for _, lookup := range broker.lookups {
+		if !lookup.Accepts(identifier) {
+			continue
+		}
+		if credential, err := lookup.ByIdentifier(identifier, template); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else if credential != nil && result == nil {
+			result = credential
+		}
+	}
+
+	if result != nil {
+		return result, nil
+	} else {
+		return nil, firstErr
+	}
+}
+
+// Bypasses the listing to the underlying broker.
+func (broker *Broker) List(template credentials.Credential, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	return credentials.List(broker.Broker, template, filter, cursor, limit)
+}
+
+// Bypasses the creation to the underlying broker.
+func (broker *Broker) Create(credential credentials.Credential) error {
+	return credentials.Create(broker.Broker, credential)
+}
+
+// Bypasses the deletion to the underlying broker.
+func (broker *Broker) Delete(credential credentials.Credential) error {
+	return credentials.Delete(broker.Broker, credential)
+}
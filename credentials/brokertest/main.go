@@ -0,0 +1,351 @@
+// Package brokertest provides a conformance test suite for
+// credentials.Broker implementations. Broker authors may run
+// it in their own tests, against a disposable store.
+package brokertest
+
+import (
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/staff"
+	"github.com/universe-10th/identity/credentials/traits/superuser"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tolerance to use when comparing stored times, since stores
+// may truncate them.
+const tolerance = time.Second
+
+// Configuration of the conformance tests. Template must be
+// allowed by the broker, while Foreign (if not nil) must not.
+// Identifier must be the identifier of an existing credential,
+// while Unknown and UnknownIndex (if not nil) must not be the
+// identifier or index, respectively, of any credential. The
+// existing credential will be modified and saved, and restored
+// afterwards. Concurrency is the number of concurrent saves to
+// attempt (8 if <= 0). The broker must return a new instance on
+// each lookup (like a database does), since the credentials are
+// modified concurrently.
+type Config struct {
+	Broker       credentials.Broker
+	Template     credentials.Credential
+	Foreign      credentials.Credential
+	Identifier   interface{}
+	Unknown      interface{}
+	UnknownIndex interface{}
+	Concurrency  int
+}
+
+// Runs all the conformance tests against the configured broker.
+func Run(t *testing.T, config Config) {
+	t.Run("Allows", func(t *testing.T) { Allows(t, config) })
+	t.Run("Unknown", func(t *testing.T) { Unknown(t, config) })
+	t.Run("Keys", func(t *testing.T) { Keys(t, config) })
+	t.Run("Fresh", func(t *testing.T) { Fresh(t, config) })
+	t.Run("RoundTrip", func(t *testing.T) { RoundTrip(t, config) })
+	t.Run("ConcurrentSaves", func(t *testing.T) { ConcurrentSaves(t, config) })
+}
+
+func load(t *testing.T, config Config) credentials.Credential {
+	credential, err := config.Broker.ByIdentifier(config.Identifier, config.Template)
+	if err != nil {
+		t.Fatalf("ByIdentifier(%v) must not fail. Error: %s\n", config.Identifier, err)
+	} else if credential == nil {
+		t.Fatalf("ByIdentifier(%v) must find the existing credential\n", config.Identifier)
+	}
+	return credential
+}
+
+// Checks that the template is allowed, and the foreign one is not.
+func Allows(t *testing.T, config Config) {
+	if !config.Broker.Allows(config.Template) {
+		t.Error("Allows must accept the template")
+	}
+	if config.Foreign != nil && config.Broker.Allows(config.Foreign) {
+		t.Error("Allows must reject the foreign template")
+	}
+}
+
+// Checks that unknown identifiers and indices return (nil, nil).
+func Unknown(t *testing.T, config Config) {
+	if config.Unknown != nil {
+		if credential, err := config.Broker.ByIdentifier(config.Unknown, config.Template); credential != nil || err != nil {
+			t.Errorf("ByIdentifier must return (nil, nil) for unknown identifiers. Returned: %v, %v\n", credential, err)
+		}
+	}
+	if config.UnknownIndex != nil {
+		if credential, err := config.Broker.ByIndex(config.UnknownIndex, config.Template); credential != nil || err != nil {
+			t.Errorf("ByIndex must return (nil, nil) for unknown indices. Returned: %v, %v\n", credential, err)
+		}
+	}
+}
+
+// Checks that the identifier and the index agree with the ones
+// told by the Identified and Indexed traits.
+func Keys(t *testing.T, config Config) {
+	credential := load(t, config)
+	identifiedCred, isIdentified := credential.(identified.Identified)
+	if isIdentified && identifiedCred.Identification() != config.Identifier {
+		t.Errorf("Identification must return the identifier used to load the credential. Returned: %v\n",
+			identifiedCred.Identification())
+	}
+
+	if indexedCred, ok := credential.(indexed.Indexed); ok {
+		if byIndex, err := config.Broker.ByIndex(indexedCred.Index(), config.Template); err != nil || byIndex == nil {
+			t.Errorf("ByIndex must find the credential by its Index. Returned: %v, %v\n", byIndex, err)
+		} else if byIndex.(indexed.Indexed).Index() != indexedCred.Index() {
+			t.Errorf("ByIndex must return a credential with the same Index. Returned: %v\n", byIndex.(indexed.Indexed).Index())
+		} else if isIdentified && byIndex.(identified.Identified).Identification() != config.Identifier {
+			t.Errorf("ByIndex must return a credential with the same Identification. Returned: %v\n",
+				byIndex.(identified.Identified).Identification())
+		}
+	}
+}
+
+// Checks that each lookup returns a new instance, when the
+// credentials are pointers.
+func Fresh(t *testing.T, config Config) {
+	first, second := load(t, config), load(t, config)
+	if reflect.ValueOf(first).Kind() == reflect.Ptr && first == second {
+		t.Error("ByIdentifier must return a new instance on each lookup")
+	}
+}
+
+// The values of the traits, in comparable form.
+type state struct {
+	hashedPassword string
+	active         bool
+	punishedOn     *time.Time
+	punishedFor    *time.Duration
+	reason         string
+	punisher       string
+	token          string
+	expiration     time.Time
+	version        uint64
+	scopes         string
+	staff          bool
+	superuser      bool
+}
+
+// Describes a credential by its type and index (or identifier),
+// so punishers can be compared across lookups.
+func describe(credential credentials.Credential) string {
+	if credential == nil {
+		return ""
+	} else if indexedCred, ok := credential.(indexed.Indexed); ok {
+		return fmt.Sprintf("%T/%v", credential, indexedCred.Index())
+	} else if identifiedCred, ok := credential.(identified.Identified); ok {
+		return fmt.Sprintf("%T:%v", credential, identifiedCred.Identification())
+	}
+	return fmt.Sprintf("%T", credential)
+}
+
+func capture(credential credentials.Credential) state {
+	result := state{hashedPassword: credential.HashedPassword()}
+	if activable, ok := credential.(deniable.Activable); ok {
+		result.active = activable.Active()
+	}
+	if punishable, ok := credential.(deniable.Punishable); ok {
+		var reason interface{}
+		var by credentials.Credential
+		result.punishedOn, result.punishedFor, reason, by = punishable.PunishedFor()
+		if reason != nil {
+			result.reason = fmt.Sprint(reason)
+		}
+		result.punisher = describe(by)
+	}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok {
+		result.token = recoverableCred.RecoveryToken()
+	}
+	if expiring, ok := credential.(recoverable.Expiring); ok && result.token != "" {
+		result.expiration = expiring.RecoveryTokenExpiration()
+	}
+	if versionedCred, ok := credential.(versioned.Versioned); ok {
+		result.version = versionedCred.Version()
+	}
+	if scopedCred, ok := credential.(scoped.Scoped); ok {
+		result.scopes = fmt.Sprint(sortedKeys(scopedCred.Scopes()))
+	}
+	if capable, ok := credential.(staff.StaffCapable); ok {
+		result.staff = capable.Staff()
+	}
+	if capable, ok := credential.(superuser.SuperuserCapable); ok {
+		result.superuser = capable.Superuser()
+	}
+	return result
+}
+
+func sortedKeys(scopes map[string]scoped.Scope) []string {
+	keys := make([]string, 0, len(scopes))
+	for key := range scopes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func closeTimes(a, b time.Time) bool {
+	delta := a.Sub(b)
+	return delta < tolerance && delta > -tolerance
+}
+
+func compare(t *testing.T, expected, actual state) {
+	if expected.hashedPassword != actual.hashedPassword {
+		t.Errorf("The hashed password must round-trip. Expected: %q, got: %q\n", expected.hashedPassword, actual.hashedPassword)
+	}
+	if expected.active != actual.active {
+		t.Errorf("The active flag must round-trip. Expected: %v, got: %v\n", expected.active, actual.active)
+	}
+	if (expected.punishedOn == nil) != (actual.punishedOn == nil) ||
+		expected.punishedOn != nil && !closeTimes(*expected.punishedOn, *actual.punishedOn) {
+		t.Errorf("The punishment time must round-trip. Expected: %v, got: %v\n", expected.punishedOn, actual.punishedOn)
+	}
+	if (expected.punishedFor == nil) != (actual.punishedFor == nil) ||
+		expected.punishedFor != nil && *expected.punishedFor != *actual.punishedFor {
+		t.Errorf("The punishment duration must round-trip. Expected: %v, got: %v\n", expected.punishedFor, actual.punishedFor)
+	}
+	if expected.reason != actual.reason {
+		t.Errorf("The punishment reason must round-trip. Expected: %q, got: %q\n", expected.reason, actual.reason)
+	}
+	if expected.punisher != actual.punisher {
+		t.Errorf("The punisher must round-trip. Expected: %q, got: %q\n", expected.punisher, actual.punisher)
+	}
+	if expected.token != actual.token {
+		t.Errorf("The recovery token must round-trip. Expected: %q, got: %q\n", expected.token, actual.token)
+	}
+	if !closeTimes(expected.expiration, actual.expiration) {
+		t.Errorf("The recovery token expiration must round-trip. Expected: %v, got: %v\n", expected.expiration, actual.expiration)
+	}
+	if expected.version != actual.version {
+		t.Errorf("The version must round-trip. Expected: %d, got: %d\n", expected.version, actual.version)
+	}
+	if expected.scopes != actual.scopes {
+		t.Errorf("The scopes must round-trip. Expected: %s, got: %s\n", expected.scopes, actual.scopes)
+	}
+	if expected.staff != actual.staff {
+		t.Errorf("The staff flag must round-trip. Expected: %v, got: %v\n", expected.staff, actual.staff)
+	}
+	if expected.superuser != actual.superuser {
+		t.Errorf("The superuser flag must round-trip. Expected: %v, got: %v\n", expected.superuser, actual.superuser)
+	}
+}
+
+// Changes every settable trait of the credential, saves it, and
+// checks that the reloaded credential has the same values. The
+// punisher is the credential itself, and the editable scopes lose
+// one of them (so the broker only has to store known scopes). The
+// staff and superuser traits cannot be set, so they are only
+// checked to be kept. The credential is restored afterwards.
+func RoundTrip(t *testing.T, config Config) {
+	credential := load(t, config)
+	original := capture(credential)
+	var originalScopes map[string]scoped.Scope
+
+	credential.SetHashedPassword(original.hashedPassword + "-roundtrip")
+	if activable, ok := credential.(deniable.Activable); ok {
+		activable.SetActive(!original.active)
+	}
+	if punishable, ok := credential.(deniable.Punishable); ok {
+		duration := 90 * time.Minute
+		punishable.Punish(&duration, "roundtrip", credential)
+	}
+	if editable, ok := credential.(scoped.Editable); ok {
+		originalScopes = editable.Scopes()
+		scopes := map[string]scoped.Scope{}
+		for key, scope := range originalScopes {
+			scopes[key] = scope
+		}
+		if keys := sortedKeys(scopes); len(keys) > 0 {
+			delete(scopes, keys[0])
+		}
+		editable.SetScopes(scopes)
+	}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok {
+		recoverableCred.SetRecoveryToken("roundtrip", time.Hour)
+	}
+	if err := config.Broker.Save(credential); err != nil {
+		t.Fatalf("Save must not fail. Error: %s\n", err)
+	}
+	expected := capture(credential)
+	compare(t, expected, capture(load(t, config)))
+
+	// Restoring the credential.
+	restored := load(t, config)
+	restored.SetHashedPassword(original.hashedPassword)
+	if activable, ok := restored.(deniable.Activable); ok {
+		activable.SetActive(original.active)
+	}
+	if punishable, ok := restored.(deniable.Punishable); ok {
+		punishable.Unpunish()
+	}
+	if recoverableCred, ok := restored.(recoverable.Recoverable); ok {
+		recoverableCred.SetRecoveryToken("", 0)
+	}
+	if editable, ok := restored.(scoped.Editable); ok {
+		editable.SetScopes(originalScopes)
+	}
+	if err := config.Broker.Save(restored); err != nil {
+		t.Errorf("Save must not fail when restoring. Error: %s\n", err)
+	}
+}
+
+// Saves the credential concurrently, with different hashed
+// passwords, and checks that the stored one is one of them
+// (saves failing with credentials.ErrConcurrentModification
+// are allowed). The credential is restored afterwards.
+func ConcurrentSaves(t *testing.T, config Config) {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	original := capture(load(t, config))
+
+	var mutex sync.Mutex
+	saved := map[string]bool{}
+	var group sync.WaitGroup
+	for index := 0; index < concurrency; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			credential, err := config.Broker.ByIdentifier(config.Identifier, config.Template)
+			if err != nil || credential == nil {
+				t.Errorf("ByIdentifier must find the credential concurrently. Returned: %v, %v\n", credential, err)
+				return
+			}
+			hashed := fmt.Sprintf("concurrent-%d", index)
+			credential.SetHashedPassword(hashed)
+			if err := config.Broker.Save(credential); err == nil {
+				mutex.Lock()
+				saved[hashed] = true
+				mutex.Unlock()
+			} else if err != credentials.ErrConcurrentModification {
+				t.Errorf("Save must not fail concurrently (other than on concurrent modification). Error: %s\n", err)
+			}
+		}(index)
+	}
+	group.Wait()
+
+	credential := load(t, config)
+	if len(saved) == 0 {
+		t.Error("At least one concurrent save must succeed")
+	} else if !saved[credential.HashedPassword()] {
+		t.Errorf("The stored hashed password must be one of the saved ones. Got: %q\n", credential.HashedPassword())
+	}
+	if actual := capture(credential); actual.active != original.active || actual.token != original.token {
+		t.Error("Concurrent saves must not corrupt the other fields")
+	}
+
+	credential.SetHashedPassword(original.hashedPassword)
+	if err := config.Broker.Save(credential); err != nil {
+		t.Errorf("Save must not fail when restoring. Error: %s\n", err)
+	}
+}
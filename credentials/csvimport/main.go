@@ -0,0 +1,261 @@
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/hashing"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The prefix marking a password column as plain text. Plain
+// text passwords are never guessed: without this prefix, the
+// column must be a hash in a known format.
+const PlaintextPrefix = "plain:"
+
+// A known (legacy) hash format. Hashes matching the pattern
+// are stored as "<Name>:<hash>", so a MultipleHashingEngine
+// having an engine registered with that name validates them.
+// Formats are tried in order, so bare hex digests are taken
+// by their length (e.g. 32 digits as MD5): prefix them with
+// the format's name when that is ambiguous.
+type Format struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// The formats known out of the box. The names are just the
+// convention expected by this package: register the engines
+// with the same names, or use custom formats instead.
+var (
+	BCrypt = Format{"bcrypt", regexp.MustCompile(`^\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}$`)}
+	Argon2 = Format{"argon2", regexp.MustCompile(`^\$argon2(id|i|d)\$v=\d+\$[^$]+\$[^$]+\$[^$]+$`)}
+	PBKDF2 = Format{"pbkdf2", regexp.MustCompile(`^pbkdf2_sha(1|256|512)\$\d+\$[^$]+\$[^$]+$`)}
+	SHA256 = Format{"sha256", regexp.MustCompile(`^[0-9a-fA-F]{64}$`)}
+	SHA1   = Format{"sha1", regexp.MustCompile(`^[0-9a-fA-F]{40}$`)}
+	MD5    = Format{"md5", regexp.MustCompile(`^[0-9a-fA-F]{32}$`)}
+)
+
+// The formats used when none is given.
+var DefaultFormats = []Format{BCrypt, Argon2, PBKDF2, SHA256, SHA1, MD5}
+
+// Returned when a row does not have the expected columns.
+var ErrBadColumns = errors.New("expected columns: identifier, password, active, scopes")
+
+// Returned when a row has an empty identifier.
+var ErrEmptyIdentifier = errors.New("empty identifier")
+
+// Returned when a password is in plain text but there is
+// no hasher to hash it, or plain text is not allowed.
+var ErrPlaintextPassword = errors.New("plain text passwords are not allowed here")
+
+// Returned when a password is neither prefixed as plain text
+// nor a hash in a known format (e.g. "{SSHA}..." hashes, when
+// no format is registered for them).
+var ErrUnknownFormat = errors.New("the password is not in a known hash format")
+
+// Returned when the factory of an importer returns nil.
+var ErrNilCredential = errors.New("the factory returned a nil credential")
+
+// A row parsed from the CSV input. The columns are: identifier,
+// password (a hash in a known format, a hash already prefixed
+// with a known format's name, or a plain text password prefixed
+// with "plain:"), the active
+// flag (as understood by strconv.ParseBool, and true if empty)
+// and the space-separated scope keys. A header row starting with
+// "identifier" is skipped.
+type Row struct {
+	Line       int
+	Identifier string
+	Password   string
+	Active     bool
+	Scopes     []string
+}
+
+// An error related to a single row. The identifier is empty if
+// the row could not be parsed.
+type RowError struct {
+	Line       int
+	Identifier string
+	Err        error
+}
+
+func (rowError *RowError) Error() string {
+	if rowError.Identifier == "" {
+		return fmt.Sprintf("line %d: %s", rowError.Line, rowError.Err)
+	} else {
+		return fmt.Sprintf("line %d (%s): %s", rowError.Line, rowError.Identifier, rowError.Err)
+	}
+}
+
+func (rowError *RowError) Unwrap() error {
+	return rowError.Err
+}
+
+// Parses all the rows in the CSV input. Malformed rows are
+// reported as row errors, and the parsing continues. Only
+// errors reading the input are returned as the third value.
+func Parse(reader io.Reader) ([]*Row, []*RowError, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	var rows []*Row
+	var rowErrors []*RowError
+	for first := true; ; first = false {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return rows, rowErrors, nil
+		} else if parseErr, ok := err.(*csv.ParseError); ok {
+			rowErrors = append(rowErrors, &RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "identifier") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 4 {
+			rowErrors = append(rowErrors, &RowError{Line: line, Err: ErrBadColumns})
+			continue
+		}
+		row := &Row{Line: line, Identifier: strings.TrimSpace(record[0]), Password: record[1], Active: true}
+		if row.Identifier == "" {
+			rowErrors = append(rowErrors, &RowError{Line: line, Err: ErrEmptyIdentifier})
+			continue
+		}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			if row.Active, err = strconv.ParseBool(strings.TrimSpace(record[2])); err != nil {
+				rowErrors = append(rowErrors, &RowError{line, row.Identifier, err})
+				continue
+			}
+		}
+		if len(record) > 3 {
+			row.Scopes = strings.Fields(record[3])
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Converts the password column of a row into a hashed password,
+// ready to be set in a credential. Empty passwords are kept empty
+// (the credential will always fail to log in). Known hashes are
+// prefixed with the format's name (unless already prefixed). Plain
+// text passwords (prefixed with "plain:") are hashed with the given
+// hasher, or rejected with ErrPlaintextPassword if the hasher is
+// nil. Anything else is rejected with ErrUnknownFormat.
+func HashedPassword(password string, formats []Format, hasher hashing.HashingEngine) (string, error) {
+	if formats == nil {
+		formats = DefaultFormats
+	}
+	if password == "" {
+		return "", nil
+	} else if strings.HasPrefix(password, PlaintextPrefix) {
+		if hasher == nil {
+			return "", ErrPlaintextPassword
+		} else {
+			return hasher.Hash(strings.TrimPrefix(password, PlaintextPrefix))
+		}
+	}
+
+	// Prefixed hashes are checked first, so a prefixed hash is
+	// kept as is even if an earlier format matches it as well.
+	for _, format := range formats {
+		if prefix := format.Name + ":"; strings.HasPrefix(password, prefix) &&
+			format.Pattern.MatchString(strings.TrimPrefix(password, prefix)) {
+			return password, nil
+		}
+	}
+	for _, format := range formats {
+		if format.Pattern.MatchString(password) {
+			return format.Name + ":" + password, nil
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// Importers create credentials from the CSV rows, through a broker
+// which must be a credentials.Creator. The factory must create a new
+// credential with the data that cannot be set via traits (i.e. the
+// identifier, the scopes, and perhaps an index), and the importer
+// will set the hashed password and the active flag. Plain text
+// passwords ("plain:" prefixed) are hashed with the new credential's
+// hasher, unless RejectPlaintext is true. Formats are the known hash formats
+// (DefaultFormats, if nil).
+type Importer struct {
+	Broker          credentials.Broker
+	Factory         func(row *Row) (credentials.Credential, error)
+	Formats         []Format
+	RejectPlaintext bool
+}
+
+// The outcome of an import: how many credentials were created,
+// and the errors of the rows that were not.
+type Report struct {
+	Created int
+	Errors  []*RowError
+}
+
+// Creates a credential from a single row.
+func (importer *Importer) ImportRow(row *Row) (credentials.Credential, error) {
+	credential, err := importer.Factory(row)
+	if err != nil {
+		return nil, err
+	} else if credential == nil {
+		return nil, ErrNilCredential
+	}
+
+	var hasher hashing.HashingEngine
+	if !importer.RejectPlaintext {
+		hasher = credential.Hasher()
+	}
+	if hashed, err := HashedPassword(row.Password, importer.Formats, hasher); err != nil {
+		return nil, err
+	} else {
+		credential.SetHashedPassword(hashed)
+	}
+	if activable, ok := credential.(deniable.Activable); ok {
+		activable.SetActive(row.Active)
+	}
+
+	if err := credentials.Create(importer.Broker, credential); err != nil {
+		return nil, err
+	} else {
+		return credential, nil
+	}
+}
+
+// Parses the CSV input and creates a credential for each row. Rows
+// failing to be parsed or created are reported, and the import goes
+// on (the errors are sorted by line). Only errors reading the input are returned as error (also,
+// credentials.ErrCreationNotSupported, without importing any row).
+func (importer *Importer) Import(reader io.Reader) (*Report, error) {
+	if _, ok := importer.Broker.(credentials.Creator); !ok {
+		return nil, credentials.ErrCreationNotSupported
+	}
+
+	rows, rowErrors, err := Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Errors: rowErrors}
+	for _, row := range rows {
+		if _, err := importer.ImportRow(row); err != nil {
+			report.Errors = append(report.Errors, &RowError{row.Line, row.Identifier, err})
+		} else {
+			report.Created++
+		}
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Line < report.Errors[j].Line
+	})
+	return report, nil
+}
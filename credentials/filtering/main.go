@@ -0,0 +1,103 @@
+package filtering
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/staff"
+	"github.com/universe-10th/identity/credentials/traits/superuser"
+	"sort"
+	"time"
+)
+
+// Tells whether a credential is currently punished
+// (i.e. punished permanently, or for a duration that
+// did not elapse yet).
+func Punished(credential credentials.Credential) bool {
+	if punishable, ok := credential.(deniable.Punishable); !ok {
+		return false
+	} else if punishedOn, punishedFor, _, _ := punishable.PunishedFor(); punishedOn == nil {
+		return false
+	} else {
+		return punishedFor == nil || punishedOn.Add(*punishedFor).After(time.Now())
+	}
+}
+
+// Tells whether a credential matches all the criteria
+// in the given filter.
+func Matches(credential credentials.Credential, filter credentials.Filter) bool {
+	if filter.Active != nil {
+		activable, ok := credential.(deniable.Activable)
+		if *filter.Active != (ok && activable.Active()) {
+			return false
+		}
+	}
+	if filter.Punished != nil && *filter.Punished != Punished(credential) {
+		return false
+	}
+	if filter.Staff != nil {
+		capable, ok := credential.(staff.StaffCapable)
+		if *filter.Staff != (ok && capable.Staff()) {
+			return false
+		}
+	}
+	if filter.Superuser != nil {
+		capable, ok := credential.(superuser.SuperuserCapable)
+		if *filter.Superuser != (ok && capable.Superuser()) {
+			return false
+		}
+	}
+	if filter.Scope != "" {
+		if scopedCred, ok := credential.(scoped.Scoped); !ok {
+			return false
+		} else if _, ok := scopedCred.Scopes()[filter.Scope]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Default page size used by Page when the limit is <= 0.
+const DefaultLimit = 50
+
+// Returned error when the cursor given to Page is not valid.
+var ErrBadCursor = errors.New("invalid cursor")
+
+// A sort key of the credentials, which must be unique (like a
+// primary key) for Page to resume from the last key it returned.
+type Key func(credential credentials.Credential) string
+
+// Paginates a list of credentials sorted (ascending) by a key,
+// applying a filter. Meant to be used by in-memory brokers or
+// brokers that cannot filter on their own. The cursor encodes
+// the key of the last returned credential, so the pages resume
+// after it even if credentials are added or removed meanwhile.
+func Page(all []credentials.Credential, key Key, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	start := 0
+	if cursor != "" {
+		if last, err := base64.RawURLEncoding.DecodeString(cursor); err != nil {
+			return nil, "", ErrBadCursor
+		} else {
+			start = sort.Search(len(all), func(position int) bool {
+				return key(all[position]) > string(last)
+			})
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	result := make([]credentials.Credential, 0, limit)
+	for position := start; position < len(all); position++ {
+		if !Matches(all[position], filter) {
+			continue
+		}
+		if len(result) == limit {
+			return result, base64.RawURLEncoding.EncodeToString([]byte(key(result[limit-1]))), nil
+		}
+		result = append(result, all[position])
+	}
+	return result, "", nil
+}
@@ -0,0 +1,61 @@
+package credentials
+
+import "errors"
+
+// Filters to apply when listing credentials. Only the
+// non-nil fields (or the non-empty scope key) will be
+// applied, and all of them must match. Credentials not
+// implementing a filtered trait count as inactive, not
+// punished, not staff, not superuser and scope-less,
+// respectively.
+type Filter struct {
+	Active    *bool
+	Punished  *bool
+	Staff     *bool
+	Superuser *bool
+	Scope     string
+}
+
+// Listers are brokers that can also enumerate their
+// credentials of a given template type, one page at
+// a time. Cursors are opaque strings: the empty one
+// stands for the first page, and the returned one
+// must be given to retrieve the next page (it will be
+// empty after the last page). A limit <= 0 lets the
+// lister choose its own page size.
+type Lister interface {
+	List(template Credential, filter Filter, cursor string, limit int) ([]Credential, string, error)
+}
+
+// Returned error when listing through a broker that is not a Lister.
+var ErrListingNotSupported = errors.New("the broker does not support listing")
+
+// Lists through the broker if it is a Lister, or fails with
+// ErrListingNotSupported. Meant to be used by sources and by
+// broker decorators bypassing the listing feature.
+func List(broker Broker, template Credential, filter Filter, cursor string, limit int) ([]Credential, string, error) {
+	if lister, ok := broker.(Lister); !ok {
+		return nil, "", ErrListingNotSupported
+	} else {
+		return lister.List(template, filter, cursor, limit)
+	}
+}
+
+// Bypasses its implementation to the broker (which must be a
+// Lister) but using the chosen template instance.
+func (source *TypedSource[T]) List(filter Filter, cursor string, limit int) ([]T, string, error) {
+	list, next, err := List(source.broker, source.template, filter, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]T, len(list))
+	for index, credential := range list {
+		if typed, ok := credential.(T); !ok {
+			return nil, "", ErrBadTypeOnLoad
+		} else {
+			result[index] = typed
+		}
+	}
+	return result, next, nil
+}
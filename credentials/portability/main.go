@@ -0,0 +1,347 @@
+package portability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/staff"
+	"github.com/universe-10th/identity/credentials/traits/superuser"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"sort"
+	"time"
+)
+
+// The current version of the exported documents. Version 1
+// documents (with no login history, WebAuthn credentials or TOTP
+// enrollment) are still imported.
+const DocumentVersion = 2
+
+// An exported scope.
+type Scope struct {
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// An exported punishment. A nil Until stands for a permanent
+// punishment, and By holds the identification of the punisher
+// (if it is known and identified).
+type Punishment struct {
+	On     time.Time   `json:"on"`
+	Until  *time.Time  `json:"until"`
+	Reason string      `json:"reason,omitempty"`
+	By     interface{} `json:"by,omitempty"`
+}
+
+// An exported recovery state. The token is only exported when
+// secrets are included.
+type Recovery struct {
+	Pending    bool       `json:"pending"`
+	Token      string     `json:"token,omitempty"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// An exported login history entry.
+type HistoryEntry struct {
+	Time      time.Time `json:"time"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	DeviceID  string    `json:"device_id,omitempty"`
+	Outcome   string    `json:"outcome"`
+}
+
+// An exported login history, the newest entry first. The times
+// are nil when there was no such login.
+type History struct {
+	LastLogin       *time.Time     `json:"last_login,omitempty"`
+	LastFailedLogin *time.Time     `json:"last_failed_login,omitempty"`
+	Entries         []HistoryEntry `json:"entries,omitempty"`
+}
+
+// An exported WebAuthn public key credential. It is only usable
+// by the same relying party it was registered for.
+type WebAuthnCredential struct {
+	ID        []byte `json:"id"`
+	PublicKey []byte `json:"public_key"`
+	SignCount uint32 `json:"sign_count"`
+}
+
+// An exported TOTP enrollment (or a pending one, if not enrolled).
+// The secret and the last used step are only exported when secrets
+// are included.
+type TOTP struct {
+	Enrolled bool   `json:"enrolled"`
+	Secret   []byte `json:"secret,omitempty"`
+	LastStep int64  `json:"last_step,omitempty"`
+}
+
+// An exported credential. Only the implemented traits are
+// present, and the hashed password (as well as the recovery
+// token and the TOTP secret) is only present when secrets are
+// included. Recovery codes and passcodes are never exported:
+// they must be generated again after importing.
+type Document struct {
+	Version           int         `json:"version"`
+	Identification    interface{} `json:"identification,omitempty"`
+	Index             interface{} `json:"index,omitempty"`
+	HashedPassword    string      `json:"hashed_password,omitempty"`
+	Active            *bool       `json:"active,omitempty"`
+	Punishment        *Punishment `json:"punishment,omitempty"`
+	Scopes            []Scope     `json:"scopes,omitempty"`
+	Staff             *bool       `json:"staff,omitempty"`
+	Superuser         *bool       `json:"superuser,omitempty"`
+	Recovery          *Recovery   `json:"recovery,omitempty"`
+	CredentialVersion *uint64     `json:"credential_version,omitempty"`
+	// Since version 2.
+	History  *History             `json:"history,omitempty"`
+	WebAuthn []WebAuthnCredential `json:"webauthn,omitempty"`
+	TOTP     *TOTP                `json:"totp,omitempty"`
+}
+
+// Returned when importing a document of an unsupported version.
+var ErrUnsupportedVersion = errors.New("unsupported document version")
+
+// Returned when the factory of an importer returns nil.
+var ErrNilCredential = errors.New("the factory returned a nil credential")
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// Returns a pointer to the time, or nil if it is zero.
+func timePtr(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
+
+// Tells the pending recovery token of a credential. Expiring
+// credentials are checked first, so reading an expired token
+// (which clears it) does not change the exported credential.
+func pendingToken(credential recoverable.Recoverable) (string, *time.Time) {
+	expiring, ok := credential.(recoverable.Expiring)
+	if !ok {
+		return credential.RecoveryToken(), nil
+	}
+	expiration := expiring.RecoveryTokenExpiration()
+	if !expiration.After(time.Now()) {
+		return "", nil
+	}
+	return credential.RecoveryToken(), &expiration
+}
+
+// Walks the implemented traits of a credential and exports them
+// into a document. The hashed password, the recovery token and
+// the TOTP secret are secrets: they are only exported when
+// includeSecrets is true (e.g. for moving users between
+// environments, but not for data access requests). Only the
+// credentials that do not implement recoverable.Expiring are
+// changed by exporting them, when their recovery token expired
+// (which clears it).
+func Export(credential credentials.Credential, includeSecrets bool) *Document {
+	document := &Document{Version: DocumentVersion}
+	if includeSecrets {
+		document.HashedPassword = credential.HashedPassword()
+	}
+	if identifiedCred, ok := credential.(identified.Identified); ok {
+		document.Identification = identifiedCred.Identification()
+	}
+	if indexedCred, ok := credential.(indexed.Indexed); ok {
+		document.Index = indexedCred.Index()
+	}
+	if activable, ok := credential.(deniable.Activable); ok {
+		document.Active = boolPtr(activable.Active())
+	}
+	if punishable, ok := credential.(deniable.Punishable); ok {
+		if punishedOn, punishedFor, reason, by := punishable.PunishedFor(); punishedOn != nil {
+			punishment := &Punishment{On: *punishedOn}
+			if punishedFor != nil {
+				until := punishedOn.Add(*punishedFor)
+				punishment.Until = &until
+			}
+			if reason != nil {
+				punishment.Reason = fmt.Sprint(reason)
+			}
+			if identifiedBy, ok := by.(identified.Identified); ok {
+				punishment.By = identifiedBy.Identification()
+			}
+			document.Punishment = punishment
+		}
+	}
+	if scopedCred, ok := credential.(scoped.Scoped); ok {
+		for _, scope := range scopedCred.Scopes() {
+			document.Scopes = append(document.Scopes, Scope{scope.Key(), scope.Name(), scope.Description()})
+		}
+		sort.Slice(document.Scopes, func(i, j int) bool {
+			return document.Scopes[i].Key < document.Scopes[j].Key
+		})
+	}
+	if capable, ok := credential.(staff.StaffCapable); ok {
+		document.Staff = boolPtr(capable.Staff())
+	}
+	if capable, ok := credential.(superuser.SuperuserCapable); ok {
+		document.Superuser = boolPtr(capable.Superuser())
+	}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok {
+		recovery := &Recovery{}
+		if token, expiration := pendingToken(recoverableCred); token != "" {
+			recovery.Pending = true
+			if includeSecrets {
+				recovery.Token = token
+			}
+			recovery.Expiration = expiration
+		}
+		document.Recovery = recovery
+	}
+	if versionedCred, ok := credential.(versioned.Versioned); ok {
+		version := versionedCred.Version()
+		document.CredentialVersion = &version
+	}
+	if tracked, ok := credential.(history.Tracked); ok {
+		document.History = &History{
+			LastLogin:       timePtr(tracked.LastLogin()),
+			LastFailedLogin: timePtr(tracked.LastFailedLogin()),
+		}
+		for _, entry := range tracked.LoginHistory() {
+			document.History.Entries = append(document.History.Entries, HistoryEntry(entry))
+		}
+	}
+	if capable, ok := credential.(webauthn.WebAuthnCapable); ok {
+		for _, registered := range capable.WebAuthnCredentials() {
+			document.WebAuthn = append(document.WebAuthn, WebAuthnCredential{
+				ID:        append([]byte(nil), registered.ID...),
+				PublicKey: append([]byte(nil), registered.PublicKey...),
+				SignCount: registered.SignCount,
+			})
+		}
+	}
+	if capable, ok := credential.(otp.TOTPCapable); ok {
+		if secret, enrolled := capable.TOTPSecret(); secret != nil {
+			document.TOTP = &TOTP{Enrolled: enrolled}
+			if includeSecrets {
+				document.TOTP.Secret = append([]byte(nil), secret...)
+				document.TOTP.LastStep = capable.TOTPLastStep()
+			}
+		}
+	}
+	return document
+}
+
+// Exports a credential as a JSON document. See Export.
+func Marshal(credential credentials.Credential, includeSecrets bool) ([]byte, error) {
+	return json.MarshalIndent(Export(credential, includeSecrets), "", "  ")
+}
+
+// Parses a JSON document. Numeric identifications and indices
+// are parsed as float64, like in any other JSON decoding.
+func Unmarshal(data []byte) (*Document, error) {
+	document := &Document{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, err
+	} else if document.Version < 1 || document.Version > DocumentVersion {
+		return nil, ErrUnsupportedVersion
+	} else {
+		return document, nil
+	}
+}
+
+// Importers recreate credentials from documents, through a
+// broker which must be a credentials.Creator. The factory must
+// create a new credential with the data that cannot be set via
+// traits (i.e. identification, index, scopes, and staff and
+// superuser flags), and the importer will set the rest: hashed
+// password, active flag, punishment (keeping its end time),
+// recovery token (only if not expired), login history, WebAuthn
+// credentials and TOTP enrollment (only if its secret was
+// exported). Punisher, if not nil,
+// resolves the punisher identification into a credential. The
+// punishment reason is imported as a string.
+type Importer struct {
+	Broker   credentials.Broker
+	Factory  func(document *Document) (credentials.Credential, error)
+	Punisher func(identification interface{}) (credentials.Credential, error)
+}
+
+// Recreates a credential from a document, and creates it
+// through the broker.
+func (importer *Importer) Import(document *Document) (credentials.Credential, error) {
+	if document.Version < 1 || document.Version > DocumentVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	credential, err := importer.Factory(document)
+	if err != nil {
+		return nil, err
+	} else if credential == nil {
+		return nil, ErrNilCredential
+	}
+
+	credential.SetHashedPassword(document.HashedPassword)
+	if activable, ok := credential.(deniable.Activable); ok && document.Active != nil {
+		activable.SetActive(*document.Active)
+	}
+	if punishable, ok := credential.(deniable.Punishable); ok && document.Punishment != nil {
+		var by credentials.Credential
+		if importer.Punisher != nil && document.Punishment.By != nil {
+			if by, err = importer.Punisher(document.Punishment.By); err != nil {
+				return nil, err
+			}
+		}
+		var reason interface{}
+		if document.Punishment.Reason != "" {
+			reason = document.Punishment.Reason
+		}
+		if document.Punishment.Until == nil {
+			punishable.Punish(nil, reason, by)
+		} else if remaining := time.Until(*document.Punishment.Until); remaining > 0 {
+			punishable.Punish(&remaining, reason, by)
+		}
+	}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok && document.Recovery != nil &&
+		document.Recovery.Token != "" && document.Recovery.Expiration != nil {
+		if remaining := time.Until(*document.Recovery.Expiration); remaining > 0 {
+			recoverableCred.SetRecoveryToken(document.Recovery.Token, remaining)
+		}
+	}
+	if tracked, ok := credential.(history.Tracked); ok && document.History != nil {
+		var lastLogin, lastFailedLogin time.Time
+		if document.History.LastLogin != nil {
+			lastLogin = *document.History.LastLogin
+		}
+		if document.History.LastFailedLogin != nil {
+			lastFailedLogin = *document.History.LastFailedLogin
+		}
+		entries := make([]history.Entry, len(document.History.Entries))
+		for index, entry := range document.History.Entries {
+			entries[index] = history.Entry(entry)
+		}
+		tracked.SetLoginHistory(lastLogin, lastFailedLogin, entries)
+	}
+	if capable, ok := credential.(webauthn.WebAuthnCapable); ok && len(document.WebAuthn) > 0 {
+		registered := make([]webauthn.PublicKeyCredential, len(document.WebAuthn))
+		for index, exported := range document.WebAuthn {
+			registered[index] = webauthn.PublicKeyCredential(exported)
+		}
+		capable.SetWebAuthnCredentials(registered)
+	}
+	if capable, ok := credential.(otp.TOTPCapable); ok && document.TOTP != nil && document.TOTP.Secret != nil {
+		capable.SetTOTPSecret(document.TOTP.Secret, document.TOTP.Enrolled)
+		capable.SetTOTPLastStep(document.TOTP.LastStep)
+	}
+
+	if err := credentials.Create(importer.Broker, credential); err != nil {
+		return nil, err
+	} else {
+		return credential, nil
+	}
+}
@@ -32,27 +32,104 @@ var ErrNilBroker = errors.New("the given broker is nil")
 // chosen broker.
 var ErrBadTemplate = errors.New("the given template is nil or not allowed by the broker")
 
+// Panicked error when attempting to create an untyped source with
+// a template whose zero value would not be a usable dummy: structs
+// embedding interfaces that supply the Credential methods (e.g. the
+// tagged credentials), since those methods would panic. Use the
+// NewTypedSource function with a factory instead.
+var ErrNoReflectiveDummy = errors.New("the template cannot be instantiated via reflection: use a typed source with a factory")
+
 var ErrNilValueOnSave = errors.New("the credential being saved is nil")
 
 // Returned error when the credential being saved is of a
 // different type than the one of the source.
 var ErrBadTypeOnSave = errors.New("the credential being saved is of a different type")
 
+// Returned error, by brokers, when saving a credential that
+// was modified (and saved) by someone else after it was loaded.
+// See the credentials/traits/versioned.Versioned trait.
+var ErrConcurrentModification = errors.New("the credential was concurrently modified")
+
+// Returned error when a broker returns a credential of a type
+// different than the one of the (typed) source.
+var ErrBadTypeOnLoad = errors.New("the loaded credential is of a different type")
+
+// Panicked error when attempting to create a typed source with
+// a nil factory.
+var ErrNilFactory = errors.New("the given factory is nil")
+
+// Typed sources are a combination of an existing broker instance
+// and a factory of credentials of a given type T. Typed sources
+// will proxy the calls to a broker (using a credential created by
+// the factory as template), returning credentials of type T, and
+// also will be able to instantiate dummy objects of type T.
+type TypedSource[T Credential] struct {
+	broker   Broker
+	template T
+	factory  func() T
+	check    func(T) error
+}
+
 // Sources are a combination of an existing broker instance and
 // a non-nil Credential instance that will serve as template.
 // Sources will proxy the calls to a broker, and also will be
 // able to instantiate dummy objects of the same type of the
-// given template.
-type Source struct {
-	broker   Broker
-	template Credential
-	tmplType reflect.Type
-	factory  func() Credential
+// given template. They are the untyped version of TypedSource.
+type Source = TypedSource[Credential]
+
+// Creates a new typed source for a given broker and factory, if
+// they match together. Panics if either is nil, or the broker does
+// not allow the credentials created by the factory.
+func NewTypedSource[T Credential](broker Broker, factory func() T) *TypedSource[T] {
+	if broker == nil {
+		panic(ErrNilBroker)
+	} else if factory == nil {
+		panic(ErrNilFactory)
+	}
+
+	template := factory()
+	if isNil(template) || !broker.Allows(template) {
+		panic(ErrBadTemplate)
+	}
+	return &TypedSource[T]{broker: broker, template: template, factory: factory}
+}
+
+var credentialInterface = reflect.TypeOf((*Credential)(nil)).Elem()
+
+// Tells whether the zero value of a type embeds interfaces (which
+// would be nil) supplying any of the Credential methods, directly
+// or through embedded structs (or pointers to them).
+func embedsCredentialInterfaces(credType reflect.Type, visited map[reflect.Type]bool) bool {
+	if credType.Kind() == reflect.Ptr {
+		credType = credType.Elem()
+	}
+	if credType.Kind() != reflect.Struct || visited[credType] {
+		return false
+	}
+	visited[credType] = true
+	for index := 0; index < credType.NumField(); index++ {
+		field := credType.Field(index)
+		if !field.Anonymous {
+			continue
+		} else if field.Type.Kind() == reflect.Interface {
+			for method := 0; method < credentialInterface.NumMethod(); method++ {
+				if _, ok := field.Type.MethodByName(credentialInterface.Method(method).Name); ok {
+					return true
+				}
+			}
+		} else if embedsCredentialInterfaces(field.Type, visited) {
+			return true
+		}
+	}
+	return false
 }
 
 // Creates a new source for a given broker and template, if they
 // match together. Panics if either is nil, or the broker does
-// not allow it.
+// not allow it. Since the type of the template is only known at
+// run time, dummy objects are created via reflection (so it
+// panics with ErrNoReflectiveDummy if they would not be usable)
+// and saved credentials are checked to be of the same type.
 func NewSource(broker Broker, template Credential) *Source {
 	if broker == nil {
 		panic(ErrNilBroker)
@@ -61,6 +138,9 @@ func NewSource(broker Broker, template Credential) *Source {
 	}
 
 	credType := reflect.TypeOf(template)
+	if embedsCredentialInterfaces(credType, map[reflect.Type]bool{}) {
+		panic(ErrNoReflectiveDummy)
+	}
 	var factory func() Credential
 	if credType.Kind() == reflect.Ptr {
 		credElemType := credType.Elem()
@@ -72,35 +152,85 @@ func NewSource(broker Broker, template Credential) *Source {
 			return reflect.New(credType).Elem().Interface().(Credential)
 		}
 	}
-	return &Source{broker, template, credType, factory}
+	check := func(credential Credential) error {
+		if reflect.TypeOf(credential) != credType {
+			return ErrBadTypeOnSave
+		} else {
+			return nil
+		}
+	}
+	return &Source{broker: broker, template: template, factory: factory, check: check}
+}
+
+func (source *TypedSource[T]) typed(credential Credential, err error) (T, bool, error) {
+	var zero T
+	if err != nil {
+		return zero, false, err
+	} else if credential == nil {
+		return zero, false, nil
+	} else if result, ok := credential.(T); !ok {
+		return zero, false, ErrBadTypeOnLoad
+	} else {
+		return result, true, nil
+	}
 }
 
 // Bypasses its implementation to the broker but using the chosen
-// template instance.
-func (source *Source) ByIdentifier(identifier interface{}) (Credential, error) {
-	return source.broker.ByIdentifier(identifier, source.template)
+// template instance. Also tells whether the credential was found.
+func (source *TypedSource[T]) LookupByIdentifier(identifier interface{}) (T, bool, error) {
+	return source.typed(source.broker.ByIdentifier(identifier, source.template))
 }
 
 // Bypasses its implementation to the broker but using the chosen
-// template instance.
-func (source *Source) ByIndex(index interface{}) (Credential, error) {
-	return source.broker.ByIndex(index, source.template)
+// template instance. Also tells whether the credential was found.
+func (source *TypedSource[T]) LookupByIndex(index interface{}) (T, bool, error) {
+	return source.typed(source.broker.ByIndex(index, source.template))
 }
 
 // Bypasses its implementation to the broker but using the chosen
-// template instance, adding a check type.
-func (source *Source) Save(credential Credential) error {
+// template instance. The zero value of T is returned when no
+// credential is found.
+func (source *TypedSource[T]) ByIdentifier(identifier interface{}) (T, error) {
+	result, _, err := source.LookupByIdentifier(identifier)
+	return result, err
+}
+
+// Bypasses its implementation to the broker but using the chosen
+// template instance. The zero value of T is returned when no
+// credential is found.
+func (source *TypedSource[T]) ByIndex(index interface{}) (T, error) {
+	result, _, err := source.LookupByIndex(index)
+	return result, err
+}
+
+func isNil(credential Credential) bool {
 	if credential == nil {
+		return true
+	}
+	switch value := reflect.ValueOf(credential); value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// Bypasses its implementation to the broker but using the chosen
+// template instance, adding a nil check (and, for untyped sources,
+// a type check).
+func (source *TypedSource[T]) Save(credential T) error {
+	if isNil(credential) {
 		return ErrNilValueOnSave
-	} else if reflect.TypeOf(credential) != source.tmplType {
-		return ErrBadTypeOnSave
-	} else {
-		return source.broker.Save(credential)
+	} else if source.check != nil {
+		if err := source.check(credential); err != nil {
+			return err
+		}
 	}
+	return source.broker.Save(credential)
 }
 
 // Creates a dummy credential object, used for security
 // purposes following a fake login cycle.
-func (source *Source) Dummy() Credential {
+func (source *TypedSource[T]) Dummy() T {
 	return source.factory()
 }
@@ -0,0 +1,43 @@
+package credentials
+
+import "errors"
+
+// Creators are brokers that can also store new credentials
+// (i.e. credentials that were not loaded from them).
+type Creator interface {
+	Create(credential Credential) error
+}
+
+// Deleters are brokers that can also delete their existing
+// credentials.
+type Deleter interface {
+	Delete(credential Credential) error
+}
+
+// Returned error when creating through a broker that is not a Creator.
+var ErrCreationNotSupported = errors.New("the broker does not support creating credentials")
+
+// Returned error when deleting through a broker that is not a Deleter.
+var ErrDeletionNotSupported = errors.New("the broker does not support deleting credentials")
+
+// Creates through the broker if it is a Creator, or fails with
+// ErrCreationNotSupported. Meant to be used by broker decorators
+// bypassing the creation feature, among others.
+func Create(broker Broker, credential Credential) error {
+	if creator, ok := broker.(Creator); !ok {
+		return ErrCreationNotSupported
+	} else {
+		return creator.Create(credential)
+	}
+}
+
+// Deletes through the broker if it is a Deleter, or fails with
+// ErrDeletionNotSupported. Meant to be used by broker decorators
+// bypassing the deletion feature, among others.
+func Delete(broker Broker, credential Credential) error {
+	if deleter, ok := broker.(Deleter); !ok {
+		return ErrDeletionNotSupported
+	} else {
+		return deleter.Delete(credential)
+	}
+}
@@ -0,0 +1,4631 @@
+// Code generated by go run ./internal/gen; DO NOT EDIT.
+
+package tagged
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/staff"
+	"github.com/universe-10th/identity/credentials/traits/superuser"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+)
+
+// Combines the adapter into a value exposing only the given traits.
+func combine(adapter *Adapter, traits uint) credentials.Credential {
+	switch traits {
+	case 0:
+		return struct {
+			base
+		}{adapter}
+	case 1:
+		return struct {
+			base
+			deniable.Activable
+		}{adapter, adapter}
+	case 2:
+		return struct {
+			base
+			deniable.Punishable
+		}{adapter, adapter}
+	case 3:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+		}{adapter, adapter, adapter}
+	case 4:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+		}{adapter, adapter, adapter}
+	case 5:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+		}{adapter, adapter, adapter, adapter}
+	case 6:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+		}{adapter, adapter, adapter, adapter}
+	case 7:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 8:
+		return struct {
+			base
+			identified.Reidentifiable
+		}{adapter, adapter}
+	case 9:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+		}{adapter, adapter, adapter}
+	case 10:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+		}{adapter, adapter, adapter}
+	case 11:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+		}{adapter, adapter, adapter, adapter}
+	case 12:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+		}{adapter, adapter, adapter, adapter}
+	case 13:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 14:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 15:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 16:
+		return struct {
+			base
+			indexed.Indexed
+		}{adapter, adapter}
+	case 17:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+		}{adapter, adapter, adapter}
+	case 18:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+		}{adapter, adapter, adapter}
+	case 19:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter}
+	case 20:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter}
+	case 21:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 22:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 23:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 24:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter}
+	case 25:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter}
+	case 26:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter}
+	case 27:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 28:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 29:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 30:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 31:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 32:
+		return struct {
+			base
+			scoped.Editable
+		}{adapter, adapter}
+	case 33:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+		}{adapter, adapter, adapter}
+	case 34:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+		}{adapter, adapter, adapter}
+	case 35:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 36:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 37:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 38:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 39:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 40:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter}
+	case 41:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 42:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 43:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 44:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 45:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 46:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 47:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 48:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter}
+	case 49:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 50:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 51:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 52:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 53:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 54:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 55:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 56:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter}
+	case 57:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 58:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 59:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 60:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 61:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 62:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 63:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 64:
+		return struct {
+			base
+			staff.StaffCapable
+		}{adapter, adapter}
+	case 65:
+		return struct {
+			base
+			deniable.Activable
+			staff.StaffCapable
+		}{adapter, adapter, adapter}
+	case 66:
+		return struct {
+			base
+			deniable.Punishable
+			staff.StaffCapable
+		}{adapter, adapter, adapter}
+	case 67:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 68:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 69:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 70:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 71:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 72:
+		return struct {
+			base
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter}
+	case 73:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 74:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 75:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 76:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 77:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 78:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 79:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 80:
+		return struct {
+			base
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter}
+	case 81:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 82:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 83:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 84:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 85:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 86:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 87:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 88:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 89:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 90:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 91:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 92:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 93:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 94:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 95:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 96:
+		return struct {
+			base
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter}
+	case 97:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 98:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 99:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 100:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 101:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 102:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 103:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 104:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 105:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 106:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 107:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 108:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 109:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 110:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 111:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 112:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter}
+	case 113:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 114:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 115:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 116:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 117:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 118:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 119:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 120:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 121:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 122:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 123:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 124:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 125:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 126:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 127:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 128:
+		return struct {
+			base
+			superuser.SuperuserCapable
+		}{adapter, adapter}
+	case 129:
+		return struct {
+			base
+			deniable.Activable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 130:
+		return struct {
+			base
+			deniable.Punishable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 131:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 132:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 133:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 134:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 135:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 136:
+		return struct {
+			base
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 137:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 138:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 139:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 140:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 141:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 142:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 143:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 144:
+		return struct {
+			base
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 145:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 146:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 147:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 148:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 149:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 150:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 151:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 152:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 153:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 154:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 155:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 156:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 157:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 158:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 159:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 160:
+		return struct {
+			base
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 161:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 162:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 163:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 164:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 165:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 166:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 167:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 168:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 169:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 170:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 171:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 172:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 173:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 174:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 175:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 176:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 177:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 178:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 179:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 180:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 181:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 182:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 183:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 184:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 185:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 186:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 187:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 188:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 189:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 190:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 191:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 192:
+		return struct {
+			base
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter}
+	case 193:
+		return struct {
+			base
+			deniable.Activable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 194:
+		return struct {
+			base
+			deniable.Punishable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 195:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 196:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 197:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 198:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 199:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 200:
+		return struct {
+			base
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 201:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 202:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 203:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 204:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 205:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 206:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 207:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 208:
+		return struct {
+			base
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 209:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 210:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 211:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 212:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 213:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 214:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 215:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 216:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 217:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 218:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 219:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 220:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 221:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 222:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 223:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 224:
+		return struct {
+			base
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter}
+	case 225:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 226:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 227:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 228:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 229:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 230:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 231:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 232:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 233:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 234:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 235:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 236:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 237:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 238:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 239:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 240:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 241:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 242:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 243:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 244:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 245:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 246:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 247:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 248:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 249:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 250:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 251:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 252:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 253:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 254:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 255:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 256:
+		return struct {
+			base
+			versioned.Versioned
+		}{adapter, adapter}
+	case 257:
+		return struct {
+			base
+			deniable.Activable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 258:
+		return struct {
+			base
+			deniable.Punishable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 259:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 260:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 261:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 262:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 263:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 264:
+		return struct {
+			base
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 265:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 266:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 267:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 268:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 269:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 270:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 271:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 272:
+		return struct {
+			base
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 273:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 274:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 275:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 276:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 277:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 278:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 279:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 280:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 281:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 282:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 283:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 284:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 285:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 286:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 287:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 288:
+		return struct {
+			base
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 289:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 290:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 291:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 292:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 293:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 294:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 295:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 296:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 297:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 298:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 299:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 300:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 301:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 302:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 303:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 304:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 305:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 306:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 307:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 308:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 309:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 310:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 311:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 312:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 313:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 314:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 315:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 316:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 317:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 318:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 319:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 320:
+		return struct {
+			base
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 321:
+		return struct {
+			base
+			deniable.Activable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 322:
+		return struct {
+			base
+			deniable.Punishable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 323:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 324:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 325:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 326:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 327:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 328:
+		return struct {
+			base
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 329:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 330:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 331:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 332:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 333:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 334:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 335:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 336:
+		return struct {
+			base
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 337:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 338:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 339:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 340:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 341:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 342:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 343:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 344:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 345:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 346:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 347:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 348:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 349:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 350:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 351:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 352:
+		return struct {
+			base
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 353:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 354:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 355:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 356:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 357:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 358:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 359:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 360:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 361:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 362:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 363:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 364:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 365:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 366:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 367:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 368:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 369:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 370:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 371:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 372:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 373:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 374:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 375:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 376:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 377:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 378:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 379:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 380:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 381:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 382:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 383:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 384:
+		return struct {
+			base
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter}
+	case 385:
+		return struct {
+			base
+			deniable.Activable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 386:
+		return struct {
+			base
+			deniable.Punishable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 387:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 388:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 389:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 390:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 391:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 392:
+		return struct {
+			base
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 393:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 394:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 395:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 396:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 397:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 398:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 399:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 400:
+		return struct {
+			base
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 401:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 402:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 403:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 404:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 405:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 406:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 407:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 408:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 409:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 410:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 411:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 412:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 413:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 414:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 415:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 416:
+		return struct {
+			base
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 417:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 418:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 419:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 420:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 421:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 422:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 423:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 424:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 425:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 426:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 427:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 428:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 429:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 430:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 431:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 432:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 433:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 434:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 435:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 436:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 437:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 438:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 439:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 440:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 441:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 442:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 443:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 444:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 445:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 446:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 447:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 448:
+		return struct {
+			base
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter}
+	case 449:
+		return struct {
+			base
+			deniable.Activable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 450:
+		return struct {
+			base
+			deniable.Punishable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 451:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 452:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 453:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 454:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 455:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 456:
+		return struct {
+			base
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 457:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 458:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 459:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 460:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 461:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 462:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 463:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 464:
+		return struct {
+			base
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 465:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 466:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 467:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 468:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 469:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 470:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 471:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 472:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 473:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 474:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 475:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 476:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 477:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 478:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 479:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 480:
+		return struct {
+			base
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter}
+	case 481:
+		return struct {
+			base
+			deniable.Activable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 482:
+		return struct {
+			base
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 483:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 484:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 485:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 486:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 487:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 488:
+		return struct {
+			base
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 489:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 490:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 491:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 492:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 493:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 494:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 495:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 496:
+		return struct {
+			base
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter}
+	case 497:
+		return struct {
+			base
+			deniable.Activable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 498:
+		return struct {
+			base
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 499:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 500:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 501:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 502:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 503:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 504:
+		return struct {
+			base
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 505:
+		return struct {
+			base
+			deniable.Activable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 506:
+		return struct {
+			base
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 507:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 508:
+		return struct {
+			base
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 509:
+		return struct {
+			base
+			deniable.Activable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 510:
+		return struct {
+			base
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	case 511:
+		return struct {
+			base
+			deniable.Activable
+			deniable.Punishable
+			recoverable.Recoverable
+			recoverable.Expiring
+			identified.Reidentifiable
+			indexed.Indexed
+			scoped.Editable
+			staff.StaffCapable
+			superuser.SuperuserCapable
+			versioned.Versioned
+		}{adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter, adapter}
+	default:
+		panic("unknown traits combination")
+	}
+}
@@ -0,0 +1,83 @@
+// This command generates the combinations.go file in the
+// tagged package: one case per combination of the traits
+// a tagged credential may expose. It runs through go generate
+// in the tagged package. With -check, it writes nothing and
+// fails if the current file differs from the generated one.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+// The traits, in the same order of their bits in the
+// tagged package. Each trait may involve many interfaces.
+var traits = [][]string{
+	{"deniable.Activable"},
+	{"deniable.Punishable"},
+	{"recoverable.Recoverable", "recoverable.Expiring"},
+	{"identified.Reidentifiable"},
+	{"indexed.Indexed"},
+	{"scoped.Editable"},
+	{"staff.StaffCapable"},
+	{"superuser.SuperuserCapable"},
+	{"versioned.Versioned"},
+}
+
+func main() {
+	check := flag.Bool("check", false, "fail if combinations.go is not up to date, instead of writing it")
+	flag.Parse()
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintln(buffer, "// Code generated by go run ./internal/gen; DO NOT EDIT.")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "package tagged")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "import (")
+	for _, pkg := range []string{"credentials", "credentials/traits/deniable", "credentials/traits/identified",
+		"credentials/traits/indexed", "credentials/traits/recoverable", "credentials/traits/scoped",
+		"credentials/traits/staff", "credentials/traits/superuser", "credentials/traits/versioned"} {
+		fmt.Fprintf(buffer, "\t\"github.com/universe-10th/identity/%s\"\n", pkg)
+	}
+	fmt.Fprintln(buffer, ")")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "// Combines the adapter into a value exposing only the given traits.")
+	fmt.Fprintln(buffer, "func combine(adapter *Adapter, traits uint) credentials.Credential {")
+	fmt.Fprintln(buffer, "\tswitch traits {")
+	for combination := 0; combination < 1<<uint(len(traits)); combination++ {
+		fields := []string{"base"}
+		for bit, interfaces := range traits {
+			if combination&(1<<uint(bit)) != 0 {
+				fields = append(fields, interfaces...)
+			}
+		}
+		values := strings.TrimSuffix(strings.Repeat("adapter, ", len(fields)), ", ")
+		fmt.Fprintf(buffer, "\tcase %d:\n", combination)
+		fmt.Fprintf(buffer, "\t\treturn struct {\n\t\t\t%s\n\t\t}{%s}\n", strings.Join(fields, "\n\t\t\t"), values)
+	}
+	fmt.Fprintln(buffer, "\tdefault:")
+	fmt.Fprintln(buffer, "\t\tpanic(\"unknown traits combination\")")
+	fmt.Fprintln(buffer, "\t}")
+	fmt.Fprintln(buffer, "}")
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		if current, err := os.ReadFile("combinations.go"); err != nil {
+			log.Fatal(err)
+		} else if !bytes.Equal(current, source) {
+			log.Fatal("combinations.go is not up to date: run go generate")
+		}
+		return
+	}
+	if err := os.WriteFile("combinations.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,403 @@
+package tagged
+
+//go:generate go run ./internal/gen
+
+import (
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/restorable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/hashing"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// The trait bits, in the same order of the traits in the
+// internal generator.
+const (
+	activableTrait uint = 1 << iota
+	punishableTrait
+	recoverableTrait
+	identifiedTrait
+	indexedTrait
+	scopedTrait
+	staffTrait
+	superuserTrait
+	versionedTrait
+)
+
+// The supported tags (the value of the `identity:"..."` struct
+// tag) and the traits they enable. Punishments require both the
+// punished_on and punished_for fields, and recovery requires both
+// the recovery_token and recovery_expiration fields.
+const (
+	PasswordTag           = "password"
+	ActiveTag             = "active"
+	RecoveryTokenTag      = "recovery_token"
+	RecoveryExpirationTag = "recovery_expiration"
+	IdentifierTag         = "identifier"
+	IndexTag              = "index"
+	ScopesTag             = "scopes"
+	StaffTag              = "staff"
+	SuperuserTag          = "superuser"
+	PunishedOnTag         = "punished_on"
+	PunishedForTag        = "punished_for"
+	PunishmentReasonTag   = "punishment_reason"
+	PunishedByTag         = "punished_by"
+	VersionTag            = "version"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var credentialType = reflect.TypeOf((*credentials.Credential)(nil)).Elem()
+
+// The expected field type for each tag. A nil type stands for
+// any type, and the credentialType stands for any type that
+// can be assigned a credential.
+var tagTypes = map[string]reflect.Type{
+	PasswordTag:           reflect.TypeOf(""),
+	ActiveTag:             reflect.TypeOf(false),
+	RecoveryTokenTag:      reflect.TypeOf(""),
+	RecoveryExpirationTag: timeType,
+	IdentifierTag:         nil,
+	IndexTag:              nil,
+	ScopesTag:             reflect.TypeOf(map[string]scoped.Scope(nil)),
+	StaffTag:              reflect.TypeOf(false),
+	SuperuserTag:          reflect.TypeOf(false),
+	PunishedOnTag:         reflect.TypeOf((*time.Time)(nil)),
+	PunishedForTag:        reflect.TypeOf((*time.Duration)(nil)),
+	PunishmentReasonTag:   nil,
+	PunishedByTag:         credentialType,
+	VersionTag:            reflect.TypeOf(uint64(0)),
+}
+
+// Returned when wrapping something that is not a non-nil
+// pointer to a struct.
+var ErrNotStructPointer = errors.New("the target must be a non-nil pointer to a struct")
+
+// Returned when wrapping with no hasher, being the target not
+// a hasher provider (i.e. it has no Hasher() method).
+var ErrNoHasher = errors.New("no hasher was given, and the target does not provide one")
+
+// Returned when wrapping a struct with no password field.
+var ErrNoPasswordField = errors.New("the target has no field tagged as password")
+
+// Returned when wrapping a struct with only one of the
+// recovery_token and recovery_expiration fields.
+var ErrIncompleteRecovery = errors.New("recovery requires both the recovery_token and recovery_expiration fields")
+
+// Returned when wrapping a struct with only one of the
+// punished_on and punished_for fields, or punishment_reason
+// or punished_by fields without them.
+var ErrIncompletePunishment = errors.New("punishment requires both the punished_on and punished_for fields")
+
+// Returned when wrapping a struct with a field tag that is
+// invalid, duplicate, on an unexported field, or on a field
+// of an unexpected type.
+type FieldError struct {
+	Field  string
+	Tag    string
+	Reason string
+}
+
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("field %s tagged as %q: %s", err.Field, err.Tag, err.Reason)
+}
+
+// The parsed fields (by tag) of a struct type, and the
+// traits they enable.
+type layout struct {
+	fields map[string][]int
+	traits uint
+}
+
+var layouts sync.Map
+
+func parse(structType reflect.Type) (*layout, error) {
+	if cached, ok := layouts.Load(structType); ok {
+		return cached.(*layout), nil
+	}
+
+	result := &layout{fields: map[string][]int{}}
+	for _, field := range reflect.VisibleFields(structType) {
+		tag, ok := field.Tag.Lookup("identity")
+		if !ok {
+			continue
+		}
+		expected, known := tagTypes[tag]
+		switch {
+		case !known:
+			return nil, &FieldError{field.Name, tag, "unknown tag"}
+		case !field.IsExported():
+			return nil, &FieldError{field.Name, tag, "the field is not exported"}
+		case result.fields[tag] != nil:
+			return nil, &FieldError{field.Name, tag, "duplicate tag"}
+		case expected == credentialType && !credentialType.AssignableTo(field.Type):
+			return nil, &FieldError{field.Name, tag, "the field cannot hold a credential"}
+		case expected != nil && expected != credentialType && expected != field.Type:
+			return nil, &FieldError{field.Name, tag, "the field must be of type " + expected.String()}
+		}
+		result.fields[tag] = field.Index
+	}
+
+	has := func(tag string) bool {
+		return result.fields[tag] != nil
+	}
+	if !has(PasswordTag) {
+		return nil, ErrNoPasswordField
+	}
+	if has(RecoveryTokenTag) != has(RecoveryExpirationTag) {
+		return nil, ErrIncompleteRecovery
+	}
+	if has(PunishedOnTag) != has(PunishedForTag) || (has(PunishmentReasonTag) || has(PunishedByTag)) && !has(PunishedOnTag) {
+		return nil, ErrIncompletePunishment
+	}
+	for tag, trait := range map[string]uint{
+		ActiveTag: activableTrait, PunishedOnTag: punishableTrait, RecoveryTokenTag: recoverableTrait,
+		IdentifierTag: identifiedTrait, IndexTag: indexedTrait, ScopesTag: scopedTrait, StaffTag: staffTrait,
+		SuperuserTag: superuserTrait, VersionTag: versionedTrait,
+	} {
+		if has(tag) {
+			result.traits |= trait
+		}
+	}
+
+	cached, _ := layouts.LoadOrStore(structType, result)
+	return cached.(*layout), nil
+}
+
+// The base interface all the wrapped credentials expose.
+type base interface {
+	credentials.Credential
+	restorable.Restorable
+	Unwrap() interface{}
+}
+
+// An adapter implements every trait by reading and writing the
+// tagged fields of a struct. It is never returned directly: it
+// is combined into a value exposing only the traits enabled by
+// the fields present in the struct.
+type Adapter struct {
+	target interface{}
+	value  reflect.Value
+	layout *layout
+	hasher hashing.HashingEngine
+}
+
+// Wraps a pointer to a struct with `identity:"..."` field tags
+// into a credential exposing exactly the traits whose fields
+// are present. The hasher may be nil if the struct provides a
+// Hasher() method on its own.
+func Wrap(target interface{}, hasher hashing.HashingEngine) (credentials.Credential, error) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotStructPointer
+	}
+	if hasher == nil {
+		if provider, ok := target.(interface{ Hasher() hashing.HashingEngine }); !ok {
+			return nil, ErrNoHasher
+		} else {
+			hasher = provider.Hasher()
+		}
+	}
+
+	if parsed, err := parse(value.Elem().Type()); err != nil {
+		return nil, err
+	} else {
+		return combine(&Adapter{target, value.Elem(), parsed, hasher}, parsed.traits), nil
+	}
+}
+
+// Like Wrap, but panics on error.
+func MustWrap(target interface{}, hasher hashing.HashingEngine) credentials.Credential {
+	if credential, err := Wrap(target, hasher); err != nil {
+		panic(err)
+	} else {
+		return credential
+	}
+}
+
+// Returns the pointer to the struct wrapped by a credential
+// created by Wrap, or nil for other credentials.
+func Unwrap(credential credentials.Credential) interface{} {
+	if wrapped, ok := credential.(base); ok {
+		return wrapped.Unwrap()
+	} else {
+		return nil
+	}
+}
+
+func (adapter *Adapter) field(tag string) reflect.Value {
+	return adapter.value.FieldByIndex(adapter.layout.fields[tag])
+}
+
+func (adapter *Adapter) has(tag string) bool {
+	return adapter.layout.fields[tag] != nil
+}
+
+// Returns the wrapped pointer to struct.
+func (adapter *Adapter) Unwrap() interface{} {
+	return adapter.target
+}
+
+// Returns a copy of the wrapped struct.
+func (adapter *Adapter) Snapshot() interface{} {
+	copied := reflect.New(adapter.value.Type()).Elem()
+	copied.Set(adapter.value)
+	return copied
+}
+
+// Restores the wrapped struct from a copy made by Snapshot.
+func (adapter *Adapter) Restore(snapshot interface{}) {
+	adapter.value.Set(snapshot.(reflect.Value))
+}
+
+// Returns the password field.
+func (adapter *Adapter) HashedPassword() string {
+	return adapter.field(PasswordTag).String()
+}
+
+// Sets the password field.
+func (adapter *Adapter) SetHashedPassword(password string) {
+	adapter.field(PasswordTag).SetString(password)
+}
+
+// Returns the hasher given on wrap, or provided by the struct.
+func (adapter *Adapter) Hasher() hashing.HashingEngine {
+	return adapter.hasher
+}
+
+// Returns the active field.
+func (adapter *Adapter) Active() bool {
+	return adapter.field(ActiveTag).Bool()
+}
+
+// Sets the active field.
+func (adapter *Adapter) SetActive(active bool) {
+	adapter.field(ActiveTag).SetBool(active)
+}
+
+// Returns the punished_on, punished_for, punishment_reason
+// and punished_by fields (the latter two may be absent).
+func (adapter *Adapter) PunishedFor() (punishedOn *time.Time, forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	punishedOn = adapter.field(PunishedOnTag).Interface().(*time.Time)
+	forTime = adapter.field(PunishedForTag).Interface().(*time.Duration)
+	if adapter.has(PunishmentReasonTag) {
+		if field := adapter.field(PunishmentReasonTag); !field.IsZero() {
+			reason = field.Interface()
+		}
+	}
+	if adapter.has(PunishedByTag) {
+		if field := adapter.field(PunishedByTag); !field.IsZero() {
+			by, _ = field.Interface().(credentials.Credential)
+		}
+	}
+	return
+}
+
+func setOrClear(field reflect.Value, value interface{}) {
+	if value == nil || !reflect.TypeOf(value).AssignableTo(field.Type()) {
+		field.Set(reflect.Zero(field.Type()))
+	} else {
+		field.Set(reflect.ValueOf(value))
+	}
+}
+
+// Sets the punishment fields. A permanent punishment is given
+// by a nil duration. Reasons not assignable to the reason field
+// are discarded.
+func (adapter *Adapter) Punish(forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	now := time.Now()
+	adapter.field(PunishedOnTag).Set(reflect.ValueOf(&now))
+	if forTime == nil {
+		adapter.field(PunishedForTag).Set(reflect.Zero(adapter.field(PunishedForTag).Type()))
+	} else {
+		duration := *forTime
+		adapter.field(PunishedForTag).Set(reflect.ValueOf(&duration))
+	}
+	if adapter.has(PunishmentReasonTag) {
+		setOrClear(adapter.field(PunishmentReasonTag), reason)
+	}
+	if adapter.has(PunishedByTag) {
+		setOrClear(adapter.field(PunishedByTag), by)
+	}
+}
+
+// Clears the punishment fields.
+func (adapter *Adapter) Unpunish() {
+	for _, tag := range []string{PunishedOnTag, PunishedForTag, PunishmentReasonTag, PunishedByTag} {
+		if adapter.has(tag) {
+			field := adapter.field(tag)
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// Sets the recovery_token field, and the recovery_expiration
+// field to now + duration.
+func (adapter *Adapter) SetRecoveryToken(token string, duration time.Duration) {
+	adapter.field(RecoveryTokenTag).SetString(token)
+	adapter.field(RecoveryExpirationTag).Set(reflect.ValueOf(time.Now().Add(duration)))
+}
+
+// Returns the recovery_token field, clearing it first if it
+// expired.
+func (adapter *Adapter) RecoveryToken() string {
+	token := adapter.field(RecoveryTokenTag)
+	if token.String() != "" && adapter.RecoveryTokenExpiration().Before(time.Now()) {
+		token.SetString("")
+	}
+	return token.String()
+}
+
+// Returns the recovery_expiration field.
+func (adapter *Adapter) RecoveryTokenExpiration() time.Time {
+	return adapter.field(RecoveryExpirationTag).Interface().(time.Time)
+}
+
+// Returns the identifier field.
+func (adapter *Adapter) Identification() interface{} {
+	return adapter.field(IdentifierTag).Interface()
+}
+
+// Sets the identifier field. Identifications not assignable
+// to the identifier field are discarded (the field is cleared).
+func (adapter *Adapter) SetIdentification(identification interface{}) {
+	setOrClear(adapter.field(IdentifierTag), identification)
+}
+
+// Returns the index field.
+func (adapter *Adapter) Index() interface{} {
+	return adapter.field(IndexTag).Interface()
+}
+
+// Returns the scopes field.
+func (adapter *Adapter) Scopes() map[string]scoped.Scope {
+	return adapter.field(ScopesTag).Interface().(map[string]scoped.Scope)
+}
+
+// Sets the scopes field.
+func (adapter *Adapter) SetScopes(scopes map[string]scoped.Scope) {
+	adapter.field(ScopesTag).Set(reflect.ValueOf(scopes))
+}
+
+// Returns the staff field.
+func (adapter *Adapter) Staff() bool {
+	return adapter.field(StaffTag).Bool()
+}
+
+// Returns the superuser field.
+func (adapter *Adapter) Superuser() bool {
+	return adapter.field(SuperuserTag).Bool()
+}
+
+// Returns the version field.
+func (adapter *Adapter) Version() uint64 {
+	return adapter.field(VersionTag).Uint()
+}
+
+// Sets the version field.
+func (adapter *Adapter) SetVersion(version uint64) {
+	adapter.field(VersionTag).SetUint(version)
+}
@@ -26,3 +26,12 @@ type Activable interface {
 	Active() bool
 	SetActive(bool)
 }
+
+// This trait allows to count the consecutive failed logins of
+// a credential, and to tell when the last one happened, so it
+// may be locked out after too many of them. A zero count means
+// there are no failures since the last successful login.
+type FailureCounting interface {
+	FailedLogins() (count int, last time.Time)
+	SetFailedLogins(count int, last time.Time)
+}
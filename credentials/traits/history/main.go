@@ -0,0 +1,38 @@
+package history
+
+import "time"
+
+// The outcomes of the login attempts.
+const (
+	Succeeded  = "succeeded"
+	Failed     = "failed"
+	LockedOut  = "locked_out"
+	Challenged = "challenged"
+)
+
+// A login attempt, as kept in the history of a credential:
+// when it happened, from where, and its outcome (Challenged
+// when a second factor was required).
+type Entry struct {
+	Time      time.Time
+	ClientIP  string
+	UserAgent string
+	DeviceID  string
+	Outcome   string
+}
+
+// This trait allows a credential to keep the time of its last
+// successful and failed logins (zero if none), and a bounded
+// history of its recent login attempts, the newest first.
+type Tracked interface {
+	LastLogin() time.Time
+	LastFailedLogin() time.Time
+	LoginHistory() []Entry
+	SetLoginHistory(lastLogin, lastFailedLogin time.Time, entries []Entry)
+}
+
+// Tells whether a credential did not login successfully since
+// the given time (including the ones that never did).
+func InactiveSince(credential Tracked, since time.Time) bool {
+	return credential.LastLogin().Before(since)
+}
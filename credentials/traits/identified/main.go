@@ -7,3 +7,12 @@ package identified
 type Identified interface {
 	Identification() interface{}
 }
+
+// This trait complements the Identified one by
+// allowing the identification to be replaced
+// (e.g. by a tombstone, when anonymizing). The
+// index, if any, must not change.
+type Reidentifiable interface {
+	Identified
+	SetIdentification(identification interface{})
+}
@@ -0,0 +1,40 @@
+package otp
+
+import "time"
+
+// This trait allows a credential to hold a TOTP (RFC 6238)
+// secret. The secret is set, but not enrolled, when the
+// enrollment begins, and becomes enrolled when the user
+// confirms it with a valid code. A nil secret means TOTP
+// is not set up. The last step is the time step of the
+// last accepted code, so accepted codes cannot be replayed.
+// Notes: the secret is needed to compute the codes, so it
+// cannot be hashed at rest.
+type TOTPCapable interface {
+	TOTPSecret() (secret []byte, enrolled bool)
+	SetTOTPSecret(secret []byte, enrolled bool)
+	TOTPLastStep() int64
+	SetTOTPLastStep(step int64)
+}
+
+// This trait allows a credential to hold one-time recovery
+// codes, to be used when the other second factors are not
+// available. The codes are hashed through the credential's
+// Hasher(), and each code is removed once it is used.
+type RecoveryCodesCapable interface {
+	RecoveryCodes() []string
+	SetRecoveryCodes(hashedCodes []string)
+}
+
+// This trait allows a credential to hold a pending one-time
+// passcode, delivered out of band (e.g. by email or SMS). The
+// code is hashed through the credential's Hasher(), expires at
+// the given time, and keeps the count of failed attempts. An
+// empty hashed code means there is no pending passcode. The
+// passcodes are only sent to enrolled credentials.
+type PasscodeCapable interface {
+	PasscodeEnrolled() bool
+	SetPasscodeEnrolled(enrolled bool)
+	Passcode() (hashedCode string, expiresAt time.Time, attempts int)
+	SetPasscode(hashedCode string, expiresAt time.Time, attempts int)
+}
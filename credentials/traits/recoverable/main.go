@@ -16,3 +16,12 @@ type Recoverable interface {
 	SetRecoveryToken(token string, duration time.Duration)
 	RecoveryToken() string
 }
+
+// This trait complements the Recoverable one by
+// telling when the current recovery token expires
+// (the result is meaningless if there is no token).
+// It allows a token to be restored exactly, e.g.
+// when rolling back a failed change.
+type Expiring interface {
+	RecoveryTokenExpiration() time.Time
+}
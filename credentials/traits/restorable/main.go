@@ -0,0 +1,12 @@
+package restorable
+
+// This trait allows a credential to take a snapshot of its
+// whole state, and restore it later (e.g. when a realm rolls
+// back a failed change). Snapshots are opaque: they are only
+// given back to the Restore method of the same credential.
+// Credentials not implementing it are restored as a whole if
+// they are pointers to structs, or through their other traits.
+type Restorable interface {
+	Snapshot() interface{}
+	Restore(snapshot interface{})
+}
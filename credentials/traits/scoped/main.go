@@ -20,3 +20,12 @@ type Scope interface {
 type Scoped interface {
 	Scopes() map[string]Scope
 }
+
+// This trait makes the scopes of a scoped credential
+// editable, so realms may grant and revoke them. The
+// realms always set a new map, instead of changing the
+// current one.
+type Editable interface {
+	Scoped
+	SetScopes(scopes map[string]Scope)
+}
@@ -0,0 +1,169 @@
+// Package traittest provides contract tests for the credential
+// traits. Credential implementors (either hand-written or from
+// generated code) may run them in their own tests.
+package traittest
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/restorable"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"testing"
+	"time"
+)
+
+// Tolerance to use when comparing times set by the traits
+// against the current time.
+const tolerance = time.Minute
+
+// Runs the contract tests for the Credential interface and
+// for every settable trait (Activable, Punishable, Recoverable
+// with or without Expiring, Versioned and Restorable) implemented
+// by the
+// credentials created by the factory. A new credential is used
+// for each trait.
+func Run(t *testing.T, factory func() credentials.Credential) {
+	t.Run("Credential", func(t *testing.T) {
+		Credential(t, factory())
+	})
+	if _, ok := factory().(deniable.Activable); ok {
+		t.Run("Activable", func(t *testing.T) {
+			Activable(t, factory().(deniable.Activable))
+		})
+	}
+	if _, ok := factory().(deniable.Punishable); ok {
+		t.Run("Punishable", func(t *testing.T) {
+			Punishable(t, factory().(deniable.Punishable))
+		})
+	}
+	if _, ok := factory().(recoverable.Recoverable); ok {
+		t.Run("Recoverable", func(t *testing.T) {
+			Recoverable(t, factory().(recoverable.Recoverable))
+		})
+	}
+	if _, ok := factory().(versioned.Versioned); ok {
+		t.Run("Versioned", func(t *testing.T) {
+			Versioned(t, factory().(versioned.Versioned))
+		})
+	}
+	if _, ok := factory().(restorable.Restorable); ok {
+		t.Run("Restorable", func(t *testing.T) {
+			Restorable(t, factory())
+		})
+	}
+}
+
+// Checks the contract of the hashed password.
+func Credential(t *testing.T, credential credentials.Credential) {
+	credential.SetHashedPassword("hashed")
+	if hashed := credential.HashedPassword(); hashed != "hashed" {
+		t.Errorf("HashedPassword must return the value given to SetHashedPassword. Returned: %q\n", hashed)
+	}
+	credential.SetHashedPassword("")
+	if hashed := credential.HashedPassword(); hashed != "" {
+		t.Errorf("HashedPassword must return an empty string after clearing it. Returned: %q\n", hashed)
+	}
+	if credential.Hasher() == nil {
+		t.Error("Hasher must not return nil")
+	}
+}
+
+// Checks the contract of the Activable trait.
+func Activable(t *testing.T, credential deniable.Activable) {
+	for _, active := range []bool{true, false, true} {
+		credential.SetActive(active)
+		if credential.Active() != active {
+			t.Errorf("Active must return the value given to SetActive (%v)\n", active)
+		}
+	}
+}
+
+// Checks the contract of the Punishable trait.
+func Punishable(t *testing.T, credential deniable.Punishable) {
+	duration := time.Hour
+	credential.Punish(&duration, "reason", nil)
+	punishedOn, punishedFor, reason, _ := credential.PunishedFor()
+	if punishedOn == nil || time.Since(*punishedOn) > tolerance || time.Until(*punishedOn) > tolerance {
+		t.Errorf("A punished credential must be punished on the current time. Punished on: %v\n", punishedOn)
+	}
+	if punishedFor == nil || *punishedFor != time.Hour {
+		t.Errorf("A punished credential must be punished for the given duration. Punished for: %v\n", punishedFor)
+	}
+	if reason != nil && reason != "reason" {
+		t.Errorf("The punishment reason, if kept, must be the given one. Reason: %v\n", reason)
+	}
+	duration = 2 * time.Hour
+	if _, punishedFor, _, _ = credential.PunishedFor(); punishedFor == nil || *punishedFor != time.Hour {
+		t.Error("The punishment duration must be copied, not referenced")
+	}
+
+	credential.Punish(nil, nil, nil)
+	if punishedOn, punishedFor, _, _ = credential.PunishedFor(); punishedOn == nil || punishedFor != nil {
+		t.Errorf("A permanent punishment must have a nil duration. Punished on: %v, for: %v\n", punishedOn, punishedFor)
+	}
+
+	credential.Unpunish()
+	if punishedOn, punishedFor, reason, by := credential.PunishedFor(); punishedOn != nil || punishedFor != nil ||
+		reason != nil || by != nil {
+		t.Errorf("An unpunished credential must have no punishment data. Got: %v, %v, %v, %v\n",
+			punishedOn, punishedFor, reason, by)
+	}
+}
+
+// Checks the contract of the Recoverable trait (and also the
+// Expiring trait, if implemented).
+func Recoverable(t *testing.T, credential recoverable.Recoverable) {
+	credential.SetRecoveryToken("token", time.Hour)
+	if token := credential.RecoveryToken(); token != "token" {
+		t.Errorf("RecoveryToken must return a non-expired token. Returned: %q\n", token)
+	}
+	if expiring, ok := credential.(recoverable.Expiring); ok {
+		if delta := time.Until(expiring.RecoveryTokenExpiration()) - time.Hour; delta > tolerance || delta < -tolerance {
+			t.Errorf("RecoveryTokenExpiration must be the current time plus the duration. Returned: %v\n",
+				expiring.RecoveryTokenExpiration())
+		}
+	}
+
+	credential.SetRecoveryToken("token", -time.Hour)
+	if token := credential.RecoveryToken(); token != "" {
+		t.Errorf("RecoveryToken must return an empty string for an expired token. Returned: %q\n", token)
+	}
+	credential.SetRecoveryToken("", time.Hour)
+	if token := credential.RecoveryToken(); token != "" {
+		t.Errorf("RecoveryToken must return an empty string after clearing it. Returned: %q\n", token)
+	}
+}
+
+// Checks the contract of the Versioned trait.
+func Versioned(t *testing.T, credential versioned.Versioned) {
+	for _, version := range []uint64{1, 2, 0} {
+		credential.SetVersion(version)
+		if credential.Version() != version {
+			t.Errorf("Version must return the value given to SetVersion (%d)\n", version)
+		}
+	}
+}
+
+// Checks the contract of the Restorable trait, for the hashed
+// password and (if implemented) the active flag.
+func Restorable(t *testing.T, credential credentials.Credential) {
+	restorableCred := credential.(restorable.Restorable)
+	activable, isActivable := credential.(deniable.Activable)
+	credential.SetHashedPassword("hashed")
+	if isActivable {
+		activable.SetActive(true)
+	}
+	snapshot := restorableCred.Snapshot()
+	credential.SetHashedPassword("changed")
+	if isActivable {
+		activable.SetActive(false)
+	}
+	restorableCred.Restore(snapshot)
+	if hashed := credential.HashedPassword(); hashed != "hashed" {
+		t.Errorf("Restore must restore the hashed password of the snapshot. Returned: %q\n", hashed)
+	}
+	if isActivable && !activable.Active() {
+		t.Error("Restore must restore the active flag of the snapshot")
+	}
+}
@@ -0,0 +1,14 @@
+package versioned
+
+// This trait allows optimistic concurrency when
+// saving credentials. By contract, brokers saving
+// a versioned credential must compare its version
+// against the stored one and, if they differ, fail
+// with credentials.ErrConcurrentModification (not
+// saving anything). Otherwise, they must store the
+// credential with the next version and then update
+// it in the credential by calling SetVersion.
+type Versioned interface {
+	Version() uint64
+	SetVersion(uint64)
+}
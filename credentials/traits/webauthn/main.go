@@ -0,0 +1,18 @@
+package webauthn
+
+// A public key credential (e.g. a passkey or a security key)
+// registered via WebAuthn: its ID, its public key in the COSE
+// format, and the last signature counter reported by the
+// authenticator.
+type PublicKeyCredential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+}
+
+// This trait allows a credential to hold the WebAuthn public
+// key credentials registered by the user.
+type WebAuthnCapable interface {
+	WebAuthnCredentials() []PublicKeyCredential
+	SetWebAuthnCredentials(credentials []PublicKeyCredential)
+}
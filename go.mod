@@ -1,3 +1,3 @@
 module github.com/universe-10th/identity
 
-go 1.12
+go 1.18
@@ -0,0 +1,217 @@
+package instrumentation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The default latency histogram buckets.
+var DefaultBuckets = []time.Duration{
+	100 * time.Microsecond, time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 5 * time.Second,
+}
+
+// The kinds of instrumented components.
+const (
+	BrokerComponent  = "broker"
+	HashingComponent = "hashing"
+)
+
+// The label each component uses for the instance name in the
+// Prometheus output.
+var componentLabels = map[string]string{
+	BrokerComponent:  "broker",
+	HashingComponent: "engine",
+}
+
+type key struct {
+	component string
+	name      string
+	operation string
+}
+
+type metric struct {
+	calls   uint64
+	errors  uint64
+	sum     time.Duration
+	buckets []uint64
+}
+
+// A registry keeps the call counts, error counts and latency
+// histograms of the instrumented operations, per component
+// (brokers or hashing engines), instance name and operation.
+// It can be published via expvar (it is an expvar.Var) and
+// served in the Prometheus text format (it is an http.Handler).
+type Registry struct {
+	mutex   sync.Mutex
+	buckets []time.Duration
+	metrics map[key]*metric
+}
+
+// Creates a new registry. The latency buckets must be sorted in
+// ascending order (DefaultBuckets will be used if none is given).
+func NewRegistry(buckets ...time.Duration) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	return &Registry{buckets: buckets, metrics: map[key]*metric{}}
+}
+
+// Records a call of an operation, with its latency and whether
+// it failed.
+func (registry *Registry) Observe(component, name, operation string, latency time.Duration, failed bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	k := key{component, name, operation}
+	m, ok := registry.metrics[k]
+	if !ok {
+		m = &metric{buckets: make([]uint64, len(registry.buckets))}
+		registry.metrics[k] = m
+	}
+	m.calls++
+	if failed {
+		m.errors++
+	}
+	m.sum += latency
+	for index, bound := range registry.buckets {
+		if latency <= bound {
+			m.buckets[index]++
+		}
+	}
+}
+
+// Returns the call and error counts of an operation.
+func (registry *Registry) Counts(component, name, operation string) (calls, errors uint64) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if m, ok := registry.metrics[key{component, name, operation}]; ok {
+		return m.calls, m.errors
+	} else {
+		return 0, 0
+	}
+}
+
+func (registry *Registry) sortedKeys() []key {
+	keys := make([]key, 0, len(registry.metrics))
+	for k := range registry.metrics {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.component != b.component {
+			return a.component < b.component
+		} else if a.name != b.name {
+			return a.name < b.name
+		} else {
+			return a.operation < b.operation
+		}
+	})
+	return keys
+}
+
+func seconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'g', -1, 64)
+}
+
+// Renders the metrics as JSON, so the registry can be published
+// via expvar.Publish.
+func (registry *Registry) String() string {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	type histogram struct {
+		Count      uint64            `json:"count"`
+		SumSeconds float64           `json:"sum_seconds"`
+		Buckets    map[string]uint64 `json:"buckets"`
+	}
+	type operation struct {
+		Calls   uint64    `json:"calls"`
+		Errors  uint64    `json:"errors"`
+		Latency histogram `json:"latency"`
+	}
+	result := map[string]map[string]map[string]operation{}
+	for k, m := range registry.metrics {
+		if result[k.component] == nil {
+			result[k.component] = map[string]map[string]operation{}
+		}
+		if result[k.component][k.name] == nil {
+			result[k.component][k.name] = map[string]operation{}
+		}
+		buckets := map[string]uint64{}
+		for index, bound := range registry.buckets {
+			buckets[seconds(bound)] = m.buckets[index]
+		}
+		result[k.component][k.name][k.operation] = operation{
+			m.calls, m.errors, histogram{m.calls, m.sum.Seconds(), buckets},
+		}
+	}
+	data, _ := json.Marshal(result)
+	return string(data)
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Renders the metrics in the Prometheus text format.
+func (registry *Registry) WritePrometheus(writer io.Writer) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	keys := registry.sortedKeys()
+	for _, component := range []string{BrokerComponent, HashingComponent} {
+		prefix := "identity_" + component
+		type family struct {
+			suffix, kind, help string
+		}
+		for _, f := range []family{
+			{"_calls_total", "counter", "Total calls per operation."},
+			{"_errors_total", "counter", "Total failed calls per operation."},
+			{"_duration_seconds", "histogram", "Latency of the calls per operation."},
+		} {
+			header := false
+			for _, k := range keys {
+				if k.component != component {
+					continue
+				}
+				if !header {
+					fmt.Fprintf(writer, "# HELP %s%s %s\n# TYPE %s%s %s\n", prefix, f.suffix, f.help, prefix, f.suffix, f.kind)
+					header = true
+				}
+				m := registry.metrics[k]
+				labels := fmt.Sprintf(`%s="%s",operation="%s"`, componentLabels[component], escape(k.name), escape(k.operation))
+				switch f.kind {
+				case "counter":
+					value := m.calls
+					if f.suffix == "_errors_total" {
+						value = m.errors
+					}
+					fmt.Fprintf(writer, "%s%s{%s} %d\n", prefix, f.suffix, labels, value)
+				default:
+					for index, bound := range registry.buckets {
+						fmt.Fprintf(writer, "%s%s_bucket{%s,le=\"%s\"} %d\n", prefix, f.suffix, labels, seconds(bound), m.buckets[index])
+					}
+					fmt.Fprintf(writer, "%s%s_bucket{%s,le=\"+Inf\"} %d\n", prefix, f.suffix, labels, m.calls)
+					fmt.Fprintf(writer, "%s%s_sum{%s} %s\n", prefix, f.suffix, labels, seconds(m.sum))
+					fmt.Fprintf(writer, "%s%s_count{%s} %d\n", prefix, f.suffix, labels, m.calls)
+				}
+			}
+		}
+	}
+}
+
+// Serves the metrics in the Prometheus text format.
+func (registry *Registry) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	registry.WritePrometheus(writer)
+}
@@ -0,0 +1,128 @@
+package instrumentation
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/hashing"
+	"time"
+)
+
+// Panicked when wrapping with a nil registry.
+var ErrNilRegistry = errors.New("the given registry is nil")
+
+// A broker decorator recording the calls, errors and latencies
+// of each operation in a registry, under a given name.
+type Broker struct {
+	credentials.Broker
+	registry *Registry
+	name     string
+}
+
+// Wraps a broker to instrument it. Panics if the broker or the
+// registry are nil.
+func WrapBroker(registry *Registry, name string, broker credentials.Broker) *Broker {
+	if registry == nil {
+		panic(ErrNilRegistry)
+	} else if broker == nil {
+		panic(credentials.ErrNilBroker)
+	}
+	return &Broker{broker, registry, name}
+}
+
+func (broker *Broker) observe(operation string, start time.Time, err error) {
+	broker.registry.Observe(BrokerComponent, broker.name, operation, time.Since(start), err != nil)
+}
+
+// Instruments the underlying ByIdentifier call.
+func (broker *Broker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	start := time.Now()
+	credential, err := broker.Broker.ByIdentifier(identifier, template)
+	broker.observe("ByIdentifier", start, err)
+	return credential, err
+}
+
+// Instruments the underlying ByIndex call.
+func (broker *Broker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	start := time.Now()
+	credential, err := broker.Broker.ByIndex(index, template)
+	broker.observe("ByIndex", start, err)
+	return credential, err
+}
+
+// Instruments the underlying Save call.
+func (broker *Broker) Save(credential credentials.Credential) error {
+	start := time.Now()
+	err := broker.Broker.Save(credential)
+	broker.observe("Save", start, err)
+	return err
+}
+
+// Instruments the underlying List call.
+func (broker *Broker) List(template credentials.Credential, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	start := time.Now()
+	list, next, err := credentials.List(broker.Broker, template, filter, cursor, limit)
+	broker.observe("List", start, err)
+	return list, next, err
+}
+
+// Instruments the underlying Create call.
+func (broker *Broker) Create(credential credentials.Credential) error {
+	start := time.Now()
+	err := credentials.Create(broker.Broker, credential)
+	broker.observe("Create", start, err)
+	return err
+}
+
+// Instruments the underlying Delete call.
+func (broker *Broker) Delete(credential credentials.Credential) error {
+	start := time.Now()
+	err := credentials.Delete(broker.Broker, credential)
+	broker.observe("Delete", start, err)
+	return err
+}
+
+// A hashing engine decorator recording the calls, errors (and
+// failed validations) and latencies of each operation in a
+// registry. It keeps the name of the underlying engine.
+type HashingEngine struct {
+	engine   hashing.HashingEngine
+	registry *Registry
+	label    string
+}
+
+// Wraps a hashing engine to instrument it, under the given label
+// (or the engine's name, if empty). Panics if the engine or the
+// registry are nil. Notes: wrapped multiple hashing engines must
+// not be registered inside other multiple hashing engines.
+func WrapHashingEngine(registry *Registry, label string, engine hashing.HashingEngine) *HashingEngine {
+	if registry == nil {
+		panic(ErrNilRegistry)
+	} else if engine == nil {
+		panic(hashing.ErrNilHasher)
+	}
+	if label == "" {
+		label = engine.Name()
+	}
+	return &HashingEngine{engine, registry, label}
+}
+
+// Returns the name of the underlying engine.
+func (engine *HashingEngine) Name() string {
+	return engine.engine.Name()
+}
+
+// Instruments the underlying Hash call.
+func (engine *HashingEngine) Hash(password string) (string, error) {
+	start := time.Now()
+	hashed, err := engine.engine.Hash(password)
+	engine.registry.Observe(HashingComponent, engine.label, "Hash", time.Since(start), err != nil)
+	return hashed, err
+}
+
+// Instruments the underlying Validate call.
+func (engine *HashingEngine) Validate(password string, hash string) error {
+	start := time.Now()
+	err := engine.engine.Validate(password, hash)
+	engine.registry.Observe(HashingComponent, engine.label, "Validate", time.Since(start), err != nil)
+	return err
+}
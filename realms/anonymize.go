@@ -0,0 +1,137 @@
+package realms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms/events"
+	"time"
+)
+
+// Error to return when attempting to anonymize a credential
+// whose identification cannot be replaced.
+var ErrNotAnonymizable = errors.New("the credential is not a reidentifiable type")
+
+// The names of the erased data, as told in erasure records.
+const (
+	ErasedPassword       = "password"
+	ErasedRecoveryToken  = "recovery_token"
+	ErasedActive         = "active"
+	ErasedPunishment     = "punishment"
+	ErasedFailedLogins   = "failed_logins"
+	ErasedTOTP           = "totp"
+	ErasedRecoveryCodes  = "recovery_codes"
+	ErasedPasscode       = "passcode"
+	ErasedWebAuthn       = "webauthn"
+	ErasedLoginHistory   = "login_history"
+	ErasedIdentification = "identification"
+)
+
+// An erasure record tells what was erased from a credential,
+// and when. It never holds the erased values: only the kept
+// index (nil if the credential is not indexed), and the new
+// identification (the tombstone).
+type ErasureRecord struct {
+	Index     interface{}
+	Tombstone interface{}
+	Erased    []string
+	At        time.Time
+}
+
+// Creates a random tombstone, used when none is given.
+func newTombstone() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	} else {
+		return "anonymized-" + hex.EncodeToString(buffer), nil
+	}
+}
+
+// Irreversibly scrubs a credential: unsets its password, clears
+// its recovery token, deactivates it, clears its punishment, its
+// failed logins, its second factors (TOTP secret, recovery codes
+// and pending passcode), its WebAuthn credentials and its login
+// history, and replaces its identifier with a tombstone (a random
+// one, if the given one is nil). The index is kept, so references
+// to this credential remain valid. This call is only allowed if
+// the credential is reidentifiable. On success, a record of what
+// was erased is returned (and published as an event).
+func (realm *TypedRealm[T]) Anonymize(credential T, tombstone interface{}) (*ErasureRecord, error) {
+	if _, ok := credentials.Credential(credential).(identified.Reidentifiable); !ok {
+		return nil, ErrNotAnonymizable
+	}
+	if tombstone == nil {
+		if random, err := newTombstone(); err != nil {
+			return nil, err
+		} else {
+			tombstone = random
+		}
+	}
+
+	record := &ErasureRecord{Tombstone: tombstone}
+	err := realm.mutateAndPublish(credential, func(current T) error {
+		record.Erased = []string{ErasedPassword}
+		current.SetHashedPassword("")
+		if recoverableCred, ok := credentials.Credential(current).(recoverable.Recoverable); ok {
+			recoverableCred.SetRecoveryToken("", time.Duration(0))
+			record.Erased = append(record.Erased, ErasedRecoveryToken)
+		}
+		if activable, ok := credentials.Credential(current).(deniable.Activable); ok {
+			activable.SetActive(false)
+			record.Erased = append(record.Erased, ErasedActive)
+		}
+		if punishable, ok := credentials.Credential(current).(deniable.Punishable); ok {
+			punishable.Unpunish()
+			record.Erased = append(record.Erased, ErasedPunishment)
+		}
+		if counting, ok := credentials.Credential(current).(deniable.FailureCounting); ok {
+			counting.SetFailedLogins(0, time.Time{})
+			record.Erased = append(record.Erased, ErasedFailedLogins)
+		}
+		if totpCapable, ok := credentials.Credential(current).(otp.TOTPCapable); ok {
+			totpCapable.SetTOTPSecret(nil, false)
+			totpCapable.SetTOTPLastStep(0)
+			record.Erased = append(record.Erased, ErasedTOTP)
+		}
+		if codesCapable, ok := credentials.Credential(current).(otp.RecoveryCodesCapable); ok {
+			codesCapable.SetRecoveryCodes(nil)
+			record.Erased = append(record.Erased, ErasedRecoveryCodes)
+		}
+		if passcodeCapable, ok := credentials.Credential(current).(otp.PasscodeCapable); ok {
+			passcodeCapable.SetPasscodeEnrolled(false)
+			passcodeCapable.SetPasscode("", time.Time{}, 0)
+			record.Erased = append(record.Erased, ErasedPasscode)
+		}
+		if webAuthnCapable, ok := credentials.Credential(current).(webauthn.WebAuthnCapable); ok {
+			webAuthnCapable.SetWebAuthnCredentials(nil)
+			record.Erased = append(record.Erased, ErasedWebAuthn)
+		}
+		if tracked, ok := credentials.Credential(current).(history.Tracked); ok {
+			tracked.SetLoginHistory(time.Time{}, time.Time{}, nil)
+			record.Erased = append(record.Erased, ErasedLoginHistory)
+		}
+		credentials.Credential(current).(identified.Reidentifiable).SetIdentification(tombstone)
+		record.Erased = append(record.Erased, ErasedIdentification)
+		if indexedCred, ok := credentials.Credential(current).(indexed.Indexed); ok {
+			record.Index = indexedCred.Index()
+		}
+		record.At = time.Now()
+		return nil
+	}, func(saved T) events.Event {
+		return &events.Anonymized{Time: record.At, Credential: saved, Index: record.Index, Tombstone: record.Tombstone,
+			Erased: append([]string(nil), record.Erased...)}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
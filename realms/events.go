@@ -0,0 +1,49 @@
+package realms
+
+import (
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login"
+)
+
+// Subscribes a handler to the events of the realm (see the
+// events package), which is invoked synchronously on each of
+// them: wrap it in an events.Dispatcher to handle the events
+// asynchronously. Returns the function that unsubscribes it.
+func (realm *TypedRealm[T]) Subscribe(handler events.Handler) func() {
+	return realm.bus.Subscribe(handler)
+}
+
+// Applies a mutation (see mutate) and, if it succeeds, publishes
+// the event made for the saved credential (if any).
+func (realm *TypedRealm[T]) mutateAndPublish(credential T, mutation func(T) error, event func(T) events.Event) error {
+	saved := credential
+	if err := realm.mutate(credential, func(current T) error {
+		saved = current
+		return mutation(current)
+	}); err != nil {
+		return err
+	}
+	if made := event(saved); made != nil {
+		realm.bus.Publish(made)
+	}
+	return nil
+}
+
+// Makes the copy of an attempt to publish in the login events,
+// so the subscribers never see the attempted password. Its
+// metadata map is also copied (not its values), so subscribers
+// cannot change the caller's one.
+func publishedAttempt(attempt *login.Attempt) *login.Attempt {
+	if attempt == nil {
+		return nil
+	}
+	published := *attempt
+	published.Password = ""
+	if attempt.Metadata != nil {
+		published.Metadata = make(map[string]interface{}, len(attempt.Metadata))
+		for key, value := range attempt.Metadata {
+			published.Metadata[key] = value
+		}
+	}
+	return &published
+}
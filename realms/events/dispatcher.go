@@ -0,0 +1,71 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A dispatcher delivers the events to a handler asynchronously,
+// in a separate goroutine and in publication order. Events are
+// buffered, and dropped (and counted) when the buffer is full,
+// so publishing never blocks. Its Handle method is the handler
+// to subscribe.
+type Dispatcher struct {
+	handler Handler
+	queue   chan Event
+	done    chan struct{}
+	mutex   sync.RWMutex
+	closed  bool
+	dropped uint64
+}
+
+// Creates a new dispatcher, buffering up to the given number
+// of events, and starts its goroutine.
+func NewDispatcher(handler Handler, buffer int) *Dispatcher {
+	if handler == nil {
+		panic(ErrNilHandler)
+	}
+	dispatcher := &Dispatcher{handler: handler, queue: make(chan Event, buffer), done: make(chan struct{})}
+	go dispatcher.run()
+	return dispatcher
+}
+
+func (dispatcher *Dispatcher) run() {
+	defer close(dispatcher.done)
+	for event := range dispatcher.queue {
+		dispatcher.handler(event)
+	}
+}
+
+// Queues an event, or drops it if the buffer is full or the
+// dispatcher is closed.
+func (dispatcher *Dispatcher) Handle(event Event) {
+	dispatcher.mutex.RLock()
+	defer dispatcher.mutex.RUnlock()
+	if dispatcher.closed {
+		atomic.AddUint64(&dispatcher.dropped, 1)
+		return
+	}
+	select {
+	case dispatcher.queue <- event:
+	default:
+		atomic.AddUint64(&dispatcher.dropped, 1)
+	}
+}
+
+// Returns how many events were dropped.
+func (dispatcher *Dispatcher) Dropped() uint64 {
+	return atomic.LoadUint64(&dispatcher.dropped)
+}
+
+// Stops accepting events, and waits until the queued ones
+// are delivered.
+func (dispatcher *Dispatcher) Close() {
+	dispatcher.mutex.Lock()
+	if !dispatcher.closed {
+		dispatcher.closed = true
+		close(dispatcher.queue)
+	}
+	dispatcher.mutex.Unlock()
+	<-dispatcher.done
+}
@@ -0,0 +1,292 @@
+package events
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/realms/login"
+	"sync"
+	"time"
+)
+
+// Panicked when a nil handler is subscribed or dispatched to.
+var ErrNilHandler = errors.New("event handler is nil")
+
+// An event tells something that happened in a realm. Handlers
+// tell the actual event by its type (e.g. *LoginSucceeded).
+type Event interface {
+	At() time.Time
+}
+
+// Published when a login succeeds (after the second factor,
+// if required). For logins completing a second factor, the
+// attempt is the one that started the login. The attempt is a
+// copy of the one given to the realm, without the password.
+type LoginSucceeded struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Attempt    *login.Attempt
+}
+
+func (event *LoginSucceeded) At() time.Time {
+	return event.Time
+}
+
+// Published when a login fails. The credential is nil if it
+// was not found, and the step is the pipeline step rejecting
+// the login (nil if it failed elsewhere, e.g. on lookup, rate
+// limiting, lockout or second factor). The reason is the error
+// returned by the login. Like in LoginSucceeded, the attempt
+// is a copy without the password.
+type LoginFailed struct {
+	Time       time.Time
+	Identifier interface{}
+	Credential credentials.Credential
+	Attempt    *login.Attempt
+	Step       login.PipelineStep
+	Reason     error
+}
+
+func (event *LoginFailed) At() time.Time {
+	return event.Time
+}
+
+// Published when the password of a credential is set. ByUser
+// tells whether the current password was given (ChangePassword).
+// Otherwise, the actor (SetPassword's one) may be nil.
+type PasswordChanged struct {
+	Time       time.Time
+	Credential credentials.Credential
+	ByUser     bool
+	By         credentials.Credential
+}
+
+func (event *PasswordChanged) At() time.Time {
+	return event.Time
+}
+
+// Published when the password of a credential is unset. The
+// actor may be nil.
+type PasswordUnset struct {
+	Time       time.Time
+	Credential credentials.Credential
+	By         credentials.Credential
+}
+
+func (event *PasswordUnset) At() time.Time {
+	return event.Time
+}
+
+// Published when a password reset is prepared. The token is not
+// published, since every subscriber would see it: only the caller
+// of PreparePasswordReset has it (e.g. to send it by email). The
+// actor may be nil.
+type ResetPrepared struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Duration   time.Duration
+	By         credentials.Credential
+}
+
+func (event *ResetPrepared) At() time.Time {
+	return event.Time
+}
+
+// Published when a password reset is confirmed.
+type ResetConfirmed struct {
+	Time       time.Time
+	Credential credentials.Credential
+}
+
+func (event *ResetConfirmed) At() time.Time {
+	return event.Time
+}
+
+// Published when a password reset is cancelled. The actor may
+// be nil.
+type ResetCancelled struct {
+	Time       time.Time
+	Credential credentials.Credential
+	By         credentials.Credential
+}
+
+func (event *ResetCancelled) At() time.Time {
+	return event.Time
+}
+
+// Published when a credential is punished. The duration is
+// nil for permanent punishments, and the punisher may be nil.
+type Punished struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Duration   *time.Duration
+	Reason     interface{}
+	By         credentials.Credential
+}
+
+func (event *Punished) At() time.Time {
+	return event.Time
+}
+
+// Published when the punishment of a credential is cleared.
+// The actor may be nil.
+type Unpunished struct {
+	Time       time.Time
+	Credential credentials.Credential
+	By         credentials.Credential
+}
+
+func (event *Unpunished) At() time.Time {
+	return event.Time
+}
+
+// Published when a credential is activated or deactivated.
+// The actor may be nil.
+type ActivationChanged struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Active     bool
+	By         credentials.Credential
+}
+
+func (event *ActivationChanged) At() time.Time {
+	return event.Time
+}
+
+// Published when a credential is anonymized. It tells the kept
+// index, the tombstone replacing the identification, and the
+// names of the erased data (see realms.ErasureRecord), but not
+// the erased values.
+type Anonymized struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Index      interface{}
+	Tombstone  interface{}
+	Erased     []string
+}
+
+func (event *Anonymized) At() time.Time {
+	return event.Time
+}
+
+// Published when a credential confirms its TOTP enrollment.
+type TOTPEnrolled struct {
+	Time       time.Time
+	Credential credentials.Credential
+}
+
+func (event *TOTPEnrolled) At() time.Time {
+	return event.Time
+}
+
+// Published when the TOTP secret of a credential is removed.
+// The actor may be nil.
+type TOTPDisabled struct {
+	Time       time.Time
+	Credential credentials.Credential
+	By         credentials.Credential
+}
+
+func (event *TOTPDisabled) At() time.Time {
+	return event.Time
+}
+
+// Published when new recovery codes are generated for a
+// credential. It tells how many, but never the codes.
+type RecoveryCodesGenerated struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Count      int
+}
+
+func (event *RecoveryCodesGenerated) At() time.Time {
+	return event.Time
+}
+
+// Published when a WebAuthn public key credential (e.g. a
+// passkey) is registered for a credential. The ID is the one
+// of the registered public key credential.
+type WebAuthnRegistered struct {
+	Time       time.Time
+	Credential credentials.Credential
+	ID         []byte
+}
+
+func (event *WebAuthnRegistered) At() time.Time {
+	return event.Time
+}
+
+// Published when a scope is granted to a credential. The
+// granter may be nil.
+type ScopeGranted struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Scope      string
+	By         credentials.Credential
+}
+
+func (event *ScopeGranted) At() time.Time {
+	return event.Time
+}
+
+// Published when a scope is revoked from a credential. The
+// revoker may be nil.
+type ScopeRevoked struct {
+	Time       time.Time
+	Credential credentials.Credential
+	Scope      string
+	By         credentials.Credential
+}
+
+func (event *ScopeRevoked) At() time.Time {
+	return event.Time
+}
+
+// A handler receives the published events.
+type Handler func(event Event)
+
+type subscription struct {
+	id      uint64
+	handler Handler
+}
+
+// A bus publishes the events to the subscribed handlers,
+// synchronously and in subscription order. The zero value
+// is ready to use.
+type Bus struct {
+	mutex         sync.RWMutex
+	nextID        uint64
+	subscriptions []subscription
+}
+
+// Subscribes a handler. Returns the function that
+// unsubscribes it.
+func (bus *Bus) Subscribe(handler Handler) func() {
+	if handler == nil {
+		panic(ErrNilHandler)
+	}
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	bus.nextID++
+	id := bus.nextID
+	bus.subscriptions = append(bus.subscriptions, subscription{id, handler})
+	return func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+		for index, current := range bus.subscriptions {
+			if current.id == id {
+				bus.subscriptions = append(bus.subscriptions[:index:index], bus.subscriptions[index+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publishes an event to the subscribed handlers.
+func (bus *Bus) Publish(event Event) {
+	bus.mutex.RLock()
+	subscriptions := bus.subscriptions
+	bus.mutex.RUnlock()
+	for _, current := range subscriptions {
+		current.handler(event)
+	}
+}
@@ -0,0 +1,203 @@
+package filelock
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Returned error when a lock could not be acquired before
+// the timeout.
+var ErrLockTimeout = errors.New("timed out while waiting for the lock")
+
+// Panicked when creating a locker with an empty directory.
+var ErrNoDirectory = errors.New("the lock directory is empty")
+
+// The default interval to check again for a held lock.
+const DefaultPollInterval = 10 * time.Millisecond
+
+// Options to create a file locker. A zero Timeout waits forever,
+// and a zero StaleAfter never breaks held locks (otherwise, locks
+// not refreshed for longer than that are considered abandoned,
+// e.g. by a crashed process, and removed). Held locks are refreshed
+// every StaleAfter / 3, so every locker sharing a directory must
+// use the same StaleAfter.
+type Options struct {
+	Timeout      time.Duration
+	PollInterval time.Duration
+	StaleAfter   time.Duration
+}
+
+// A locker implementing realms.Locker via lock directories in a
+// directory, so it works across processes sharing it. Each lock
+// is a directory holding a single file, named by a random owner
+// token. It is prepared aside and moved into place, which fails
+// while the lock is held. Releasing or breaking a lock removes
+// the owner's file first (which only one process can do, and
+// only while that owner holds the lock), so an owner whose lock
+// was broken as stale does not remove the lock of the next owner.
+// A lock directory with no owner file is free.
+type Locker struct {
+	directory string
+	options   Options
+}
+
+// Creates a new file locker on the given directory, which is
+// created if it does not exist.
+func New(directory string, options Options) *Locker {
+	if directory == "" {
+		panic(ErrNoDirectory)
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	return &Locker{directory, options}
+}
+
+func (locker *Locker) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(locker.directory, hex.EncodeToString(sum[:])+".lock")
+}
+
+// Generates a random owner token for a lock.
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// Removes the lock directory if it is held by the given owner.
+// Removing the owner's file fails if the lock was released or
+// broken already, so the directory (which may belong to a new
+// owner by then) is kept. Removing the directory itself only
+// succeeds while it is empty.
+func release(path, owner string) {
+	if err := os.Remove(filepath.Join(path, owner)); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// Tells the owner of a lock directory, and when it was last
+// refreshed. An empty owner means the lock is free.
+func readOwner(path string) (string, time.Time, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) == 0 {
+		return "", time.Time{}, err
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return entries[0].Name(), info.ModTime(), nil
+}
+
+// Prepares a lock directory owned by the given token, aside of
+// the lock path, to be moved into it.
+func prepare(path, token string) (string, error) {
+	aside := path + "." + token + ".tmp"
+	if err := os.Mkdir(aside, 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(aside, token), nil, 0600); err != nil {
+		_ = os.RemoveAll(aside)
+		return "", err
+	}
+	return aside, nil
+}
+
+// Refreshes the owner file of a held lock until stopped, so it
+// is not broken as stale while the mutation takes long.
+func (locker *Locker) heartbeat(path, token string, stop chan struct{}) {
+	ticker := time.NewTicker(locker.options.StaleAfter / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if err := os.Chtimes(filepath.Join(path, token), now, now); err != nil {
+				// The lock was broken: there is nothing to refresh.
+				return
+			}
+		}
+	}
+}
+
+// Locks the given key, waiting until its lock directory can be
+// moved into place. Fails with ErrLockTimeout if the timeout
+// elapses, or with the underlying error if the directory cannot
+// be prepared for any other reason.
+func (locker *Locker) Lock(key string) (func(), error) {
+	if err := os.MkdirAll(locker.directory, 0700); err != nil {
+		return nil, err
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	path := locker.path(key)
+	aside, err := prepare(path, token)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	for {
+		// The owner file is refreshed first, so the lock is not
+		// taken as stale after waiting for it.
+		now := time.Now()
+		moveErr := os.Chtimes(filepath.Join(aside, token), now, now)
+		if moveErr == nil {
+			moveErr = os.Rename(aside, path)
+		}
+		if moveErr == nil {
+			stop := make(chan struct{})
+			if locker.options.StaleAfter > 0 {
+				go locker.heartbeat(path, token, stop)
+			}
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					close(stop)
+					release(path, token)
+				})
+			}, nil
+		}
+
+		if owner, refreshed, err := readOwner(path); err == nil && owner == "" {
+			// A lock directory left by a release (e.g. one being
+			// made, or interrupted), which is free to remove.
+			_ = os.Remove(path)
+			continue
+		} else if err == nil && locker.options.StaleAfter > 0 && time.Since(refreshed) > locker.options.StaleAfter {
+			// The stale owner is identified by its token, so the
+			// lock is only broken if it was not released and taken
+			// again since it was checked.
+			release(path, owner)
+			continue
+		} else if os.IsNotExist(err) {
+			// The lock was just released, unless the failure was
+			// about the prepared directory.
+			if _, err := os.Stat(aside); err != nil {
+				return nil, moveErr
+			}
+			continue
+		} else if err != nil {
+			_ = os.RemoveAll(aside)
+			return nil, err
+		}
+		if locker.options.Timeout > 0 && time.Since(start) >= locker.options.Timeout {
+			_ = os.RemoveAll(aside)
+			return nil, ErrLockTimeout
+		}
+		time.Sleep(locker.options.PollInterval)
+	}
+}
@@ -0,0 +1,77 @@
+package realms
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/realms/login"
+	"time"
+)
+
+// The default number of login attempts kept in the history
+// of the credentials.
+const DefaultLoginHistorySize = 10
+
+// Sets how many login attempts are kept in the history of the
+// credentials implementing the history.Tracked trait (by default:
+// DefaultLoginHistorySize).
+func (realm *TypedRealm[T]) SetLoginHistorySize(size int) {
+	realm.history = size
+}
+
+// The number of login attempts kept in the history.
+func (realm *TypedRealm[T]) historySize() int {
+	if realm.history <= 0 {
+		return DefaultLoginHistorySize
+	}
+	return realm.history
+}
+
+// Adds a login attempt to the history of a credential, updating
+// its last (successful or failed) login time, and saves it.
+func (realm *TypedRealm[T]) trackLogin(credential T, attempt *login.Attempt, outcome string) error {
+	if _, ok := credentials.Credential(credential).(history.Tracked); !ok {
+		return nil
+	}
+	entry := history.Entry{Time: time.Now(), Outcome: outcome}
+	if attempt != nil {
+		entry.Time, entry.UserAgent, entry.DeviceID = attempt.Time, attempt.UserAgent, attempt.DeviceID
+		if attempt.ClientIP != nil {
+			entry.ClientIP = attempt.ClientIP.String()
+		}
+	}
+	return realm.mutate(credential, func(current T) error {
+		tracked := credentials.Credential(current).(history.Tracked)
+		lastLogin, lastFailedLogin := tracked.LastLogin(), tracked.LastFailedLogin()
+		switch outcome {
+		case history.Succeeded:
+			lastLogin = entry.Time
+		case history.Failed, history.LockedOut:
+			lastFailedLogin = entry.Time
+		}
+		entries := append([]history.Entry{entry}, tracked.LoginHistory()...)
+		if len(entries) > realm.historySize() {
+			entries = entries[:realm.historySize()]
+		}
+		tracked.SetLoginHistory(lastLogin, lastFailedLogin, entries)
+		return nil
+	})
+}
+
+// Lists a page of credentials matching a filter (see List), and
+// returns the ones implementing the history.Tracked trait that
+// did not login successfully since the given time (including the
+// ones that never did). Pages may then hold fewer credentials
+// than the limit, or none.
+func (realm *TypedRealm[T]) ListInactive(since time.Time, filter credentials.Filter, cursor string, limit int) ([]T, string, error) {
+	page, next, err := realm.source.List(filter, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	var result []T
+	for _, credential := range page {
+		if tracked, ok := credentials.Credential(credential).(history.Tracked); ok && history.InactiveSince(tracked, since) {
+			result = append(result, credential)
+		}
+	}
+	return result, next, nil
+}
@@ -0,0 +1,77 @@
+package realms
+
+import (
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"sync"
+)
+
+// A locker provides mutual exclusion per key. Lock blocks
+// until the key is acquired (or fails), and returns the
+// function that releases it. Implementations may work only
+// in-process (like MemoryLocker) or across processes (like
+// the ones in the realms/filelock package).
+type Locker interface {
+	Lock(key string) (unlock func(), err error)
+}
+
+type memoryLock struct {
+	mutex sync.Mutex
+	users int
+}
+
+// An in-process locker, keeping one mutex per key only
+// while it is being used.
+type MemoryLocker struct {
+	mutex sync.Mutex
+	locks map[string]*memoryLock
+}
+
+// Creates a new in-process locker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: map[string]*memoryLock{}}
+}
+
+// Locks the given key, blocking until it is released by
+// other goroutines. It never fails.
+func (locker *MemoryLocker) Lock(key string) (func(), error) {
+	locker.mutex.Lock()
+	lock, ok := locker.locks[key]
+	if !ok {
+		lock = &memoryLock{}
+		locker.locks[key] = lock
+	}
+	lock.users++
+	locker.mutex.Unlock()
+
+	lock.mutex.Lock()
+	return func() {
+		lock.mutex.Unlock()
+		locker.mutex.Lock()
+		defer locker.mutex.Unlock()
+		if lock.users--; lock.users == 0 {
+			delete(locker.locks, key)
+		}
+	}, nil
+}
+
+// Returns the key to lock a credential by, which involves
+// its type and its index. Non-indexed credentials are not
+// locked.
+func lockKey(credential credentials.Credential) (string, bool) {
+	if indexedCred, ok := credential.(indexed.Indexed); !ok {
+		return "", false
+	} else {
+		return fmt.Sprintf("%T/%v", credential, indexedCred.Index()), true
+	}
+}
+
+// Sets a locker to serialize the mutations of each credential
+// (keyed like "*pkg.User/1", by its type and index, so it must
+// implement the Indexed trait). The lock is held during the whole
+// mutation, including the retries. By default (or when setting
+// nil), no lock is used.
+func (realm *TypedRealm[T]) SetLocker(locker Locker) {
+	realm.locker = locker
+}
@@ -0,0 +1,155 @@
+package realms
+
+import (
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/lockout"
+	"sync"
+	"time"
+)
+
+// Sets the lockout policy of the realm, which locks credentials
+// out after too many consecutive failed logins (see lockout.Policy).
+// Rejections of pipeline steps implementing login.CountedStep (e.g.
+// password.PasswordCheckingStep) count as failures of credentials
+// implementing deniable.FailureCounting. Other rejections (e.g. of
+// inactive credentials) count like the failures of the unknown
+// identifiers, which are only counted if the realm's credential
+// type implements deniable.FailureCounting: so locked out logins
+// fail with a *lockout.LockedOutError for existing and unknown
+// identifiers alike. By default, no lockout is done.
+func (realm *TypedRealm[T]) SetLockout(policy *lockout.Policy) {
+	realm.lockout = policy
+}
+
+// Tells whether a credential is locked out: by a lockout
+// punishment (when the policy punishes), or by its count
+// of failed logins.
+func (realm *TypedRealm[T]) lockedOut(credential T, now time.Time) (*lockout.LockedOutError, bool) {
+	if punishable, ok := credentials.Credential(credential).(deniable.Punishable); ok && realm.lockout.Punish {
+		if punishedOn, forTime, reason, _ := punishable.PunishedFor(); punishedOn != nil && forTime != nil && reason == realm.lockout.Reason {
+			if until := punishedOn.Add(*forTime); now.Before(until) {
+				return &lockout.LockedOutError{Until: until, RetryAfter: until.Sub(now)}, true
+			}
+		}
+		return nil, false
+	} else if counting, ok := credentials.Credential(credential).(deniable.FailureCounting); ok {
+		count, last := counting.FailedLogins()
+		return realm.lockout.Check(count, last, now)
+	}
+	return nil, false
+}
+
+// Tells whether a credential has an active punishment for
+// another reason than the lockout one (e.g. a ban).
+func (realm *TypedRealm[T]) otherwisePunished(punishable deniable.Punishable, now time.Time) bool {
+	punishedOn, forTime, reason, _ := punishable.PunishedFor()
+	if punishedOn == nil || reason == realm.lockout.Reason {
+		return false
+	}
+	return forTime == nil || now.Before(punishedOn.Add(*forTime))
+}
+
+// Counts a failed login of a credential, saving it. Returns
+// the lockout error if the credential got locked out, and
+// punishes it when the policy says so (publishing it), unless
+// it has an active punishment for another reason, which is
+// kept instead.
+func (realm *TypedRealm[T]) countFailure(credential T, now time.Time) (*lockout.LockedOutError, error) {
+	if _, ok := credentials.Credential(credential).(deniable.FailureCounting); !ok {
+		return nil, nil
+	}
+	var lockedErr *lockout.LockedOutError
+	var punishment *time.Duration
+	err := realm.mutateAndPublish(credential, func(current T) error {
+		punishment = nil
+		counting := credentials.Credential(current).(deniable.FailureCounting)
+		count, _ := counting.FailedLogins()
+		counting.SetFailedLogins(count+1, now)
+		lockedErr, _ = realm.lockout.Check(count+1, now, now)
+		if punishable, ok := credentials.Credential(current).(deniable.Punishable); ok && lockedErr != nil && realm.lockout.Punish {
+			if !realm.otherwisePunished(punishable, now) {
+				duration := lockedErr.RetryAfter
+				punishable.Punish(&duration, realm.lockout.Reason, nil)
+				punishment = &duration
+			}
+		}
+		return nil
+	}, func(saved T) events.Event {
+		if punishment == nil {
+			return nil
+		}
+		return &events.Punished{Time: time.Now(), Credential: saved, Duration: punishment, Reason: realm.lockout.Reason}
+	})
+	return lockedErr, err
+}
+
+// Resets the count of failed logins of a credential, if any,
+// saving it.
+func (realm *TypedRealm[T]) resetFailures(credential T) error {
+	if counting, ok := credentials.Credential(credential).(deniable.FailureCounting); !ok {
+		return nil
+	} else if count, _ := counting.FailedLogins(); count == 0 {
+		return nil
+	}
+	return realm.mutate(credential, func(current T) error {
+		credentials.Credential(current).(deniable.FailureCounting).SetFailedLogins(0, time.Time{})
+		return nil
+	})
+}
+
+// Tells whether an unknown identifier (or one rejected by a step
+// not counting failures) is locked out, counting the failure
+// otherwise. Nothing is counted if the credentials of the realm
+// do not count failures, since those are never locked out.
+func (realm *TypedRealm[T]) countPhantomFailure(identifier interface{}, now time.Time) (*lockout.LockedOutError, bool) {
+	if _, ok := credentials.Credential(realm.source.Dummy()).(deniable.FailureCounting); !ok {
+		return nil, false
+	}
+	key := fmt.Sprintf("%v", identifier)
+	if lockedErr, locked := realm.lockout.CheckPhantom(key, now); locked {
+		return lockedErr, true
+	}
+	return realm.lockout.FailPhantom(key, now)
+}
+
+// The average time taken to record the failed logins of existing
+// credentials (i.e. saving their failures and login history). The
+// failures of unknown identifiers take that long too, so they are
+// not told apart by their timing.
+type failureCost struct {
+	mutex   sync.Mutex
+	average time.Duration
+}
+
+// Records the time taken since the given start to record the
+// failed login of a credential, if it saves failures at all.
+func (cost *failureCost) observe(credential credentials.Credential, started time.Time) {
+	_, counting := credential.(deniable.FailureCounting)
+	_, tracked := credential.(history.Tracked)
+	if !counting && !tracked {
+		return
+	}
+	elapsed := time.Since(started)
+	cost.mutex.Lock()
+	defer cost.mutex.Unlock()
+	if cost.average == 0 {
+		cost.average = elapsed
+	} else {
+		cost.average += (elapsed - cost.average) / 8
+	}
+}
+
+// Waits, since the given start, for the average time taken to
+// record the failed logins of existing credentials.
+func (cost *failureCost) pad(started time.Time) {
+	cost.mutex.Lock()
+	average := cost.average
+	cost.mutex.Unlock()
+	if remaining := average - time.Since(started); remaining > 0 {
+		time.Sleep(remaining)
+	}
+}
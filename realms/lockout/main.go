@@ -0,0 +1,157 @@
+package lockout
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The default number of consecutive failed logins that
+// locks a credential out.
+const DefaultThreshold = 5
+
+// The default duration of the first lockout.
+const DefaultBaseDuration = time.Minute
+
+// The default maximum duration of a lockout.
+const DefaultMaxDuration = 24 * time.Hour
+
+// The default maximum number of unknown identifiers whose
+// failed logins are tracked.
+const DefaultMaxPhantoms = 10000
+
+// The default reason of the lockout punishments.
+const TooManyFailures = "too many failed logins"
+
+// Panicked when a policy is created with a non-positive
+// threshold or base duration.
+var ErrBadPolicy = errors.New("lockout threshold and base duration must be positive")
+
+// Returned by a realm when a credential is locked out after
+// too many failed logins. The login may be attempted again
+// after RetryAfter (i.e. on Until).
+type LockedOutError struct {
+	Until      time.Time
+	RetryAfter time.Duration
+}
+
+func (error *LockedOutError) Error() string {
+	return fmt.Sprintf("too many failed logins, retry after: %s", error.RetryAfter.Round(time.Second))
+}
+
+// A lockout policy tells when a credential is locked out: every
+// Threshold consecutive failed logins, the credential is locked
+// out for a duration that starts on BaseDuration and doubles on
+// each lockout (up to MaxDuration). The failures are counted in
+// credentials implementing the deniable.FailureCounting trait,
+// and reset on a successful login. When Punish is true, the
+// lockouts are also applied as punishments (with Reason) to the
+// credentials implementing the deniable.Punishable trait.
+//
+// To not tell whether an identifier exists, the failed logins
+// of unknown identifiers are tracked in memory (up to MaxPhantoms
+// of them, forgetting the least recently failed one to make room)
+// and locked out the same way.
+type Policy struct {
+	Threshold    int
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+	Punish       bool
+	Reason       interface{}
+	MaxPhantoms  int
+
+	mutex    sync.Mutex
+	phantoms map[string]*list.Element
+	// The tracked phantoms, the most recently failed first.
+	order *list.List
+}
+
+type phantom struct {
+	key   string
+	count int
+	last  time.Time
+}
+
+// Creates a new lockout policy, with DefaultMaxDuration,
+// DefaultMaxPhantoms and the TooManyFailures reason.
+func NewPolicy(threshold int, baseDuration time.Duration) *Policy {
+	if threshold <= 0 || baseDuration <= 0 {
+		panic(ErrBadPolicy)
+	}
+	return &Policy{
+		Threshold: threshold, BaseDuration: baseDuration, MaxDuration: DefaultMaxDuration,
+		Reason: TooManyFailures, MaxPhantoms: DefaultMaxPhantoms,
+	}
+}
+
+// Returns the duration of the given lockout (starting at 1).
+func (policy *Policy) Duration(lockout int) time.Duration {
+	duration := policy.BaseDuration
+	for index := 1; index < lockout && (policy.MaxDuration <= 0 || duration < policy.MaxDuration); index++ {
+		duration *= 2
+	}
+	if policy.MaxDuration > 0 && duration > policy.MaxDuration {
+		duration = policy.MaxDuration
+	}
+	return duration
+}
+
+// Tells whether the given count of consecutive failures, the
+// last of them happening on the given time, causes a lockout.
+// Returns the error to report until the lockout ends.
+func (policy *Policy) Check(count int, last time.Time, now time.Time) (*LockedOutError, bool) {
+	if count < policy.Threshold || count%policy.Threshold != 0 {
+		return nil, false
+	}
+	until := last.Add(policy.Duration(count / policy.Threshold))
+	if !now.Before(until) {
+		return nil, false
+	}
+	return &LockedOutError{Until: until, RetryAfter: until.Sub(now)}, true
+}
+
+// Tells whether an unknown identifier is locked out.
+func (policy *Policy) CheckPhantom(key string, now time.Time) (*LockedOutError, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if element, ok := policy.phantoms[key]; ok {
+		current := element.Value.(*phantom)
+		return policy.Check(current.count, current.last, now)
+	}
+	return nil, false
+}
+
+// Records a failed login of an unknown identifier, and tells
+// whether it is locked out because of it. When there are too
+// many identifiers being tracked, the least recently failed one
+// is forgotten to make room, so the counts are never skipped.
+func (policy *Policy) FailPhantom(key string, now time.Time) (*LockedOutError, bool) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if policy.phantoms == nil {
+		policy.phantoms = map[string]*list.Element{}
+		policy.order = list.New()
+	}
+	element, ok := policy.phantoms[key]
+	if ok {
+		policy.order.MoveToFront(element)
+	} else {
+		maxPhantoms := policy.MaxPhantoms
+		if maxPhantoms <= 0 {
+			maxPhantoms = DefaultMaxPhantoms
+		}
+		for policy.order.Len() >= maxPhantoms {
+			oldest := policy.order.Back()
+			policy.order.Remove(oldest)
+			delete(policy.phantoms, oldest.Value.(*phantom).key)
+		}
+		element = policy.order.PushFront(&phantom{key: key})
+		policy.phantoms[key] = element
+	}
+	current := element.Value.(*phantom)
+	current.count++
+	current.last = now
+	return policy.Check(current.count, current.last, now)
+}
@@ -2,6 +2,8 @@ package login
 
 import (
 	"github.com/universe-10th/identity/credentials"
+	"net"
+	"time"
 )
 
 // A login pipeline step performs a check on a given
@@ -14,3 +16,64 @@ import (
 type PipelineStep interface {
 	Login(credential credentials.Credential, password string) error
 }
+
+// A login attempt describes the context of a login: who
+// attempts it, from where and when. Only the identifier,
+// the password and the time are set by the realm: the rest
+// is given by the caller (e.g. taken from the request) and
+// may be empty. Metadata holds any other value the steps
+// may need (e.g. a geolocation).
+type Attempt struct {
+	Identifier interface{}
+	Password   string
+	ClientIP   net.IP
+	UserAgent  string
+	DeviceID   string
+	Realm      string
+	Time       time.Time
+	Metadata   map[string]interface{}
+}
+
+// Creates a new login attempt for an identifier and a
+// password, happening now.
+func NewAttempt(identifier interface{}, password string) *Attempt {
+	return &Attempt{Identifier: identifier, Password: password, Time: time.Now()}
+}
+
+// An attempt pipeline step is a pipeline step that also
+// takes the context of the login attempt into account.
+// When a step implements it, the realm invokes LoginAttempt
+// instead of Login. The Login method is still needed, for
+// the places taking plain pipeline steps: usually, it runs
+// LoginAttempt with NewAttempt(nil, password).
+type AttemptStep interface {
+	PipelineStep
+	LoginAttempt(credential credentials.Credential, attempt *Attempt) error
+}
+
+// A counted pipeline step is a pipeline step whose rejections
+// count as failed login attempts (e.g. a wrong password), so
+// realms may lock credentials out after too many of them.
+type CountedStep interface {
+	PipelineStep
+	CountsFailures() bool
+}
+
+// Adapts a pipeline step to an attempt pipeline step: plain
+// steps only receive the password of the attempt, while the
+// attempt steps are returned as they are.
+func Adapt(step PipelineStep) AttemptStep {
+	if attemptStep, ok := step.(AttemptStep); ok {
+		return attemptStep
+	} else {
+		return adapter{step}
+	}
+}
+
+type adapter struct {
+	PipelineStep
+}
+
+func (adapter adapter) LoginAttempt(credential credentials.Credential, attempt *Attempt) error {
+	return adapter.Login(credential, attempt.Password)
+}
@@ -25,3 +25,8 @@ func (PasswordCheckingStep) Login(credential credentials.Credential, password st
 		return nil
 	}
 }
+
+// Rejections of this step count as failed login attempts.
+func (PasswordCheckingStep) CountsFailures() bool {
+	return true
+}
@@ -3,8 +3,16 @@ package realms
 import (
 	"errors"
 	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
 	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/lockout"
 	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/ratelimit"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"sync"
 	"time"
 )
 
@@ -24,130 +32,445 @@ var ErrNotRecoverable = errors.New("the credential is not a recoverable type")
 // password reset attempt.
 var ErrBadToken = errors.New("invalid token on password reset confirm, or password reset was not issued")
 
+// Error to return when attempting to punish a credential
+// that is not punishable.
+var ErrNotPunishable = errors.New("the credential is not a punishable type")
+
+// Error to return when attempting to activate or deactivate
+// a credential that is not activable.
+var ErrNotActivable = errors.New("the credential is not an activable type")
+
 // Panicked when a nil source is given to a realm.
 var ErrNilSource = errors.New("source is nil")
 
 // Panicked when a nil pipeline step is given to a realm.
 var ErrNilPipelineStep = errors.New("pipeline step is nil")
 
-// A login realm is a class combining a full pipeline
-// and a source. It only provides one method: Login,
-// which takes the identifier and password to attempt
-// a user lookup and then the actual login process by
-// running all the elements in the pipe.
-type Realm struct {
-	source *credentials.Source
-	steps  []login.PipelineStep
+// Panicked when a nil login attempt is given to a realm.
+var ErrNilAttempt = errors.New("login attempt is nil")
+
+// A typed login realm is a class combining a full pipeline
+// and a typed source. It provides the Login method, which
+// takes the identifier and password to attempt a user lookup
+// and then the actual login process by running all the
+// elements in the pipe, and the password management methods.
+// Credentials are of type T in all of them.
+type TypedRealm[T credentials.Credential] struct {
+	source  *credentials.TypedSource[T]
+	steps   []login.PipelineStep
+	retries int
+	locker  Locker
+	lockout *lockout.Policy
+	limiter *ratelimit.Limiter
+	bus     events.Bus
+	history int
+
+	factors           []twofactor.SecondFactor
+	challengeTTL      time.Duration
+	challengeAttempts int
+	challengesMutex   sync.Mutex
+	challenges        map[string]*challenge[T]
+	codeFailures      map[string]*codeFailures
+
+	failureCost failureCost
+}
+
+// A login realm is the untyped version of TypedRealm, which
+// works with any credentials.Credential.
+type Realm = TypedRealm[credentials.Credential]
+
+// Sets how many times a mutation (e.g. a password change) is
+// retried when saving fails with credentials.ErrConcurrentModification.
+// Retrying involves reloading the credential through ByIndex (so it
+// must implement the Indexed trait) and applying the mutation again
+// over the reloaded credential. By default, no retries are done.
+// Notes: on retry, the given credential is not updated (but the
+// reloaded one is), so it should be reloaded by the caller if it
+// is going to be used after the call.
+func (realm *TypedRealm[T]) SetRetries(retries int) {
+	realm.retries = retries
+}
+
+// Applies a mutation on a credential and saves it, retrying the
+// whole process on reloaded credentials on concurrent modification.
+// If the mutation or the save fail, the changes are rolled back
+// in the in-memory credential. When a locker is set, the whole
+// process is done while holding the lock of the credential.
+func (realm *TypedRealm[T]) mutate(credential T, mutation func(T) error) error {
+	if realm.locker != nil {
+		if key, ok := lockKey(credential); ok {
+			if unlock, err := realm.locker.Lock(key); err != nil {
+				return err
+			} else {
+				defer unlock()
+			}
+		}
+	}
+
+	current := credential
+	for attempt := 0; ; attempt++ {
+		previous := takeSnapshot(current)
+		err := mutation(current)
+		if err == nil {
+			err = realm.source.Save(current)
+		}
+		if err == nil {
+			return nil
+		}
+		previous.restore()
+		if err != credentials.ErrConcurrentModification || attempt >= realm.retries {
+			return err
+		}
+		if indexedCred, ok := credentials.Credential(credential).(indexed.Indexed); !ok {
+			return err
+		} else if reloaded, found, loadErr := realm.source.LookupByIndex(indexedCred.Index()); loadErr != nil {
+			return loadErr
+		} else if !found {
+			return err
+		} else {
+			current = reloaded
+		}
+	}
 }
 
 // Retrieves a credential by its identifier. This call is directly bypassed to the source.
-func (realm *Realm) ByIdentifier(identifier interface{}) (credentials.Credential, error) {
+func (realm *TypedRealm[T]) ByIdentifier(identifier interface{}) (T, error) {
 	return realm.source.ByIdentifier(identifier)
 }
 
 // Retrieves a credential by its index / key. This call is directly bypassed to the source.
-func (realm *Realm) ByIndex(index interface{}) (credentials.Credential, error) {
+func (realm *TypedRealm[T]) ByIndex(index interface{}) (T, error) {
 	return realm.source.ByIndex(index)
 }
 
+// Lists the credentials matching a filter, one page at a time. This call is directly bypassed to the source.
+func (realm *TypedRealm[T]) List(filter credentials.Filter, cursor string, limit int) ([]T, string, error) {
+	return realm.source.List(filter, cursor, limit)
+}
+
+// Runs the pipeline steps over a credential, in the context
+// of a login attempt. Also tells which step rejected it, if any.
+func (realm *TypedRealm[T]) runSteps(credential credentials.Credential, attempt *login.Attempt) (login.PipelineStep, error) {
+	for _, step := range realm.steps {
+		if err := login.Adapt(step).LoginAttempt(credential, attempt); err != nil {
+			return step, err
+		}
+	}
+	return nil, nil
+}
+
+// Runs all the pipeline steps over a credential, ignoring
+// their results.
+func (realm *TypedRealm[T]) runAllSteps(credential credentials.Credential, attempt *login.Attempt) {
+	for _, step := range realm.steps {
+		_ = login.Adapt(step).LoginAttempt(credential, attempt)
+	}
+}
+
 // Makes a full login lifecycle function. The returned
 // function takes the identification as an arbitrary
 // value, the plain-text password as a string, and
 // returns either the found and logged credential, or
 // an error. To make this function, a login source
 // must be used. A template credential is used to both
-// serve as factory and dummy.
-func (realm *Realm) Login(identifier interface{}, password string) (credentials.Credential, error) {
-	if credential, err := realm.ByIdentifier(identifier); credential == nil {
+// serve as factory and dummy. If the credential has
+// a second factor enabled, a *SecondFactorRequiredError
+// is returned instead (see CompleteLogin). This is the
+// same as LoginAttempt(login.NewAttempt(identifier, password)).
+func (realm *TypedRealm[T]) Login(identifier interface{}, password string) (T, error) {
+	return realm.LoginAttempt(login.NewAttempt(identifier, password))
+}
+
+// Like Login, but taking the whole context of the login
+// attempt (e.g. the client IP or the user agent), which
+// is given to the rate limiter and to the pipeline steps
+// implementing the login.AttemptStep interface. The time
+// of the attempt is set to now, if not given.
+func (realm *TypedRealm[T]) LoginAttempt(attempt *login.Attempt) (T, error) {
+	var zero T
+	if attempt == nil {
+		panic(ErrNilAttempt)
+	}
+	if attempt.Time.IsZero() {
+		attempt.Time = time.Now()
+	}
+
+	credential, found, step, err := realm.attemptLogin(attempt)
+	if err == nil {
+		realm.bus.Publish(&events.LoginSucceeded{Time: time.Now(), Credential: credential, Attempt: publishedAttempt(attempt)})
+		return credential, nil
+	}
+	if _, ok := err.(*SecondFactorRequiredError); !ok {
+		event := &events.LoginFailed{Time: time.Now(), Identifier: attempt.Identifier, Attempt: publishedAttempt(attempt), Step: step, Reason: err}
+		if found {
+			event.Credential = credential
+		}
+		realm.bus.Publish(event)
+	}
+	return zero, err
+}
+
+// Runs the whole login of an attempt. Returns the credential
+// (if found) even when the login fails, and the pipeline step
+// rejecting the login (if any).
+func (realm *TypedRealm[T]) attemptLogin(attempt *login.Attempt) (T, bool, login.PipelineStep, error) {
+	if realm.limiter != nil {
+		if err := realm.limiter.Allow(attempt); err != nil {
+			var zero T
+			return zero, false, nil, err
+		}
+	}
+
+	if credential, found, err := realm.source.LookupByIdentifier(attempt.Identifier); !found {
 		// These steps are dumb and intended to prevent
 		// time correlation attacks to distinguish the
 		// case of invalid password and the case of
 		// credential not being found.
-		dummy := realm.source.Dummy()
-		for _, step := range realm.steps {
-			_ = step.Login(dummy, password)
-		}
+		realm.runAllSteps(realm.source.Dummy(), attempt)
 		// When both credential and error are nil, the
-		// ErrLoginFailed will be used instead.
+		// ErrLoginFailed will be used instead. Also, the
+		// unknown identifiers are locked out like the
+		// existing ones.
 		if err == nil {
+			// When the known identifiers would be challenged
+			// (e.g. for passwordless logins), the unknown ones
+			// get a fake challenge instead.
+			if fakeErr, faked := realm.fakeChallenge(attempt); faked {
+				return credential, false, nil, fakeErr
+			}
+			started := time.Now()
 			err = ErrLoginFailed
+			if realm.lockout != nil {
+				if lockedErr, locked := realm.countPhantomFailure(attempt.Identifier, attempt.Time); locked {
+					err = lockedErr
+				}
+			}
+			realm.failureCost.pad(started)
 		}
-		return nil, err
+		return credential, false, nil, err
 	} else {
-		for _, step := range realm.steps {
-			if stepErr := step.Login(credential, password); stepErr != nil {
-				return nil, stepErr
+		if realm.lockout != nil {
+			if lockedErr, locked := realm.lockedOut(credential, attempt.Time); locked {
+				// The steps run anyway, like for the unknown
+				// identifiers, but without counting failures.
+				realm.runAllSteps(credential, attempt)
+				started := time.Now()
+				_ = realm.trackLogin(credential, attempt, history.LockedOut)
+				realm.failureCost.observe(credential, started)
+				return credential, true, nil, lockedErr
 			}
 		}
-		return credential, nil
+		if step, err := realm.runSteps(credential, attempt); err != nil {
+			counted, ok := step.(login.CountedStep)
+			return credential, true, step, realm.failLogin(credential, attempt, ok && counted.CountsFailures(), err)
+		}
+		if realm.lockout != nil {
+			if err := realm.resetFailures(credential); err != nil {
+				return credential, true, nil, err
+			}
+		}
+		if enabled, required := realm.enabledFactors(credential); required {
+			if err := realm.trackLogin(credential, attempt, history.Challenged); err != nil {
+				return credential, true, nil, err
+			}
+			return credential, true, nil, realm.issueChallenge(credential, enabled, attempt)
+		}
+		if err := realm.trackLogin(credential, attempt, history.Succeeded); err != nil {
+			return credential, true, nil, err
+		}
+		return credential, true, nil, nil
+	}
+}
+
+// Records the failed login of an existing credential, returning
+// the error to tell: the lockout error if it got locked out. The
+// counted failures (e.g. wrong passwords) are counted for the
+// credential, and the other ones (e.g. of inactive credentials)
+// like the ones of unknown identifiers, so both get locked out
+// alike.
+func (realm *TypedRealm[T]) failLogin(credential T, attempt *login.Attempt, counted bool, err error) error {
+	started := time.Now()
+	if counted && realm.lockout != nil {
+		if lockedErr, countErr := realm.countFailure(credential, attempt.Time); countErr != nil {
+			return countErr
+		} else if lockedErr != nil {
+			err = lockedErr
+		}
+	} else if realm.lockout != nil {
+		if lockedErr, locked := realm.countPhantomFailure(attempt.Identifier, attempt.Time); locked {
+			err = lockedErr
+		}
 	}
+	// Tracking errors are ignored, since the login
+	// failed anyway.
+	_ = realm.trackLogin(credential, attempt, history.Failed)
+	realm.failureCost.observe(credential, started)
+	return err
 }
 
 // Attempts a password change, which involves invoking the appropriate hashing.
-// The credential will be saved after that.
-func (realm *Realm) SetPassword(credential credentials.Credential, password string) error {
+// The credential will be saved after that. The actor (e.g. an administrator)
+// may be nil.
+func (realm *TypedRealm[T]) SetPassword(credential T, password string, by credentials.Credential) error {
 	if hashedPassword, err := credential.Hasher().Hash(password); err != nil {
 		return err
 	} else {
-		credential.SetHashedPassword(hashedPassword)
-		return realm.source.Save(credential)
+		return realm.mutateAndPublish(credential, func(current T) error {
+			current.SetHashedPassword(hashedPassword)
+			return nil
+		}, func(saved T) events.Event {
+			return &events.PasswordChanged{Time: time.Now(), Credential: saved, By: by}
+		})
 	}
 }
 
 // Attempts a password unset, which involves deleting the hashed password.
-// The credential will be saved after that.
-func (realm *Realm) UnsetPassword(credential credentials.Credential) error {
-	credential.SetHashedPassword("")
-	return realm.source.Save(credential)
+// The credential will be saved after that. The actor may be nil.
+func (realm *TypedRealm[T]) UnsetPassword(credential T, by credentials.Credential) error {
+	return realm.mutateAndPublish(credential, func(current T) error {
+		current.SetHashedPassword("")
+		return nil
+	}, func(saved T) events.Event {
+		return &events.PasswordUnset{Time: time.Now(), Credential: saved, By: by}
+	})
 }
 
 // Attempts a by-user password change, which involves invoking the appropriate
 // hashing and also validating the current password. The credential will be
 // saved after that.
-func (realm *Realm) ChangePassword(credential credentials.Credential, currentPassword, newPassword string) error {
+func (realm *TypedRealm[T]) ChangePassword(credential T, currentPassword, newPassword string) error {
+	// The current password is checked before hashing the new
+	// one, so a wrong password does not cost a hash.
 	if err := credential.Hasher().Validate(currentPassword, credential.HashedPassword()); err != nil {
 		return ErrBadCurrentPassword
+	} else if hashedPassword, err := credential.Hasher().Hash(newPassword); err != nil {
+		return err
 	} else {
-		return realm.SetPassword(credential, newPassword)
+		return realm.mutateAndPublish(credential, func(current T) error {
+			// The current password is checked again inside the
+			// mutation, since the credential may be changed while
+			// waiting for the lock, or reloaded after a concurrent
+			// change.
+			if err := current.Hasher().Validate(currentPassword, current.HashedPassword()); err != nil {
+				return ErrBadCurrentPassword
+			}
+			current.SetHashedPassword(hashedPassword)
+			return nil
+		}, func(saved T) events.Event {
+			return &events.PasswordChanged{Time: time.Now(), Credential: saved, ByUser: true}
+		})
 	}
 }
 
-// Attempts an external, non-logged and to-be-confirmed attempt to reset a password.
-// It will set the recovery token and save the credential. This call is only allowed
-// if the credential is of a recoverable type.
-func (realm *Realm) PreparePasswordReset(credential credentials.Credential, token string, duration time.Duration) error {
-	if recoverableCred, ok := credential.(recoverable.Recoverable); !ok {
+// Sets the recovery token of a credential, saving it and
+// publishing the event made for it.
+func (realm *TypedRealm[T]) setRecoveryToken(credential T, token string, duration time.Duration, event func(T) events.Event) error {
+	if _, ok := credentials.Credential(credential).(recoverable.Recoverable); !ok {
 		return ErrNotRecoverable
 	} else {
-		recoverableCred.SetRecoveryToken(token, duration)
-		return realm.source.Save(credential)
+		return realm.mutateAndPublish(credential, func(current T) error {
+			credentials.Credential(current).(recoverable.Recoverable).SetRecoveryToken(token, duration)
+			return nil
+		}, event)
 	}
 }
 
+// Attempts an external, non-logged and to-be-confirmed attempt to reset a password.
+// It will set the recovery token and save the credential. This call is only allowed
+// if the credential is of a recoverable type. The actor (e.g. an administrator
+// starting the reset for the user) may be nil.
+func (realm *TypedRealm[T]) PreparePasswordReset(credential T, token string, duration time.Duration, by credentials.Credential) error {
+	return realm.setRecoveryToken(credential, token, duration, func(saved T) events.Event {
+		return &events.ResetPrepared{Time: time.Now(), Credential: saved, Duration: duration, By: by}
+	})
+}
+
 // Clears an external, non-logged and to-be-confirmed attempt to reset a password.
-// This call is only allowed if the credential is of a recoverable type.
-func (realm *Realm) CancelPasswordReset(credential credentials.Credential) error {
-	return realm.PreparePasswordReset(credential, "", time.Duration(0))
+// This call is only allowed if the credential is of a recoverable type. The actor
+// may be nil.
+func (realm *TypedRealm[T]) CancelPasswordReset(credential T, by credentials.Credential) error {
+	return realm.setRecoveryToken(credential, "", time.Duration(0), func(saved T) events.Event {
+		return &events.ResetCancelled{Time: time.Now(), Credential: saved, By: by}
+	})
 }
 
 // Confirms an external, non-logged and to-be-confirmed attempt to reset a password.
 // This call is only allowed if the credential is of a recoverable type.
-func (realm *Realm) ConfirmPasswordReset(credential credentials.Credential, token, password string) error {
-	if recoverableCred, ok := credential.(recoverable.Recoverable); !ok {
+func (realm *TypedRealm[T]) ConfirmPasswordReset(credential T, token, password string) error {
+	if _, ok := credentials.Credential(credential).(recoverable.Recoverable); !ok {
 		return ErrNotRecoverable
-	} else if token != recoverableCred.RecoveryToken() || token == "" {
+	} else if token == "" {
 		return ErrBadToken
 	} else if hashed, err := credential.Hasher().Hash(password); err != nil {
 		return err
 	} else {
-		credential.SetHashedPassword(hashed)
-		recoverableCred.SetRecoveryToken("", time.Duration(0))
-		return realm.source.Save(credential)
+		return realm.mutateAndPublish(credential, func(current T) error {
+			// The token is checked inside the mutation, since the
+			// credential may be changed while waiting for the lock,
+			// or reloaded after a concurrent change.
+			currentRecoverable := credentials.Credential(current).(recoverable.Recoverable)
+			if token != currentRecoverable.RecoveryToken() {
+				return ErrBadToken
+			}
+			current.SetHashedPassword(hashed)
+			currentRecoverable.SetRecoveryToken("", time.Duration(0))
+			return nil
+		}, func(saved T) events.Event {
+			return &events.ResetConfirmed{Time: time.Now(), Credential: saved}
+		})
 	}
 }
 
-// Creates a new realm.
-func NewRealm(source *credentials.Source, steps ...login.PipelineStep) *Realm {
+// Punishes a credential for the given time (or permanently, if nil), replacing
+// any current punishment. The credential will be saved after that. This call
+// is only allowed if the credential is of a punishable type.
+func (realm *TypedRealm[T]) Punish(credential T, forTime *time.Duration, reason interface{}, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(deniable.Punishable); !ok {
+		return ErrNotPunishable
+	} else {
+		return realm.mutateAndPublish(credential, func(current T) error {
+			credentials.Credential(current).(deniable.Punishable).Punish(forTime, reason, by)
+			return nil
+		}, func(saved T) events.Event {
+			return &events.Punished{Time: time.Now(), Credential: saved, Duration: forTime, Reason: reason, By: by}
+		})
+	}
+}
+
+// Clears the punishment of a credential. The credential will be saved after
+// that. This call is only allowed if the credential is of a punishable type.
+// The actor may be nil.
+func (realm *TypedRealm[T]) Unpunish(credential T, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(deniable.Punishable); !ok {
+		return ErrNotPunishable
+	} else {
+		return realm.mutateAndPublish(credential, func(current T) error {
+			credentials.Credential(current).(deniable.Punishable).Unpunish()
+			return nil
+		}, func(saved T) events.Event {
+			return &events.Unpunished{Time: time.Now(), Credential: saved, By: by}
+		})
+	}
+}
+
+// Activates or deactivates a credential. The credential will be saved after
+// that. This call is only allowed if the credential is of an activable type.
+// The actor may be nil.
+func (realm *TypedRealm[T]) SetActive(credential T, active bool, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(deniable.Activable); !ok {
+		return ErrNotActivable
+	} else {
+		return realm.mutateAndPublish(credential, func(current T) error {
+			credentials.Credential(current).(deniable.Activable).SetActive(active)
+			return nil
+		}, func(saved T) events.Event {
+			return &events.ActivationChanged{Time: time.Now(), Credential: saved, Active: active, By: by}
+		})
+	}
+}
+
+// Creates a new typed realm.
+func NewTypedRealm[T credentials.Credential](source *credentials.TypedSource[T], steps ...login.PipelineStep) *TypedRealm[T] {
 	if source == nil {
 		panic(ErrNilSource)
 	}
@@ -158,5 +481,10 @@ func NewRealm(source *credentials.Source, steps ...login.PipelineStep) *Realm {
 		}
 	}
 
-	return &Realm{source: source, steps: steps}
+	return &TypedRealm[T]{source: source, steps: steps}
+}
+
+// Creates a new realm.
+func NewRealm(source *credentials.Source, steps ...login.PipelineStep) *Realm {
+	return NewTypedRealm(source, steps...)
 }
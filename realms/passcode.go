@@ -0,0 +1,30 @@
+package realms
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"time"
+)
+
+// Error to return when attempting to use passcode features on
+// a credential that is not passcode capable.
+var ErrNotPasscodeCapable = errors.New("the credential is not a passcode capable type")
+
+// Sets whether a credential is enrolled to receive passcodes.
+// Disabling them also clears the pending passcode, if any. The
+// credential will be saved after that. This call is only allowed
+// if the credential is passcode capable.
+func (realm *TypedRealm[T]) SetPasscodeEnrolled(credential T, enrolled bool) error {
+	if _, ok := credentials.Credential(credential).(otp.PasscodeCapable); !ok {
+		return ErrNotPasscodeCapable
+	}
+	return realm.mutate(credential, func(current T) error {
+		capable := credentials.Credential(current).(otp.PasscodeCapable)
+		capable.SetPasscodeEnrolled(enrolled)
+		if !enrolled {
+			capable.SetPasscode("", time.Time{}, 0)
+		}
+		return nil
+	})
+}
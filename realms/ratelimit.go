@@ -0,0 +1,11 @@
+package realms
+
+import "github.com/universe-10th/identity/realms/ratelimit"
+
+// Sets the rate limiter of the realm, which rejects the login
+// attempts exceeding its limits with a *ratelimit.RateLimitedError
+// before looking the credential up (so unknown identifiers are
+// limited the same way). By default, no rate limiting is done.
+func (realm *TypedRealm[T]) SetRateLimiter(limiter *ratelimit.Limiter) {
+	realm.limiter = limiter
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/realms/login"
+	"net"
+	"time"
+)
+
+// The scopes of the limits, as reported by RateLimitedError.
+const (
+	IdentifierScope = "identifier"
+	AddressScope    = "address"
+	SubnetScope     = "subnet"
+	GlobalScope     = "global"
+)
+
+// The default prefix lengths of the IPv4 and IPv6 subnets.
+const (
+	DefaultIPv4Prefix = 24
+	DefaultIPv6Prefix = 64
+)
+
+// Panicked when a nil store is given to a limiter.
+var ErrNilStore = errors.New("rate limit store is nil")
+
+// A token bucket limit: a bucket holds up to Burst tokens,
+// regaining one of them every Interval, and each attempt
+// takes one token. Attempts are rejected while the bucket
+// is empty.
+type Limit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// Returned by a limiter (and a realm using it) when an attempt
+// is rejected by one of the limits. The attempt may be done
+// again after RetryAfter.
+type RateLimitedError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (error *RateLimitedError) Error() string {
+	return fmt.Sprintf("too many login attempts (%s), retry after: %s", error.Scope, error.RetryAfter.Round(time.Second))
+}
+
+// A store keeps the token buckets. Take must atomically refill
+// the bucket of the key (creating it full, if it does not exist),
+// and take a token from it. If there are no tokens, it must tell
+// how long until there is one. Stores backed by shared services
+// allow limiting the attempts across processes.
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// A limiter rejects the login attempts exceeding the limits
+// per identifier, per client address, per client subnet (of
+// IPv4Prefix and IPv6Prefix bits) and globally. Nil limits
+// are not applied, as well as the address and subnet limits
+// for attempts without a client IP.
+type Limiter struct {
+	Store         Store
+	PerIdentifier *Limit
+	PerAddress    *Limit
+	PerSubnet     *Limit
+	Global        *Limit
+	IPv4Prefix    int
+	IPv6Prefix    int
+}
+
+// Creates a new limiter, with no limits and the default
+// subnet prefixes.
+func NewLimiter(store Store) *Limiter {
+	if store == nil {
+		panic(ErrNilStore)
+	}
+	return &Limiter{Store: store, IPv4Prefix: DefaultIPv4Prefix, IPv6Prefix: DefaultIPv6Prefix}
+}
+
+// Returns the subnet of an IP, as a string.
+func (limiter *Limiter) subnet(ip net.IP) string {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return (&net.IPNet{IP: ipv4.Mask(net.CIDRMask(limiter.IPv4Prefix, 32)), Mask: net.CIDRMask(limiter.IPv4Prefix, 32)}).String()
+	} else {
+		return (&net.IPNet{IP: ip.Mask(net.CIDRMask(limiter.IPv6Prefix, 128)), Mask: net.CIDRMask(limiter.IPv6Prefix, 128)}).String()
+	}
+}
+
+// Takes a token for the attempt from each of the buckets it
+// belongs to, from the most specific to the global one. Returns
+// a *RateLimitedError for the first bucket with no tokens, or
+// the error of the store. This does not tell whether the
+// identifier exists, since it does not look it up.
+func (limiter *Limiter) Allow(attempt *login.Attempt) error {
+	now := attempt.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	type check struct {
+		scope string
+		key   string
+		limit *Limit
+	}
+	checks := []check{{IdentifierScope, fmt.Sprintf("identifier/%v", attempt.Identifier), limiter.PerIdentifier}}
+	if attempt.ClientIP != nil {
+		checks = append(checks,
+			check{AddressScope, "address/" + attempt.ClientIP.String(), limiter.PerAddress},
+			check{SubnetScope, "subnet/" + limiter.subnet(attempt.ClientIP), limiter.PerSubnet},
+		)
+	}
+	checks = append(checks, check{GlobalScope, "global", limiter.Global})
+
+	for _, current := range checks {
+		if current.limit == nil {
+			continue
+		}
+		if allowed, retryAfter, err := limiter.Store.Take(current.key, *current.limit, now); err != nil {
+			return err
+		} else if !allowed {
+			return &RateLimitedError{Scope: current.scope, RetryAfter: retryAfter}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// The default maximum number of buckets kept by a memory
+// store before forgetting the full ones.
+const DefaultMaxBuckets = 100000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// Refills the bucket up to the given time.
+func (bucket *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 && bucket.limit.Interval > 0 {
+		bucket.tokens += float64(elapsed) / float64(bucket.limit.Interval)
+		bucket.updated = now
+	}
+	if bucket.tokens > float64(bucket.limit.Burst) {
+		bucket.tokens = float64(bucket.limit.Burst)
+	}
+}
+
+// A memory store keeps the token buckets in memory, so
+// the limits apply only to the current process. When it
+// holds MaxBuckets buckets, the full ones are forgotten
+// (they are the same as new ones).
+type MemoryStore struct {
+	MaxBuckets int
+
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+// Creates a new memory store, with DefaultMaxBuckets.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{MaxBuckets: DefaultMaxBuckets, buckets: map[string]*bucket{}}
+}
+
+// Takes a token from the bucket of the key.
+func (store *MemoryStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.buckets == nil {
+		store.buckets = map[string]*bucket{}
+	}
+
+	current, ok := store.buckets[key]
+	if !ok {
+		if store.MaxBuckets > 0 && len(store.buckets) >= store.MaxBuckets {
+			store.prune(now)
+		}
+		current = &bucket{tokens: float64(limit.Burst), updated: now}
+		store.buckets[key] = current
+	}
+	current.limit = limit
+	current.refill(now)
+	if current.tokens >= 1 {
+		current.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration(math.Ceil((1 - current.tokens) * float64(limit.Interval))), nil
+}
+
+func (store *MemoryStore) prune(now time.Time) {
+	for key, current := range store.buckets {
+		if current.refill(now); current.tokens >= float64(current.limit.Burst) {
+			delete(store.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package realms
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/twofactor/recoverycodes"
+	"time"
+)
+
+// Error to return when attempting to use recovery codes on a
+// credential that cannot hold them.
+var ErrNotRecoveryCodesCapable = errors.New("the credential is not a recovery codes capable type")
+
+// Generates new one-time recovery codes (recoverycodes.DefaultCount
+// if count <= 0) for a credential, replacing the current ones. They
+// are hashed through the credential's hasher, and the credential will
+// be saved after that (publishing RecoveryCodesGenerated). The plain
+// text codes are returned, to be shown to the user only once. This
+// call is only allowed if the credential is recovery codes capable.
+func (realm *TypedRealm[T]) RegenerateRecoveryCodes(credential T, count int) ([]string, error) {
+	if _, ok := credentials.Credential(credential).(otp.RecoveryCodesCapable); !ok {
+		return nil, ErrNotRecoveryCodesCapable
+	} else if codes, err := recoverycodes.Generate(count); err != nil {
+		return nil, err
+	} else if hashed, err := recoverycodes.Hash(credential, codes); err != nil {
+		return nil, err
+	} else if err := realm.mutateAndPublish(credential, func(current T) error {
+		credentials.Credential(current).(otp.RecoveryCodesCapable).SetRecoveryCodes(hashed)
+		return nil
+	}, func(saved T) events.Event {
+		return &events.RecoveryCodesGenerated{Time: time.Now(), Credential: saved, Count: len(codes)}
+	}); err != nil {
+		return nil, err
+	} else {
+		return codes, nil
+	}
+}
@@ -0,0 +1,286 @@
+package realms
+
+import (
+	"bytes"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/restorable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"reflect"
+	"time"
+)
+
+// A snapshot keeps the values a realm may change in a
+// credential, so they can be restored if a mutation or
+// its save fails. This keeps the in-memory credential
+// matching the stored one. When the credential is
+// restorable (like the tagged credentials), the snapshot
+// is the one it takes, and when it is a pointer to a
+// struct, the snapshot is a copy of the struct.
+type snapshot struct {
+	credential       credentials.Credential
+	restorable       restorable.Restorable
+	state            interface{}
+	target           reflect.Value
+	copied           reflect.Value
+	hashedPassword   string
+	token            string
+	expiration       time.Time
+	active           bool
+	punishedOn       *time.Time
+	punishedFor      *time.Duration
+	reason           interface{}
+	punishedBy       credentials.Credential
+	version          uint64
+	identification   interface{}
+	totpSecret       []byte
+	totpEnrolled     bool
+	totpLastStep     int64
+	recoveryCodes    []string
+	passcodeEnrolled bool
+	passcode         string
+	passcodeExpiry   time.Time
+	passcodeTries    int
+	webAuthn         []webauthn.PublicKeyCredential
+	failedLogins     int
+	lastFailure      time.Time
+	lastLogin        time.Time
+	lastFailed       time.Time
+	loginHistory     []history.Entry
+	scopes           map[string]scoped.Scope
+}
+
+// Returns the struct a credential points to, if any.
+func structOf(credential credentials.Credential) (reflect.Value, bool) {
+	target := reflect.ValueOf(credential)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return target.Elem(), true
+}
+
+func takeSnapshot(credential credentials.Credential) *snapshot {
+	if restorableCred, ok := credential.(restorable.Restorable); ok {
+		return &snapshot{credential: credential, restorable: restorableCred, state: restorableCred.Snapshot()}
+	}
+	if target, ok := structOf(credential); ok {
+		copied := reflect.New(target.Type()).Elem()
+		copied.Set(target)
+		return &snapshot{credential: credential, target: target, copied: copied}
+	}
+
+	result := &snapshot{credential: credential, hashedPassword: credential.HashedPassword()}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok {
+		result.token = recoverableCred.RecoveryToken()
+		if expiringCred, ok := credential.(recoverable.Expiring); ok {
+			result.expiration = expiringCred.RecoveryTokenExpiration()
+		}
+	}
+	if activableCred, ok := credential.(deniable.Activable); ok {
+		result.active = activableCred.Active()
+	}
+	if punishableCred, ok := credential.(deniable.Punishable); ok {
+		result.punishedOn, result.punishedFor, result.reason, result.punishedBy = punishableCred.PunishedFor()
+	}
+	if versionedCred, ok := credential.(versioned.Versioned); ok {
+		result.version = versionedCred.Version()
+	}
+	if reidentifiable, ok := credential.(identified.Reidentifiable); ok {
+		result.identification = reidentifiable.Identification()
+	}
+	if totpCapable, ok := credential.(otp.TOTPCapable); ok {
+		result.totpSecret, result.totpEnrolled = totpCapable.TOTPSecret()
+		result.totpLastStep = totpCapable.TOTPLastStep()
+	}
+	if codesCapable, ok := credential.(otp.RecoveryCodesCapable); ok {
+		result.recoveryCodes = append([]string(nil), codesCapable.RecoveryCodes()...)
+	}
+	if passcodeCapable, ok := credential.(otp.PasscodeCapable); ok {
+		result.passcodeEnrolled = passcodeCapable.PasscodeEnrolled()
+		result.passcode, result.passcodeExpiry, result.passcodeTries = passcodeCapable.Passcode()
+	}
+	if webAuthnCapable, ok := credential.(webauthn.WebAuthnCapable); ok {
+		result.webAuthn = append([]webauthn.PublicKeyCredential(nil), webAuthnCapable.WebAuthnCredentials()...)
+	}
+	if countingCred, ok := credential.(deniable.FailureCounting); ok {
+		result.failedLogins, result.lastFailure = countingCred.FailedLogins()
+	}
+	if tracked, ok := credential.(history.Tracked); ok {
+		result.lastLogin, result.lastFailed = tracked.LastLogin(), tracked.LastFailedLogin()
+		result.loginHistory = append([]history.Entry(nil), tracked.LoginHistory()...)
+	}
+	if editable, ok := credential.(scoped.Editable); ok {
+		result.scopes = editable.Scopes()
+	}
+	return result
+}
+
+// Restores the values that differ from the snapshot. Restorable
+// credentials restore their own snapshots, and structs are
+// restored exactly, as a whole. Otherwise, the values are
+// restored through the traits: a recovery token can only be
+// restored with its exact expiration if the credential also
+// implements the recoverable.Expiring trait (other tokens are
+// cleared instead), and timed punishments are restored with
+// their original end time, but starting now.
+func (snapshot *snapshot) restore() {
+	if snapshot.restorable != nil {
+		snapshot.restorable.Restore(snapshot.state)
+		return
+	}
+	if snapshot.target.IsValid() {
+		snapshot.target.Set(snapshot.copied)
+		return
+	}
+
+	credential := snapshot.credential
+	if credential.HashedPassword() != snapshot.hashedPassword {
+		credential.SetHashedPassword(snapshot.hashedPassword)
+	}
+	if recoverableCred, ok := credential.(recoverable.Recoverable); ok && recoverableCred.RecoveryToken() != snapshot.token {
+		if _, ok := credential.(recoverable.Expiring); ok && snapshot.token != "" {
+			recoverableCred.SetRecoveryToken(snapshot.token, time.Until(snapshot.expiration))
+		} else {
+			recoverableCred.SetRecoveryToken("", time.Duration(0))
+		}
+	}
+	if activableCred, ok := credential.(deniable.Activable); ok && activableCred.Active() != snapshot.active {
+		activableCred.SetActive(snapshot.active)
+	}
+	if punishableCred, ok := credential.(deniable.Punishable); ok {
+		if punishedOn, punishedFor, reason, by := punishableCred.PunishedFor(); !sameTime(punishedOn, snapshot.punishedOn) ||
+			!sameDuration(punishedFor, snapshot.punishedFor) || !sameValue(reason, snapshot.reason) || !sameValue(by, snapshot.punishedBy) {
+			if snapshot.punishedOn == nil {
+				punishableCred.Unpunish()
+			} else if snapshot.punishedFor == nil {
+				punishableCred.Punish(nil, snapshot.reason, snapshot.punishedBy)
+			} else {
+				remaining := time.Until(snapshot.punishedOn.Add(*snapshot.punishedFor))
+				punishableCred.Punish(&remaining, snapshot.reason, snapshot.punishedBy)
+			}
+		}
+	}
+	if versionedCred, ok := credential.(versioned.Versioned); ok && versionedCred.Version() != snapshot.version {
+		versionedCred.SetVersion(snapshot.version)
+	}
+	if reidentifiable, ok := credential.(identified.Reidentifiable); ok && reidentifiable.Identification() != snapshot.identification {
+		reidentifiable.SetIdentification(snapshot.identification)
+	}
+	if totpCapable, ok := credential.(otp.TOTPCapable); ok {
+		if secret, enrolled := totpCapable.TOTPSecret(); !bytes.Equal(secret, snapshot.totpSecret) || enrolled != snapshot.totpEnrolled {
+			totpCapable.SetTOTPSecret(snapshot.totpSecret, snapshot.totpEnrolled)
+		}
+		if totpCapable.TOTPLastStep() != snapshot.totpLastStep {
+			totpCapable.SetTOTPLastStep(snapshot.totpLastStep)
+		}
+	}
+	if codesCapable, ok := credential.(otp.RecoveryCodesCapable); ok && !sameStrings(codesCapable.RecoveryCodes(), snapshot.recoveryCodes) {
+		codesCapable.SetRecoveryCodes(snapshot.recoveryCodes)
+	}
+	if passcodeCapable, ok := credential.(otp.PasscodeCapable); ok {
+		if passcodeCapable.PasscodeEnrolled() != snapshot.passcodeEnrolled {
+			passcodeCapable.SetPasscodeEnrolled(snapshot.passcodeEnrolled)
+		}
+		if code, expiry, tries := passcodeCapable.Passcode(); code != snapshot.passcode || !expiry.Equal(snapshot.passcodeExpiry) || tries != snapshot.passcodeTries {
+			passcodeCapable.SetPasscode(snapshot.passcode, snapshot.passcodeExpiry, snapshot.passcodeTries)
+		}
+	}
+	if webAuthnCapable, ok := credential.(webauthn.WebAuthnCapable); ok && !sameWebAuthn(webAuthnCapable.WebAuthnCredentials(), snapshot.webAuthn) {
+		webAuthnCapable.SetWebAuthnCredentials(snapshot.webAuthn)
+	}
+	if countingCred, ok := credential.(deniable.FailureCounting); ok {
+		if count, last := countingCred.FailedLogins(); count != snapshot.failedLogins || !last.Equal(snapshot.lastFailure) {
+			countingCred.SetFailedLogins(snapshot.failedLogins, snapshot.lastFailure)
+		}
+	}
+	if tracked, ok := credential.(history.Tracked); ok {
+		if !tracked.LastLogin().Equal(snapshot.lastLogin) || !tracked.LastFailedLogin().Equal(snapshot.lastFailed) ||
+			!sameHistory(tracked.LoginHistory(), snapshot.loginHistory) {
+			tracked.SetLoginHistory(snapshot.lastLogin, snapshot.lastFailed, snapshot.loginHistory)
+		}
+	}
+	if editable, ok := credential.(scoped.Editable); ok && !sameScopes(editable.Scopes(), snapshot.scopes) {
+		editable.SetScopes(snapshot.scopes)
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameScopes(a, b map[string]scoped.Scope) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, scope := range a {
+		if other, ok := b[key]; !ok || !sameValue(other, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameWebAuthn(a, b []webauthn.PublicKeyCredential) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if !bytes.Equal(a[index].ID, b[index].ID) || a[index].SignCount != b[index].SignCount {
+			return false
+		}
+	}
+	return true
+}
+
+func sameHistory(a, b []history.Entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if !a[index].Time.Equal(b[index].Time) || a[index].Outcome != b[index].Outcome || a[index].ClientIP != b[index].ClientIP ||
+			a[index].UserAgent != b[index].UserAgent || a[index].DeviceID != b[index].DeviceID {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return a == b
+	} else if reflect.TypeOf(a).Comparable() {
+		return a == b
+	} else {
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func sameDuration(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	} else {
+		return *a == *b
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	} else {
+		return a.Equal(*b)
+	}
+}
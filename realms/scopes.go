@@ -0,0 +1,81 @@
+package realms
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/realms/events"
+	"time"
+)
+
+// Error to return when attempting to grant or revoke scopes
+// of a credential whose scopes are not editable.
+var ErrNotScopeEditable = errors.New("the credential is not a scope editable type")
+
+// Error to return when attempting to grant a nil scope.
+var ErrNilScope = errors.New("the scope is nil")
+
+// Grants a scope to a credential, on behalf of another one (which
+// may be nil). The credential will be saved after that, and the
+// ScopeGranted event is published unless it already had the scope.
+// This call is only allowed if the credential's scopes are editable.
+func (realm *TypedRealm[T]) GrantScope(credential T, scope scoped.Scope, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(scoped.Editable); !ok {
+		return ErrNotScopeEditable
+	} else if scope == nil {
+		return ErrNilScope
+	}
+	key := scope.Key()
+	changed := false
+	return realm.mutateAndPublish(credential, func(current T) error {
+		editable := credentials.Credential(current).(scoped.Editable)
+		_, granted := editable.Scopes()[key]
+		changed = !granted
+		if changed {
+			scopes := copyScopes(editable.Scopes())
+			scopes[key] = scope
+			editable.SetScopes(scopes)
+		}
+		return nil
+	}, func(saved T) events.Event {
+		if !changed {
+			return nil
+		}
+		return &events.ScopeGranted{Time: time.Now(), Credential: saved, Scope: key, By: by}
+	})
+}
+
+// Revokes a scope (by its key) from a credential, on behalf of
+// another one (which may be nil). The credential will be saved
+// after that, and the ScopeRevoked event is published unless it
+// did not have the scope. This call is only allowed if the
+// credential's scopes are editable.
+func (realm *TypedRealm[T]) RevokeScope(credential T, key string, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(scoped.Editable); !ok {
+		return ErrNotScopeEditable
+	}
+	changed := false
+	return realm.mutateAndPublish(credential, func(current T) error {
+		editable := credentials.Credential(current).(scoped.Editable)
+		_, changed = editable.Scopes()[key]
+		if changed {
+			scopes := copyScopes(editable.Scopes())
+			delete(scopes, key)
+			editable.SetScopes(scopes)
+		}
+		return nil
+	}, func(saved T) events.Event {
+		if !changed {
+			return nil
+		}
+		return &events.ScopeRevoked{Time: time.Now(), Credential: saved, Scope: key, By: by}
+	})
+}
+
+func copyScopes(scopes map[string]scoped.Scope) map[string]scoped.Scope {
+	copied := make(map[string]scoped.Scope, len(scopes)+1)
+	for key, scope := range scopes {
+		copied[key] = scope
+	}
+	return copied
+}
@@ -0,0 +1,385 @@
+package realms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"time"
+)
+
+// The default lifetime of a second factor challenge.
+const DefaultChallengeTTL = 5 * time.Minute
+
+// The default number of codes that may be attempted
+// for the second factor challenges of a credential.
+const DefaultChallengeAttempts = 5
+
+// Returned by CompleteLogin when the challenge handle is
+// unknown, expired, or its credential ran out of attempts.
+var ErrBadChallenge = errors.New("invalid or expired second factor challenge")
+
+// Panicked when a nil second factor is given to a realm.
+var ErrNilSecondFactor = errors.New("second factor is nil")
+
+// Returned by Login when the credential passed the pipeline
+// but a second factor is required. The handle must be given
+// to CompleteLogin, along with the code, before it expires.
+// Factors are the names of the factors enabled for the
+// credential.
+type SecondFactorRequiredError struct {
+	Handle    string
+	ExpiresAt time.Time
+	Factors   []string
+}
+
+func (error *SecondFactorRequiredError) Error() string {
+	return "second factor required"
+}
+
+type challenge[T credentials.Credential] struct {
+	credential T
+	attempt    *login.Attempt
+	expiresAt  time.Time
+	// The key the failed codes are counted by.
+	key string
+	// Fake challenges are issued for unknown identifiers, and
+	// never accept a code.
+	fake bool
+}
+
+// The failed codes of a credential, counted across all of its
+// challenges, so issuing a new challenge (i.e. logging in again)
+// does not grant more attempts.
+type codeFailures struct {
+	count int
+	last  time.Time
+}
+
+// Sets the second factors of the realm. When the credential
+// has at least one of them enabled (not counting fallbacks),
+// Login returns a *SecondFactorRequiredError instead of the
+// credential. By default, no second factor is used.
+func (realm *TypedRealm[T]) SetSecondFactors(factors ...twofactor.SecondFactor) {
+	for _, factor := range factors {
+		if factor == nil {
+			panic(ErrNilSecondFactor)
+		}
+	}
+	realm.factors = factors
+}
+
+// Sets the lifetime of the second factor challenges, and the
+// number of codes that may be attempted for the challenges of
+// each credential (by default: DefaultChallengeTTL and
+// DefaultChallengeAttempts). The count of failed codes of a
+// credential is kept across its challenges, and forgotten once
+// a code is accepted or after a lifetime without failures.
+// Credentials are told apart like for locking (by their type
+// and index), so the non-indexed ones have their failed codes
+// counted per challenge instead.
+func (realm *TypedRealm[T]) SetChallengeLimits(ttl time.Duration, attempts int) {
+	realm.challengeTTL = ttl
+	realm.challengeAttempts = attempts
+}
+
+// Returns the enabled second factors of a credential, and
+// whether any of them requires a second login stage.
+func (realm *TypedRealm[T]) enabledFactors(credential T) ([]twofactor.SecondFactor, bool) {
+	var enabled []twofactor.SecondFactor
+	required := false
+	for _, factor := range realm.factors {
+		if factor.Enabled(credential) {
+			enabled = append(enabled, factor)
+			if fallback, ok := factor.(twofactor.Fallback); !ok || !fallback.Fallback() {
+				required = true
+			}
+		}
+	}
+	return enabled, required
+}
+
+// Challenges the enabled factors supporting it, saving the
+// credential and then delivering the challenges.
+func (realm *TypedRealm[T]) challengeFactors(credential T, enabled []twofactor.SecondFactor) (T, error) {
+	var challengers []twofactor.Challenger
+	for _, factor := range enabled {
+		if challenger, ok := factor.(twofactor.Challenger); ok {
+			challengers = append(challengers, challenger)
+		}
+	}
+	if len(challengers) == 0 {
+		return credential, nil
+	}
+
+	var deliveries []func() error
+	challenged := credential
+	if err := realm.mutate(credential, func(current T) error {
+		challenged, deliveries = current, nil
+		for _, challenger := range challengers {
+			if deliver, err := challenger.Challenge(current); err != nil {
+				return err
+			} else {
+				deliveries = append(deliveries, deliver)
+			}
+		}
+		return nil
+	}); err != nil {
+		return challenged, err
+	}
+	for _, deliver := range deliveries {
+		if err := deliver(); err != nil {
+			return challenged, err
+		}
+	}
+	return challenged, nil
+}
+
+// Issues a challenge for a credential requiring a second factor,
+// challenging the enabled factors that support it.
+func (realm *TypedRealm[T]) issueChallenge(credential T, enabled []twofactor.SecondFactor, attempt *login.Attempt) error {
+	credential, err := realm.challengeFactors(credential, enabled)
+	if err != nil {
+		return err
+	}
+	return realm.registerChallenge(credential, enabled, attempt, false)
+}
+
+// Issues a fake challenge for an unknown identifier, when the
+// realm has no steps counting failures (e.g. password checks)
+// and the dummy credential would require a second factor, like
+// for passwordless logins. So the response does not tell whether
+// the identifier exists. The factors challenge the dummy, but
+// nothing is delivered. Returns false if no fake challenge is
+// due.
+func (realm *TypedRealm[T]) fakeChallenge(attempt *login.Attempt) (error, bool) {
+	for _, step := range realm.steps {
+		if counted, ok := step.(login.CountedStep); ok && counted.CountsFailures() {
+			return nil, false
+		}
+	}
+	dummy := realm.source.Dummy()
+	enabled, required := realm.enabledFactors(dummy)
+	if !required {
+		return nil, false
+	}
+	for _, factor := range enabled {
+		if challenger, ok := factor.(twofactor.Challenger); ok {
+			if _, err := challenger.Challenge(dummy); err != nil {
+				return err, true
+			}
+		}
+	}
+	return realm.registerChallenge(dummy, enabled, attempt, true), true
+}
+
+// Registers a challenge, returning the error telling its handle.
+func (realm *TypedRealm[T]) registerChallenge(credential T, enabled []twofactor.SecondFactor, attempt *login.Attempt, fake bool) error {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return err
+	}
+
+	ttl := realm.challengeTTL
+	if ttl <= 0 {
+		ttl = DefaultChallengeTTL
+	}
+	now := time.Now()
+	result := &SecondFactorRequiredError{Handle: hex.EncodeToString(buffer), ExpiresAt: now.Add(ttl)}
+	for _, factor := range enabled {
+		result.Factors = append(result.Factors, factor.Name())
+	}
+
+	key, ok := lockKey(credential)
+	if !ok || fake {
+		key = result.Handle
+	}
+
+	realm.challengesMutex.Lock()
+	defer realm.challengesMutex.Unlock()
+	if realm.challenges == nil {
+		realm.challenges = map[string]*challenge[T]{}
+		realm.codeFailures = map[string]*codeFailures{}
+	}
+	for handle, existing := range realm.challenges {
+		if now.After(existing.expiresAt) {
+			delete(realm.challenges, handle)
+		}
+	}
+	for failureKey, failures := range realm.codeFailures {
+		if now.After(failures.last.Add(ttl)) {
+			delete(realm.codeFailures, failureKey)
+		}
+	}
+	realm.challenges[result.Handle] = &challenge[T]{
+		credential: credential, attempt: attempt, expiresAt: result.ExpiresAt, key: key, fake: fake,
+	}
+	return result
+}
+
+// The number of codes that may be attempted per credential.
+func (realm *TypedRealm[T]) maxChallengeAttempts() int {
+	if realm.challengeAttempts <= 0 {
+		return DefaultChallengeAttempts
+	}
+	return realm.challengeAttempts
+}
+
+// Takes a challenge out, so it cannot be redeemed concurrently.
+// It is not valid if expired, or if its credential ran out of
+// attempts.
+func (realm *TypedRealm[T]) takeChallenge(handle string) (*challenge[T], bool) {
+	realm.challengesMutex.Lock()
+	defer realm.challengesMutex.Unlock()
+	if current, ok := realm.challenges[handle]; !ok {
+		return nil, false
+	} else {
+		delete(realm.challenges, handle)
+		if failures, ok := realm.codeFailures[current.key]; ok && failures.count >= realm.maxChallengeAttempts() {
+			return current, false
+		}
+		return current, time.Now().Before(current.expiresAt)
+	}
+}
+
+// Counts a failed code for the credential of a challenge, and
+// puts the challenge back unless the credential ran out of
+// attempts.
+func (realm *TypedRealm[T]) putChallenge(handle string, current *challenge[T]) {
+	realm.challengesMutex.Lock()
+	defer realm.challengesMutex.Unlock()
+	failures, ok := realm.codeFailures[current.key]
+	if !ok {
+		failures = &codeFailures{}
+		realm.codeFailures[current.key] = failures
+	}
+	failures.count++
+	failures.last = time.Now()
+	if failures.count < realm.maxChallengeAttempts() {
+		realm.challenges[handle] = current
+	}
+}
+
+// Forgets the failed codes for the credential of a challenge.
+func (realm *TypedRealm[T]) clearCodeFailures(current *challenge[T]) {
+	realm.challengesMutex.Lock()
+	defer realm.challengesMutex.Unlock()
+	delete(realm.codeFailures, current.key)
+}
+
+// Runs the pipeline steps not counting failures (e.g. the
+// activity and punishment checks) over a credential, so the
+// ones denied after passing the first login stage cannot
+// complete the second one.
+func (realm *TypedRealm[T]) runChecks(credential credentials.Credential, attempt *login.Attempt) (login.PipelineStep, error) {
+	for _, step := range realm.steps {
+		if counted, ok := step.(login.CountedStep); ok && counted.CountsFailures() {
+			continue
+		}
+		if err := login.Adapt(step).LoginAttempt(credential, attempt); err != nil {
+			return step, err
+		}
+	}
+	return nil, nil
+}
+
+// Completes a login requiring a second factor, by redeeming the
+// handle of the challenge with a code. The code is verified by
+// the enabled factors of the credential (including fallbacks),
+// and the credential is saved when one of them accepts it (e.g.
+// to prevent replays), or when none accepts it but some of them
+// record the failure. Returns ErrBadChallenge if the handle is
+// unknown or expired, and twofactor.ErrBadCode (or whatever the
+// factors return) if the code is not accepted. The credential can
+// attempt a limited number of codes (see SetChallengeLimits). The
+// pipeline steps not counting failures (e.g. the activity and
+// punishment checks) run again before verifying the code, over
+// the credential reloaded through ByIndex (when it implements the
+// Indexed trait), and their rejections discard the challenge.
+func (realm *TypedRealm[T]) CompleteLogin(handle string, code string) (T, error) {
+	var zero T
+	current, ok := realm.takeChallenge(handle)
+	if !ok {
+		realm.bus.Publish(&events.LoginFailed{Time: time.Now(), Reason: ErrBadChallenge})
+		return zero, ErrBadChallenge
+	} else if current.fake {
+		realm.putChallenge(handle, current)
+		realm.bus.Publish(&events.LoginFailed{Time: time.Now(), Identifier: current.attempt.Identifier,
+			Attempt: publishedAttempt(current.attempt), Reason: twofactor.ErrBadCode})
+		return zero, twofactor.ErrBadCode
+	}
+
+	// The credential may have been deactivated or punished
+	// (perhaps through another instance) since the challenge
+	// was issued.
+	if indexedCred, ok := credentials.Credential(current.credential).(indexed.Indexed); ok {
+		if reloaded, found, err := realm.source.LookupByIndex(indexedCred.Index()); err != nil {
+			// No code was attempted, so the challenge is kept.
+			realm.challengesMutex.Lock()
+			realm.challenges[handle] = current
+			realm.challengesMutex.Unlock()
+			return zero, err
+		} else if found {
+			current.credential = reloaded
+		}
+	}
+	var deniedStep login.PipelineStep
+	verified := current.credential
+	err := realm.mutate(current.credential, func(credential T) error {
+		verified = credential
+		if step, err := realm.runChecks(credential, current.attempt); err != nil {
+			deniedStep = step
+			return err
+		}
+		enabled, _ := realm.enabledFactors(credential)
+		for _, factor := range enabled {
+			if err := factor.Verify(credential, code); err != twofactor.ErrBadCode {
+				return err
+			}
+		}
+		return twofactor.ErrBadCode
+	})
+	if err == twofactor.ErrBadCode {
+		realm.recordFailure(verified)
+	}
+	if err != nil {
+		if deniedStep == nil {
+			realm.putChallenge(handle, current)
+		}
+		_ = realm.trackLogin(verified, current.attempt, history.Failed)
+		realm.bus.Publish(&events.LoginFailed{Time: time.Now(), Credential: verified, Attempt: publishedAttempt(current.attempt), Step: deniedStep, Reason: err})
+		return zero, err
+	}
+	realm.clearCodeFailures(current)
+	if err := realm.trackLogin(verified, current.attempt, history.Succeeded); err != nil {
+		return zero, err
+	}
+	realm.bus.Publish(&events.LoginSucceeded{Time: time.Now(), Credential: verified, Attempt: publishedAttempt(current.attempt)})
+	return verified, nil
+}
+
+// Lets the enabled factors record a rejected code, saving the
+// credential. Errors are ignored, since the code was rejected
+// anyway.
+func (realm *TypedRealm[T]) recordFailure(credential T) {
+	enabled, _ := realm.enabledFactors(credential)
+	var recorders []twofactor.FailureRecorder
+	for _, factor := range enabled {
+		if recorder, ok := factor.(twofactor.FailureRecorder); ok {
+			recorders = append(recorders, recorder)
+		}
+	}
+	if len(recorders) > 0 {
+		_ = realm.mutate(credential, func(current T) error {
+			for _, recorder := range recorders {
+				recorder.RecordFailure(current)
+			}
+			return nil
+		})
+	}
+}
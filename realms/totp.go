@@ -0,0 +1,92 @@
+package realms
+
+import (
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"time"
+)
+
+// Error to return when attempting to use TOTP features on a
+// credential that is not TOTP capable.
+var ErrNotTOTPCapable = errors.New("the credential is not a TOTP capable type")
+
+// Error to return when confirming a TOTP enrollment that was
+// not started.
+var ErrNoTOTPEnrollment = errors.New("there is no TOTP enrollment in progress")
+
+// Starts a TOTP enrollment: a new secret is generated and set
+// (not enrolled yet) in the credential, which is saved. Returns
+// the otpauth:// URI to show to the user. The account defaults
+// to the credential's identification, if it is identified. This
+// call is only allowed if the credential is TOTP capable.
+func (realm *TypedRealm[T]) EnrollTOTP(credential T, factor *totp.Factor, account string) (string, error) {
+	if _, ok := credentials.Credential(credential).(otp.TOTPCapable); !ok {
+		return "", ErrNotTOTPCapable
+	}
+	if identifiedCred, ok := credentials.Credential(credential).(identified.Identified); ok && account == "" {
+		account = fmt.Sprint(identifiedCred.Identification())
+	}
+	if secret, err := totp.GenerateSecret(); err != nil {
+		return "", err
+	} else if err := realm.mutate(credential, func(current T) error {
+		capable := credentials.Credential(current).(otp.TOTPCapable)
+		capable.SetTOTPSecret(secret, false)
+		capable.SetTOTPLastStep(0)
+		return nil
+	}); err != nil {
+		return "", err
+	} else {
+		return factor.URI(secret, account), nil
+	}
+}
+
+// Confirms a TOTP enrollment with a code from the authenticator,
+// so the secret becomes enrolled. The credential will be saved
+// after that, and the TOTPEnrolled event published. Returns
+// twofactor.ErrBadCode if the code is not valid. This call is only
+// allowed if the credential is TOTP capable, and an enrollment is
+// in progress.
+func (realm *TypedRealm[T]) ConfirmTOTP(credential T, factor *totp.Factor, code string) error {
+	if _, ok := credentials.Credential(credential).(otp.TOTPCapable); !ok {
+		return ErrNotTOTPCapable
+	}
+	return realm.mutateAndPublish(credential, func(current T) error {
+		capable := credentials.Credential(current).(otp.TOTPCapable)
+		if secret, enrolled := capable.TOTPSecret(); len(secret) == 0 || enrolled {
+			return ErrNoTOTPEnrollment
+		} else if step, ok := factor.Match(secret, code, capable.TOTPLastStep()); !ok {
+			return twofactor.ErrBadCode
+		} else {
+			capable.SetTOTPSecret(secret, true)
+			capable.SetTOTPLastStep(step)
+			return nil
+		}
+	}, func(saved T) events.Event {
+		return &events.TOTPEnrolled{Time: time.Now(), Credential: saved}
+	})
+}
+
+// Removes the TOTP secret of a credential, enrolled or not. The
+// credential will be saved after that, and the TOTPDisabled event
+// published. This call is only allowed if the credential is TOTP
+// capable. The actor (e.g. the credential itself, or an
+// administrator) may be nil.
+func (realm *TypedRealm[T]) DisableTOTP(credential T, by credentials.Credential) error {
+	if _, ok := credentials.Credential(credential).(otp.TOTPCapable); !ok {
+		return ErrNotTOTPCapable
+	}
+	return realm.mutateAndPublish(credential, func(current T) error {
+		capable := credentials.Credential(current).(otp.TOTPCapable)
+		capable.SetTOTPSecret(nil, false)
+		capable.SetTOTPLastStep(0)
+		return nil
+	}, func(saved T) events.Event {
+		return &events.TOTPDisabled{Time: time.Now(), Credential: saved, By: by}
+	})
+}
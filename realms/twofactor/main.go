@@ -0,0 +1,47 @@
+package twofactor
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+)
+
+// Returned when a second factor code is not valid for
+// a credential.
+var ErrBadCode = errors.New("invalid second factor code")
+
+// A second factor verifies codes for a credential after its
+// password was checked. Enabled tells whether the credential
+// has this factor set up. Verify checks the code, and may
+// update the credential (e.g. to prevent replays): the realm
+// saves it afterwards, or rolls the changes back if saving
+// fails. It must return ErrBadCode when the code is invalid.
+type SecondFactor interface {
+	Name() string
+	Enabled(credential credentials.Credential) bool
+	Verify(credential credentials.Credential, code string) error
+}
+
+// Second factors implementing this interface and returning
+// true are fallbacks: they are accepted when a second factor
+// is required, but they do not require it by themselves.
+type Fallback interface {
+	Fallback() bool
+}
+
+// Second factors implementing this interface are challenged
+// when the login of a credential having them enabled requires
+// a second factor (e.g. to send a code to the user). Challenge
+// prepares the challenge in the credential, which the realm
+// saves afterwards, and returns the function delivering it,
+// which the realm invokes once the credential was saved.
+type Challenger interface {
+	Challenge(credential credentials.Credential) (deliver func() error, err error)
+}
+
+// Second factors implementing this interface keep track of the
+// rejected codes in the credential (e.g. to invalidate a code
+// after too many attempts). The realm invokes RecordFailure, and
+// saves the credential, when a code is not accepted.
+type FailureRecorder interface {
+	RecordFailure(credential credentials.Credential)
+}
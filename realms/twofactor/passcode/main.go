@@ -0,0 +1,161 @@
+package passcode
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"math/big"
+	"time"
+)
+
+// The defaults used by NewFactor. They are also used
+// instead of the zero (or negative) settings of any factor.
+const (
+	DefaultDigits      = 6
+	DefaultTTL         = 10 * time.Minute
+	DefaultMaxAttempts = 5
+)
+
+// Panicked when creating a factor with a nil notifier.
+var ErrNilNotifier = errors.New("notifier is nil")
+
+// Notifiers deliver the passcodes to the users (e.g. by email
+// or SMS), telling when they expire.
+type Notifier interface {
+	Notify(credential credentials.Credential, code string, expiresAt time.Time) error
+}
+
+// A second factor sending numeric one-time passcodes through a
+// notifier, for the credentials implementing the trait
+// otp.PasscodeCapable which are enrolled. A new passcode is sent
+// on each challenge, replacing the previous one. It may also be
+// used as the only factor of a realm without a password checking
+// step, for a passwordless login: then Passwordless must be true,
+// so every passcode capable credential counts as enrolled (they
+// would log in without any check otherwise). Now (if not nil)
+// replaces the clock.
+type Factor struct {
+	Notifier     Notifier
+	Digits       int
+	TTL          time.Duration
+	MaxAttempts  int
+	Passwordless bool
+	Now          func() time.Time
+}
+
+// Creates a passcode factor with the default settings.
+func NewFactor(notifier Notifier) *Factor {
+	if notifier == nil {
+		panic(ErrNilNotifier)
+	}
+	return &Factor{Notifier: notifier, Digits: DefaultDigits, TTL: DefaultTTL, MaxAttempts: DefaultMaxAttempts}
+}
+
+func (factor *Factor) now() time.Time {
+	if factor.Now != nil {
+		return factor.Now()
+	} else {
+		return time.Now()
+	}
+}
+
+func (factor *Factor) digits() int {
+	if factor.Digits <= 0 {
+		return DefaultDigits
+	}
+	return factor.Digits
+}
+
+func (factor *Factor) ttl() time.Duration {
+	if factor.TTL <= 0 {
+		return DefaultTTL
+	}
+	return factor.TTL
+}
+
+func (factor *Factor) maxAttempts() int {
+	if factor.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return factor.MaxAttempts
+}
+
+// Generates a random numeric code.
+func (factor *Factor) generate() (string, error) {
+	digits := factor.digits()
+	limit := big.NewInt(1)
+	for index := 0; index < digits; index++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	if value, err := rand.Int(rand.Reader, limit); err != nil {
+		return "", err
+	} else {
+		return fmt.Sprintf("%0*d", digits, value), nil
+	}
+}
+
+// The name of this factor.
+func (factor *Factor) Name() string {
+	return "passcode"
+}
+
+// Tells whether the credential is enrolled to receive passcodes
+// (or just passcode capable, for passwordless logins).
+func (factor *Factor) Enabled(credential credentials.Credential) bool {
+	capable, ok := credential.(otp.PasscodeCapable)
+	return ok && (factor.Passwordless || capable.PasscodeEnrolled())
+}
+
+// Sets a new hashed passcode in the credential, and returns the
+// function sending it through the notifier.
+func (factor *Factor) Challenge(credential credentials.Credential) (func() error, error) {
+	if code, err := factor.generate(); err != nil {
+		return nil, err
+	} else if hashed, err := credential.Hasher().Hash(code); err != nil {
+		return nil, err
+	} else {
+		expiresAt := factor.now().Add(factor.ttl())
+		credential.(otp.PasscodeCapable).SetPasscode(hashed, expiresAt, 0)
+		return func() error {
+			return factor.Notifier.Notify(credential, code, expiresAt)
+		}, nil
+	}
+}
+
+// Verifies a code against the pending passcode, which must not
+// be expired nor out of attempts. The passcode is cleared when
+// accepted, so it is consumed when the credential is saved.
+func (factor *Factor) Verify(credential credentials.Credential, code string) error {
+	if !factor.Enabled(credential) {
+		return twofactor.ErrBadCode
+	}
+	capable := credential.(otp.PasscodeCapable)
+	hashed, expiresAt, attempts := capable.Passcode()
+	if hashed == "" || !factor.now().Before(expiresAt) || attempts >= factor.maxAttempts() {
+		return twofactor.ErrBadCode
+	} else if credential.Hasher().Validate(code, hashed) != nil {
+		return twofactor.ErrBadCode
+	} else {
+		capable.SetPasscode("", time.Time{}, 0)
+		return nil
+	}
+}
+
+// Counts a failed attempt against the pending passcode, which
+// is cleared once it runs out of attempts.
+func (factor *Factor) RecordFailure(credential credentials.Credential) {
+	capable, ok := credential.(otp.PasscodeCapable)
+	if !ok {
+		return
+	}
+	if hashed, expiresAt, attempts := capable.Passcode(); hashed == "" {
+		return
+	} else if attempts+1 >= factor.maxAttempts() {
+		capable.SetPasscode("", time.Time{}, 0)
+	} else {
+		capable.SetPasscode(hashed, expiresAt, attempts+1)
+	}
+}
@@ -0,0 +1,69 @@
+package passcode
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"os"
+	"sync"
+	"time"
+)
+
+// A message written to an outbox file.
+type Message struct {
+	To        string    `json:"to"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// A notifier appending the passcodes, as JSON lines, to an outbox
+// file instead of sending them. Intended for local testing. The
+// recipient is the identification of the credential.
+type FileNotifier struct {
+	Path  string
+	mutex sync.Mutex
+}
+
+// Appends the message to the outbox file.
+func (notifier *FileNotifier) Notify(credential credentials.Credential, code string, expiresAt time.Time) error {
+	message := Message{Code: code, ExpiresAt: expiresAt}
+	if identifiedCred, ok := credential.(identified.Identified); ok {
+		message.To = fmt.Sprint(identifiedCred.Identification())
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+	file, err := os.OpenFile(notifier.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Reads all the messages in an outbox file.
+func ReadOutbox(path string) ([]Message, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		message := Message{}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, scanner.Err()
+}
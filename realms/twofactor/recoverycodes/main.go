@@ -0,0 +1,106 @@
+package recoverycodes
+
+import (
+	"crypto/rand"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"math/big"
+	"strings"
+)
+
+// The default number of generated codes.
+const DefaultCount = 10
+
+// The alphabet of the generated codes, lacking the
+// characters that are easy to confuse (0/o, 1/l/i).
+const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// Generates plain text codes like "abcd-efgh". Each character
+// is uniformly drawn from the alphabet.
+func Generate(count int) ([]string, error) {
+	if count <= 0 {
+		count = DefaultCount
+	}
+	codes := make([]string, count)
+	size := big.NewInt(int64(len(alphabet)))
+	for index := range codes {
+		code := make([]byte, 9)
+		for position := 0; position < 8; position++ {
+			value, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return nil, err
+			}
+			if position < 4 {
+				code[position] = alphabet[value.Int64()]
+			} else {
+				code[position+1] = alphabet[value.Int64()]
+			}
+		}
+		code[4] = '-'
+		codes[index] = string(code)
+	}
+	return codes, nil
+}
+
+// Normalizes a code as typed by the user, so the case, the
+// dashes and the spaces do not matter.
+func Normalize(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// Hashes plain text codes with the given credential's hasher.
+func Hash(credential credentials.Credential, codes []string) ([]string, error) {
+	hashed := make([]string, len(codes))
+	for index, code := range codes {
+		if result, err := credential.Hasher().Hash(Normalize(code)); err != nil {
+			return nil, err
+		} else {
+			hashed[index] = result
+		}
+	}
+	return hashed, nil
+}
+
+// A second factor accepting the one-time recovery codes of the
+// credentials implementing the otp.RecoveryCodesCapable trait.
+// It is a fallback: it does not require a second factor by
+// itself.
+type Factor int
+
+// The name of this factor.
+func (factor Factor) Name() string {
+	return "recovery_code"
+}
+
+// Recovery codes are a fallback factor.
+func (factor Factor) Fallback() bool {
+	return true
+}
+
+// Tells whether the credential has recovery codes left.
+func (factor Factor) Enabled(credential credentials.Credential) bool {
+	capable, ok := credential.(otp.RecoveryCodesCapable)
+	return ok && len(capable.RecoveryCodes()) > 0
+}
+
+// Verifies a code against the hashed ones, removing the matched
+// one (so it is consumed when the credential is saved).
+func (factor Factor) Verify(credential credentials.Credential, code string) error {
+	capable, ok := credential.(otp.RecoveryCodesCapable)
+	if !ok {
+		return twofactor.ErrBadCode
+	}
+	codes := capable.RecoveryCodes()
+	normalized := Normalize(code)
+	for index, hashed := range codes {
+		if credential.Hasher().Validate(normalized, hashed) == nil {
+			remaining := make([]string, 0, len(codes)-1)
+			remaining = append(remaining, codes[:index]...)
+			remaining = append(remaining, codes[index+1:]...)
+			capable.SetRecoveryCodes(remaining)
+			return nil
+		}
+	}
+	return twofactor.ErrBadCode
+}
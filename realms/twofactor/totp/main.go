@@ -0,0 +1,171 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/otp"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"net/url"
+	"time"
+)
+
+// The defaults used by NewFactor, which are also the
+// ones assumed by most authenticator apps. They are also
+// used instead of the zero (or invalid) settings of any
+// factor: periods under a second, and non-positive digits.
+const (
+	DefaultPeriod = 30 * time.Second
+	DefaultDigits = 6
+	DefaultSkew   = 1
+)
+
+// The size, in bytes, of the generated secrets.
+const SecretSize = 20
+
+// A TOTP (RFC 6238, using HMAC-SHA1) second factor, for the
+// credentials implementing the otp.TOTPCapable trait. Skew is
+// the number of time steps tolerated before and after the
+// current one, and Now (if not nil) replaces the clock.
+type Factor struct {
+	Issuer string
+	Period time.Duration
+	Digits int
+	Skew   int
+	Now    func() time.Time
+}
+
+// Creates a TOTP factor with the default settings.
+func NewFactor(issuer string) *Factor {
+	return &Factor{Issuer: issuer, Period: DefaultPeriod, Digits: DefaultDigits, Skew: DefaultSkew}
+}
+
+// Generates a new random secret.
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	} else {
+		return secret, nil
+	}
+}
+
+// Computes the code (RFC 4226) of a secret for a time step.
+// Non-positive digits stand for DefaultDigits.
+func Code(secret []byte, step int64, digits int) string {
+	if digits <= 0 {
+		digits = DefaultDigits
+	}
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	// The modulo stops growing once it exceeds the value,
+	// so it does not overflow for too many digits.
+	modulo := uint64(1)
+	for index := 0; index < digits && modulo <= uint64(value); index++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, uint64(value)%modulo)
+}
+
+// The period in seconds, or the default one if the period is
+// under a second.
+func (factor *Factor) seconds() int64 {
+	if factor.Period < time.Second {
+		return int64(DefaultPeriod / time.Second)
+	}
+	return int64(factor.Period / time.Second)
+}
+
+// The digits, or the default ones if not positive.
+func (factor *Factor) digits() int {
+	if factor.Digits <= 0 {
+		return DefaultDigits
+	}
+	return factor.Digits
+}
+
+// Returns the time step for the given time.
+func (factor *Factor) Step(at time.Time) int64 {
+	return at.Unix() / factor.seconds()
+}
+
+func (factor *Factor) now() time.Time {
+	if factor.Now != nil {
+		return factor.Now()
+	} else {
+		return time.Now()
+	}
+}
+
+// Looks for the time step (among the tolerated ones, and after
+// the given one) matching the code for a secret.
+func (factor *Factor) Match(secret []byte, code string, after int64) (int64, bool) {
+	current := factor.Step(factor.now())
+	for step := current - int64(factor.Skew); step <= current+int64(factor.Skew); step++ {
+		if step > after && subtle.ConstantTimeCompare([]byte(Code(secret, step, factor.digits())), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Renders the otpauth:// URI (usually shown as a QR code) for
+// authenticator apps to enroll a secret.
+func (factor *Factor) URI(secret []byte, account string) string {
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	label := account
+	if factor.Issuer != "" {
+		label = factor.Issuer + ":" + account
+	}
+	query := url.Values{}
+	query.Set("secret", encoded)
+	if factor.Issuer != "" {
+		query.Set("issuer", factor.Issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(factor.digits()))
+	query.Set("period", fmt.Sprint(factor.seconds()))
+	return (&url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}).String()
+}
+
+// The name of this factor.
+func (factor *Factor) Name() string {
+	return "totp"
+}
+
+// Tells whether the credential has an enrolled TOTP secret.
+func (factor *Factor) Enabled(credential credentials.Credential) bool {
+	if capable, ok := credential.(otp.TOTPCapable); !ok {
+		return false
+	} else {
+		secret, enrolled := capable.TOTPSecret()
+		return enrolled && len(secret) > 0
+	}
+}
+
+// Verifies a code against the enrolled secret, rejecting the
+// codes of the last accepted time step (or earlier ones), and
+// records the matched step as the last accepted one.
+func (factor *Factor) Verify(credential credentials.Credential, code string) error {
+	if !factor.Enabled(credential) {
+		return twofactor.ErrBadCode
+	}
+	capable := credential.(otp.TOTPCapable)
+	secret, _ := capable.TOTPSecret()
+	if step, ok := factor.Match(secret, code, capable.TOTPLastStep()); !ok {
+		return twofactor.ErrBadCode
+	} else {
+		capable.SetTOTPLastStep(step)
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package realms
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	webauthntrait "github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/webauthn"
+	"time"
+)
+
+// Error to return when attempting to use WebAuthn features on a
+// credential that is not WebAuthn capable (or is not indexed).
+var ErrNotWebAuthnCapable = errors.New("the credential is not a WebAuthn capable and indexed type")
+
+// Error to return when a registration ceremony was started for
+// another credential.
+var ErrWebAuthnUserMismatch = errors.New("the WebAuthn registration belongs to another credential")
+
+// Error to return when registering an already registered public
+// key credential.
+var ErrDuplicateWebAuthnCredential = errors.New("the WebAuthn public key credential is already registered")
+
+// Returns the opaque WebAuthn user handle of a credential, derived
+// from its type and index.
+func userHandle(credential credentials.Credential) ([]byte, bool) {
+	if _, ok := credential.(webauthntrait.WebAuthnCapable); !ok {
+		return nil, false
+	} else if key, ok := lockKey(credential); !ok {
+		return nil, false
+	} else {
+		sum := sha256.Sum256([]byte(key))
+		return sum[:], true
+	}
+}
+
+// Starts the registration of a new WebAuthn public key credential
+// (e.g. a passkey) for a credential. Returns the options for the
+// browser. This call is only allowed if the credential is WebAuthn
+// capable and indexed.
+func (realm *TypedRealm[T]) BeginWebAuthnRegistration(credential T, relyingParty *webauthn.RelyingParty) (*webauthn.CreationOptions, error) {
+	handle, ok := userHandle(credential)
+	if !ok {
+		return nil, ErrNotWebAuthnCapable
+	}
+	name := fmt.Sprint(credentials.Credential(credential).(indexed.Indexed).Index())
+	if identifiedCred, ok := credentials.Credential(credential).(identified.Identified); ok {
+		name = fmt.Sprint(identifiedCred.Identification())
+	}
+	registered := credentials.Credential(credential).(webauthntrait.WebAuthnCapable).WebAuthnCredentials()
+	return relyingParty.BeginRegistration(webauthn.UserEntity{ID: handle, Name: name, DisplayName: name}, registered)
+}
+
+// Finishes the registration of a WebAuthn public key credential,
+// verifying the response of the browser, and adds it to the
+// credential, which will be saved after that (publishing the
+// WebAuthnRegistered event). This call is only allowed if the
+// credential is WebAuthn capable and indexed, and the registration
+// was started for it.
+func (realm *TypedRealm[T]) FinishWebAuthnRegistration(credential T, relyingParty *webauthn.RelyingParty, response *webauthn.AttestationResponse) (*webauthn.Registration, error) {
+	handle, ok := userHandle(credential)
+	if !ok {
+		return nil, ErrNotWebAuthnCapable
+	}
+	registration, err := relyingParty.FinishRegistration(response)
+	if err != nil {
+		return nil, err
+	} else if !bytes.Equal(registration.UserID, handle) {
+		return nil, ErrWebAuthnUserMismatch
+	}
+	return registration, realm.mutateAndPublish(credential, func(current T) error {
+		capable := credentials.Credential(current).(webauthntrait.WebAuthnCapable)
+		registered := capable.WebAuthnCredentials()
+		for _, existing := range registered {
+			if bytes.Equal(existing.ID, registration.Credential.ID) {
+				return ErrDuplicateWebAuthnCredential
+			}
+		}
+		updated := append(append([]webauthntrait.PublicKeyCredential(nil), registered...), registration.Credential)
+		capable.SetWebAuthnCredentials(updated)
+		return nil
+	}, func(saved T) events.Event {
+		return &events.WebAuthnRegistered{Time: time.Now(), Credential: saved, ID: registration.Credential.ID}
+	})
+}
+
+// Starts a WebAuthn login for an identifier. Returns the options
+// for the browser, allowing the public key credentials registered
+// by the credential, padded with fake ones (see the PadCredentials
+// method of the relying party), so the response does not tell
+// whether the credential exists, nor how many it registered.
+func (realm *TypedRealm[T]) BeginWebAuthnLogin(identifier interface{}, relyingParty *webauthn.RelyingParty) (*webauthn.RequestOptions, error) {
+	var registered []webauthntrait.PublicKeyCredential
+	if credential, found, err := realm.source.LookupByIdentifier(identifier); err != nil {
+		return nil, err
+	} else if capable, ok := credentials.Credential(credential).(webauthntrait.WebAuthnCapable); found && ok {
+		registered = capable.WebAuthnCredentials()
+	}
+	if padded, err := relyingParty.PadCredentials(identifier, registered); err != nil {
+		return nil, err
+	} else {
+		return relyingParty.BeginLogin(identifier, padded)
+	}
+}
+
+// Finishes a WebAuthn login, verifying the response of the browser.
+// Then, the pipeline runs with an empty password (so the realm must
+// not have a password checking step), and the signature counter is
+// checked and updated in the credential, which will be saved after
+// that. Returns ErrLoginFailed if the credential does not exist or
+// the public key credential is not one of its own (alike, so the
+// response does not tell whether it exists), and the WebAuthn errors
+// (e.g. webauthn.ErrSignCountRegression) if the verification fails.
+// The rate limiter and the lockout policy apply like for Login, with
+// failed verifications counting like wrong passwords. Second factors
+// are not required for these logins.
+func (realm *TypedRealm[T]) FinishWebAuthnLogin(relyingParty *webauthn.RelyingParty, response *webauthn.AssertionResponse) (T, error) {
+	return realm.FinishWebAuthnLoginAttempt(relyingParty, response, &login.Attempt{})
+}
+
+// Like FinishWebAuthnLogin, but taking the context of the login
+// attempt, which is given to the rate limiter and to the pipeline
+// steps. The identifier and the password of the attempt are set by
+// this call.
+func (realm *TypedRealm[T]) FinishWebAuthnLoginAttempt(relyingParty *webauthn.RelyingParty, response *webauthn.AssertionResponse, attempt *login.Attempt) (T, error) {
+	var zero T
+	if attempt == nil {
+		panic(ErrNilAttempt)
+	}
+	attempt.Password = ""
+	if attempt.Time.IsZero() {
+		attempt.Time = time.Now()
+	}
+
+	credential, found, step, err := realm.finishWebAuthnLogin(relyingParty, response, attempt)
+	if err != nil {
+		event := &events.LoginFailed{Time: time.Now(), Identifier: attempt.Identifier, Attempt: publishedAttempt(attempt), Step: step, Reason: err}
+		if found {
+			event.Credential = credential
+		}
+		realm.bus.Publish(event)
+		return zero, err
+	}
+	realm.bus.Publish(&events.LoginSucceeded{Time: time.Now(), Credential: credential, Attempt: publishedAttempt(attempt)})
+	return credential, nil
+}
+
+// Verifies a WebAuthn login and runs the pipeline. Returns the
+// credential (if found) even when the login fails, and the
+// pipeline step rejecting the login (if any).
+func (realm *TypedRealm[T]) finishWebAuthnLogin(relyingParty *webauthn.RelyingParty, response *webauthn.AssertionResponse, attempt *login.Attempt) (T, bool, login.PipelineStep, error) {
+	var credential T
+	var rejected error
+	found := false
+	used, err := relyingParty.FinishLogin(response, func(identifier interface{}) ([]webauthntrait.PublicKeyCredential, error) {
+		attempt.Identifier = identifier
+		if realm.limiter != nil {
+			if rejected = realm.limiter.Allow(attempt); rejected != nil {
+				return nil, rejected
+			}
+		}
+		if current, ok, err := realm.source.LookupByIdentifier(identifier); err != nil {
+			rejected = err
+			return nil, err
+		} else if !ok {
+			// No public key credential is known, so the login
+			// fails like for an unknown public key credential.
+			return nil, nil
+		} else if capable, isCapable := credentials.Credential(current).(webauthntrait.WebAuthnCapable); !isCapable {
+			credential, found = current, true
+			return nil, nil
+		} else {
+			credential, found = current, true
+			return capable.WebAuthnCredentials(), nil
+		}
+	})
+	if err == webauthn.ErrUnknownCredential {
+		err = ErrLoginFailed
+	}
+	if rejected != nil || attempt.Identifier == nil {
+		// The rate limiter, the source or the ceremony rejected
+		// the login before looking the credential up.
+		return credential, found, nil, err
+	}
+
+	if !found {
+		if err == ErrLoginFailed && realm.lockout != nil {
+			started := time.Now()
+			if lockedErr, locked := realm.countPhantomFailure(attempt.Identifier, attempt.Time); locked {
+				err = lockedErr
+			}
+			realm.failureCost.pad(started)
+		}
+		return credential, false, nil, err
+	}
+	if realm.lockout != nil {
+		if lockedErr, locked := realm.lockedOut(credential, attempt.Time); locked {
+			started := time.Now()
+			_ = realm.trackLogin(credential, attempt, history.LockedOut)
+			realm.failureCost.observe(credential, started)
+			return credential, true, nil, lockedErr
+		}
+	}
+	if err != nil {
+		// Failed verifications count like wrong passwords.
+		return credential, true, nil, realm.failLogin(credential, attempt, true, err)
+	}
+	if step, err := realm.runSteps(credential, attempt); err != nil {
+		counted, ok := step.(login.CountedStep)
+		return credential, true, step, realm.failLogin(credential, attempt, ok && counted.CountsFailures(), err)
+	}
+
+	if err := realm.mutate(credential, func(current T) error {
+		capable := credentials.Credential(current).(webauthntrait.WebAuthnCapable)
+		registered := append([]webauthntrait.PublicKeyCredential(nil), capable.WebAuthnCredentials()...)
+		for index := range registered {
+			if bytes.Equal(registered[index].ID, used.ID) {
+				// The counter is checked against the current one,
+				// so concurrent logins are checked too.
+				if err := webauthn.CheckSignCount(registered[index].SignCount, used.SignCount); err != nil {
+					return err
+				}
+				registered[index].SignCount = used.SignCount
+			}
+		}
+		capable.SetWebAuthnCredentials(registered)
+		return nil
+	}); err != nil {
+		_ = realm.trackLogin(credential, attempt, history.Failed)
+		return credential, true, nil, err
+	}
+	if realm.lockout != nil {
+		if err := realm.resetFailures(credential); err != nil {
+			return credential, true, nil, err
+		}
+	}
+	if err := realm.trackLogin(credential, attempt, history.Succeeded); err != nil {
+		return credential, true, nil, err
+	}
+	return credential, true, nil, nil
+}
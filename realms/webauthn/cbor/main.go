@@ -0,0 +1,263 @@
+// This package is a minimal CBOR (RFC 8949) codec, supporting the
+// subset used by WebAuthn: integers, byte and text strings, arrays,
+// maps, and the false, true and null simple values. Indefinite
+// lengths, tags and floats are not supported. Decoded integers are
+// int64 values (uint64 values only when they do not fit), and maps
+// are of type map[interface{}]interface{}.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Returned when the input is truncated or malformed.
+var ErrMalformed = errors.New("malformed CBOR data")
+
+// Returned when the input uses unsupported CBOR features.
+var ErrUnsupported = errors.New("unsupported CBOR feature")
+
+const maxLength = 1 << 24
+
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+func (decoder *decoder) take(count uint64) ([]byte, error) {
+	if count > uint64(len(decoder.data)-decoder.offset) {
+		return nil, ErrMalformed
+	}
+	result := decoder.data[decoder.offset : decoder.offset+int(count)]
+	decoder.offset += int(count)
+	return result, nil
+}
+
+func (decoder *decoder) header() (byte, uint64, error) {
+	head, err := decoder.take(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	major, info := head[0]>>5, head[0]&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info <= 27:
+		if raw, err := decoder.take(1 << (info - 24)); err != nil {
+			return 0, 0, err
+		} else {
+			var value uint64
+			for _, b := range raw {
+				value = value<<8 | uint64(b)
+			}
+			return major, value, nil
+		}
+	default:
+		return 0, 0, ErrUnsupported
+	}
+}
+
+func (decoder *decoder) decode(depth int) (interface{}, error) {
+	if depth > 16 {
+		return nil, ErrUnsupported
+	}
+	major, argument, err := decoder.header()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		if argument > 1<<63-1 {
+			return argument, nil
+		}
+		return int64(argument), nil
+	case 1:
+		if argument > 1<<63-1 {
+			return nil, ErrUnsupported
+		}
+		return -1 - int64(argument), nil
+	case 2, 3:
+		if argument > maxLength {
+			return nil, ErrUnsupported
+		}
+		raw, err := decoder.take(argument)
+		if err != nil {
+			return nil, err
+		} else if major == 2 {
+			return append([]byte(nil), raw...), nil
+		} else {
+			return string(raw), nil
+		}
+	case 4:
+		if argument > maxLength {
+			return nil, ErrUnsupported
+		}
+		result := make([]interface{}, 0)
+		for index := uint64(0); index < argument; index++ {
+			if item, err := decoder.decode(depth + 1); err != nil {
+				return nil, err
+			} else {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	case 5:
+		if argument > maxLength {
+			return nil, ErrUnsupported
+		}
+		result := map[interface{}]interface{}{}
+		for index := uint64(0); index < argument; index++ {
+			key, err := decoder.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, ErrUnsupported
+			}
+			if value, err := decoder.decode(depth + 1); err != nil {
+				return nil, err
+			} else {
+				result[key] = value
+			}
+		}
+		return result, nil
+	case 7:
+		switch argument {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		default:
+			return nil, ErrUnsupported
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// Decodes the first CBOR item in the data, and returns it along
+// with the number of bytes it took (there may be more data after
+// it, like in WebAuthn authenticator data).
+func DecodeFirst(data []byte) (interface{}, int, error) {
+	decoder := &decoder{data: data}
+	if value, err := decoder.decode(0); err != nil {
+		return nil, 0, err
+	} else {
+		return value, decoder.offset, nil
+	}
+}
+
+// Decodes a single CBOR item, which must take all the data.
+func Decode(data []byte) (interface{}, error) {
+	if value, length, err := DecodeFirst(data); err != nil {
+		return nil, err
+	} else if length != len(data) {
+		return nil, ErrMalformed
+	} else {
+		return value, nil
+	}
+}
+
+func writeHeader(buffer *bytes.Buffer, major byte, argument uint64) {
+	switch {
+	case argument < 24:
+		buffer.WriteByte(major<<5 | byte(argument))
+	case argument <= 0xff:
+		buffer.Write([]byte{major<<5 | 24, byte(argument)})
+	case argument <= 0xffff:
+		buffer.WriteByte(major<<5 | 25)
+		_ = binary.Write(buffer, binary.BigEndian, uint16(argument))
+	case argument <= 0xffffffff:
+		buffer.WriteByte(major<<5 | 26)
+		_ = binary.Write(buffer, binary.BigEndian, uint32(argument))
+	default:
+		buffer.WriteByte(major<<5 | 27)
+		_ = binary.Write(buffer, binary.BigEndian, argument)
+	}
+}
+
+func encode(buffer *bytes.Buffer, value interface{}) error {
+	switch typed := value.(type) {
+	case nil:
+		buffer.WriteByte(0xf6)
+	case bool:
+		if typed {
+			buffer.WriteByte(0xf5)
+		} else {
+			buffer.WriteByte(0xf4)
+		}
+	case int:
+		return encode(buffer, int64(typed))
+	case int64:
+		if typed >= 0 {
+			writeHeader(buffer, 0, uint64(typed))
+		} else {
+			writeHeader(buffer, 1, uint64(-1-typed))
+		}
+	case uint64:
+		writeHeader(buffer, 0, typed)
+	case []byte:
+		writeHeader(buffer, 2, uint64(len(typed)))
+		buffer.Write(typed)
+	case string:
+		writeHeader(buffer, 3, uint64(len(typed)))
+		buffer.WriteString(typed)
+	case []interface{}:
+		writeHeader(buffer, 4, uint64(len(typed)))
+		for _, item := range typed {
+			if err := encode(buffer, item); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		// Keys are sorted in the canonical order: by the
+		// length of their encoding, and then bytewise.
+		type entry struct {
+			key   []byte
+			value interface{}
+		}
+		entries := make([]entry, 0, len(typed))
+		for key, item := range typed {
+			keyBuffer := &bytes.Buffer{}
+			if err := encode(keyBuffer, key); err != nil {
+				return err
+			}
+			entries = append(entries, entry{keyBuffer.Bytes(), item})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if len(entries[i].key) != len(entries[j].key) {
+				return len(entries[i].key) < len(entries[j].key)
+			}
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		writeHeader(buffer, 5, uint64(len(entries)))
+		for _, item := range entries {
+			buffer.Write(item.key)
+			if err := encode(buffer, item.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: cannot encode %T", ErrUnsupported, value)
+	}
+	return nil
+}
+
+// Encodes a value of the supported types (nil, bool, int, int64,
+// uint64, []byte, string, []interface{} and maps of type
+// map[interface{}]interface{}), sorting the map keys canonically.
+func Encode(value interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := encode(buffer, value); err != nil {
+		return nil, err
+	} else {
+		return buffer.Bytes(), nil
+	}
+}
@@ -0,0 +1,315 @@
+package webauthn
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The supported COSE algorithms.
+const (
+	ES256 = -7
+	EdDSA = -8
+)
+
+// The default timeout of the ceremonies.
+const DefaultTimeout = 5 * time.Minute
+
+// The default size the lists of allowed public key credentials
+// are padded to.
+const DefaultAllowListSize = 4
+
+// Panicked when creating a relying party without origins.
+var ErrNoOrigins = errors.New("at least one origin must be given")
+
+// Returned when the ceremony of a response is unknown, expired,
+// already finished, or of another kind.
+var ErrUnknownCeremony = errors.New("unknown or expired WebAuthn ceremony")
+
+// Bytes are marshalled as unpadded base64url strings, like in
+// the JSON forms of the WebAuthn structures.
+type Bytes []byte
+
+func (value Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(value))
+}
+
+func (value *Bytes) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	} else if decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "=")); err != nil {
+		return err
+	} else {
+		*value = decoded
+		return nil
+	}
+}
+
+// The relying party, as told to the authenticators.
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// The user, as told to the authenticators on registration. The
+// ID is an opaque handle, and not personal information.
+type UserEntity struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// An accepted public key algorithm.
+type Parameter struct {
+	Type      string `json:"type"`
+	Algorithm int    `json:"alg"`
+}
+
+// A reference to a registered public key credential.
+type Descriptor struct {
+	Type string `json:"type"`
+	ID   Bytes  `json:"id"`
+}
+
+// The authenticator requirements on registration.
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// The options to pass to navigator.credentials.create().
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []Parameter            `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	Exclude                []Descriptor           `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// The options to pass to navigator.credentials.get().
+type RequestOptions struct {
+	Challenge        Bytes        `json:"challenge"`
+	Timeout          int64        `json:"timeout"`
+	RelyingPartyID   string       `json:"rpId"`
+	Allow            []Descriptor `json:"allowCredentials"`
+	UserVerification string       `json:"userVerification"`
+}
+
+// The (flattened) result of navigator.credentials.create().
+type AttestationResponse struct {
+	ID                Bytes `json:"rawId"`
+	ClientDataJSON    Bytes `json:"clientDataJSON"`
+	AttestationObject Bytes `json:"attestationObject"`
+}
+
+// The (flattened) result of navigator.credentials.get().
+type AssertionResponse struct {
+	ID                Bytes `json:"rawId"`
+	ClientDataJSON    Bytes `json:"clientDataJSON"`
+	AuthenticatorData Bytes `json:"authenticatorData"`
+	Signature         Bytes `json:"signature"`
+	UserHandle        Bytes `json:"userHandle,omitempty"`
+}
+
+type session struct {
+	registration bool
+	key          interface{}
+	expiresAt    time.Time
+}
+
+// A relying party verifies the WebAuthn ceremonies of a site:
+// its ID (a domain), name and accepted origins (e.g. the URL
+// of the site). It keeps the ceremonies in progress in memory,
+// so they must be finished in the same process. The secret
+// derives the fake public key credentials padding the allowed
+// ones (see PadCredentials): it should be the same in all the
+// processes of the site, and a random one is generated if empty.
+// DefaultTimeout and DefaultAllowListSize are used instead of
+// zero (or negative) settings.
+type RelyingParty struct {
+	ID                      string
+	Name                    string
+	Origins                 []string
+	Timeout                 time.Duration
+	AllowListSize           int
+	RequireUserVerification bool
+	Secret                  []byte
+	mutex                   sync.Mutex
+	sessions                map[string]*session
+}
+
+// Creates a new relying party.
+func NewRelyingParty(id, name string, origins ...string) *RelyingParty {
+	if len(origins) == 0 {
+		panic(ErrNoOrigins)
+	}
+	return &RelyingParty{ID: id, Name: name, Origins: origins, Timeout: DefaultTimeout, AllowListSize: DefaultAllowListSize}
+}
+
+func (relyingParty *RelyingParty) timeout() time.Duration {
+	if relyingParty.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return relyingParty.Timeout
+}
+
+func (relyingParty *RelyingParty) allowListSize() int {
+	if relyingParty.AllowListSize <= 0 {
+		return DefaultAllowListSize
+	}
+	return relyingParty.AllowListSize
+}
+
+func (relyingParty *RelyingParty) userVerification() string {
+	if relyingParty.RequireUserVerification {
+		return "required"
+	} else {
+		return "preferred"
+	}
+}
+
+// Starts a ceremony, returning its random challenge.
+func (relyingParty *RelyingParty) begin(registration bool, key interface{}) ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	relyingParty.mutex.Lock()
+	defer relyingParty.mutex.Unlock()
+	if relyingParty.sessions == nil {
+		relyingParty.sessions = map[string]*session{}
+	}
+	for id, existing := range relyingParty.sessions {
+		if now.After(existing.expiresAt) {
+			delete(relyingParty.sessions, id)
+		}
+	}
+	relyingParty.sessions[string(challenge)] = &session{registration, key, now.Add(relyingParty.timeout())}
+	return challenge, nil
+}
+
+// Finishes a ceremony, given its challenge.
+func (relyingParty *RelyingParty) finish(registration bool, challenge []byte) (interface{}, error) {
+	relyingParty.mutex.Lock()
+	defer relyingParty.mutex.Unlock()
+	if current, ok := relyingParty.sessions[string(challenge)]; !ok || current.registration != registration {
+		return nil, ErrUnknownCeremony
+	} else {
+		delete(relyingParty.sessions, string(challenge))
+		if time.Now().After(current.expiresAt) {
+			return nil, ErrUnknownCeremony
+		}
+		return current.key, nil
+	}
+}
+
+// Pads the public key credentials registered for a key (e.g. an
+// identifier, which may be unknown) with fake ones, up to the next
+// multiple of the allow list size (at least one), and sorts them
+// by ID. So the login options do not tell whether the user exists,
+// nor how many public key credentials were registered (unless more
+// than the allow list size). The fake ones are derived from the
+// secret, so each key gets the same ones each time, and have the
+// lengths of the registered ones (or, when there are none, a length
+// also derived from the secret) so they are not told apart.
+func (relyingParty *RelyingParty) PadCredentials(key interface{}, registered []webauthn.PublicKeyCredential) ([]webauthn.PublicKeyCredential, error) {
+	relyingParty.mutex.Lock()
+	if len(relyingParty.Secret) == 0 {
+		relyingParty.Secret = make([]byte, 32)
+		if _, err := rand.Read(relyingParty.Secret); err != nil {
+			relyingParty.Secret = nil
+			relyingParty.mutex.Unlock()
+			return nil, err
+		}
+	}
+	secret := relyingParty.Secret
+	relyingParty.mutex.Unlock()
+
+	derive := func(index, block int) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(fmt.Sprintf("%d:%d:%v", index, block, key)))
+		return mac.Sum(nil)
+	}
+	// The length of the fake IDs, when there are no registered ones:
+	// either the one of a SHA-256 sum, or its half.
+	length := sha256.Size
+	if derive(-1, 0)[0]%2 == 0 {
+		length /= 2
+	}
+
+	size := relyingParty.allowListSize()
+	padded := append([]webauthn.PublicKeyCredential(nil), registered...)
+	for index := 0; len(padded) == 0 || len(padded)%size != 0; index++ {
+		if len(registered) > 0 {
+			length = len(registered[index%len(registered)].ID)
+		}
+		var id []byte
+		for block := 0; len(id) < length; block++ {
+			id = append(id, derive(index, block)...)
+		}
+		padded = append(padded, webauthn.PublicKeyCredential{ID: id[:length]})
+	}
+	sort.Slice(padded, func(i, j int) bool {
+		return string(padded[i].ID) < string(padded[j].ID)
+	})
+	return padded, nil
+}
+
+func descriptors(registered []webauthn.PublicKeyCredential) []Descriptor {
+	result := make([]Descriptor, len(registered))
+	for index, credential := range registered {
+		result[index] = Descriptor{"public-key", credential.ID}
+	}
+	return result
+}
+
+// Starts a registration ceremony for a user, excluding the public
+// key credentials that are already registered.
+func (relyingParty *RelyingParty) BeginRegistration(user UserEntity, registered []webauthn.PublicKeyCredential) (*CreationOptions, error) {
+	if challenge, err := relyingParty.begin(true, string(user.ID)); err != nil {
+		return nil, err
+	} else {
+		return &CreationOptions{
+			Challenge:              challenge,
+			RelyingParty:           RelyingPartyEntity{relyingParty.ID, relyingParty.Name},
+			User:                   user,
+			Parameters:             []Parameter{{"public-key", ES256}, {"public-key", EdDSA}},
+			Timeout:                relyingParty.timeout().Milliseconds(),
+			Exclude:                descriptors(registered),
+			AuthenticatorSelection: AuthenticatorSelection{"preferred", relyingParty.userVerification()},
+			Attestation:            "direct",
+		}, nil
+	}
+}
+
+// Starts an authentication ceremony, keyed by an arbitrary value
+// (e.g. the identifier of the user), allowing the given public key
+// credentials.
+func (relyingParty *RelyingParty) BeginLogin(key interface{}, registered []webauthn.PublicKeyCredential) (*RequestOptions, error) {
+	if challenge, err := relyingParty.begin(false, key); err != nil {
+		return nil, err
+	} else {
+		return &RequestOptions{
+			Challenge:        challenge,
+			Timeout:          relyingParty.timeout().Milliseconds(),
+			RelyingPartyID:   relyingParty.ID,
+			Allow:            descriptors(registered),
+			UserVerification: relyingParty.userVerification(),
+		}, nil
+	}
+}
@@ -0,0 +1,386 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms/webauthn/cbor"
+	"math/big"
+	"strings"
+)
+
+// Returned when the client data is malformed, or of another
+// kind of ceremony.
+var ErrBadClientData = errors.New("invalid WebAuthn client data")
+
+// Returned when the origin of the client data is not accepted.
+var ErrBadOrigin = errors.New("the WebAuthn origin is not accepted")
+
+// Returned when the authenticator data is malformed.
+var ErrBadAuthenticatorData = errors.New("invalid WebAuthn authenticator data")
+
+// Returned when the authenticator data is for another relying party.
+var ErrBadRelyingParty = errors.New("the WebAuthn relying party does not match")
+
+// Returned when the authenticator did not check the user presence.
+var ErrUserNotPresent = errors.New("the WebAuthn user presence was not checked")
+
+// Returned when the authenticator did not verify the user, and the
+// relying party requires it.
+var ErrUserNotVerified = errors.New("the WebAuthn user was not verified")
+
+// Returned when the attestation object is malformed or invalid.
+var ErrBadAttestation = errors.New("invalid WebAuthn attestation")
+
+// Returned when the attestation format is not "none" or "packed".
+var ErrUnsupportedAttestation = errors.New("unsupported WebAuthn attestation format")
+
+// Returned when the public key is malformed, or its algorithm is
+// not ES256 (on P-256) or EdDSA (on Ed25519).
+var ErrUnsupportedPublicKey = errors.New("unsupported or invalid WebAuthn public key")
+
+// Returned when a signature is not valid.
+var ErrBadSignature = errors.New("invalid WebAuthn signature")
+
+// Returned when an assertion uses a public key credential that is
+// not registered for the user.
+var ErrUnknownCredential = errors.New("unknown WebAuthn public key credential")
+
+// Returned when the signature counter of an assertion did not
+// increase, which suggests that the authenticator was cloned.
+var ErrSignCountRegression = errors.New("the WebAuthn signature counter did not increase")
+
+// The flags of the authenticator data.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+	flagExtensions   = 0x80
+)
+
+// The extension telling the AAGUID in attestation certificates.
+var aaguidExtension = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+
+// The result of a registration ceremony. Certificates are the
+// attestation certificates of a "packed" attestation: they are
+// only checked to sign it, so their trust must be checked by the
+// caller if needed (there are none for self attestation, nor for
+// the "none" format).
+type Registration struct {
+	Credential   webauthn.PublicKeyCredential
+	UserID       []byte
+	Format       string
+	AAGUID       []byte
+	Certificates []*x509.Certificate
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// Checks the client data of a ceremony, returning its challenge.
+func (relyingParty *RelyingParty) checkClientData(raw []byte, kind string) ([]byte, error) {
+	data := clientData{}
+	if err := json.Unmarshal(raw, &data); err != nil || data.Type != kind {
+		return nil, ErrBadClientData
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil {
+		return nil, ErrBadClientData
+	}
+	for _, origin := range relyingParty.Origins {
+		if origin == data.Origin {
+			return challenge, nil
+		}
+	}
+	return nil, ErrBadOrigin
+}
+
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, ErrBadAuthenticatorData
+	}
+	data := &authenticatorData{rpIDHash: raw[:32], flags: raw[32], signCount: binary.BigEndian.Uint32(raw[33:37])}
+	offset := 37
+	if data.flags&flagAttested != 0 {
+		if len(raw) < offset+18 {
+			return nil, ErrBadAuthenticatorData
+		}
+		data.aaguid = raw[offset : offset+16]
+		length := int(binary.BigEndian.Uint16(raw[offset+16 : offset+18]))
+		offset += 18
+		if len(raw) < offset+length {
+			return nil, ErrBadAuthenticatorData
+		}
+		data.credentialID = raw[offset : offset+length]
+		offset += length
+		if _, keyLength, err := cbor.DecodeFirst(raw[offset:]); err != nil {
+			return nil, ErrBadAuthenticatorData
+		} else {
+			data.publicKey = raw[offset : offset+keyLength]
+			offset += keyLength
+		}
+	}
+	if data.flags&flagExtensions != 0 {
+		if _, length, err := cbor.DecodeFirst(raw[offset:]); err != nil {
+			return nil, ErrBadAuthenticatorData
+		} else {
+			offset += length
+		}
+	}
+	if offset != len(raw) {
+		return nil, ErrBadAuthenticatorData
+	}
+	return data, nil
+}
+
+func (relyingParty *RelyingParty) checkAuthenticatorData(data *authenticatorData) error {
+	expected := sha256.Sum256([]byte(relyingParty.ID))
+	if subtle.ConstantTimeCompare(expected[:], data.rpIDHash) != 1 {
+		return ErrBadRelyingParty
+	} else if data.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	} else if relyingParty.RequireUserVerification && data.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	} else {
+		return nil
+	}
+}
+
+// Parses a COSE public key, returning its algorithm and key.
+func parsePublicKey(raw []byte) (int64, crypto.PublicKey, error) {
+	decoded, err := cbor.Decode(raw)
+	if err != nil {
+		return 0, nil, ErrUnsupportedPublicKey
+	}
+	fields, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return 0, nil, ErrUnsupportedPublicKey
+	}
+	keyType, _ := fields[int64(1)].(int64)
+	algorithm, _ := fields[int64(3)].(int64)
+	curve, _ := fields[int64(-1)].(int64)
+	x, _ := fields[int64(-2)].([]byte)
+	switch {
+	case keyType == 2 && algorithm == ES256 && curve == 1 && len(x) == 32:
+		y, _ := fields[int64(-3)].([]byte)
+		if len(y) != 32 {
+			return 0, nil, ErrUnsupportedPublicKey
+		}
+		pointX, pointY := new(big.Int).SetBytes(x), new(big.Int).SetBytes(y)
+		if !elliptic.P256().IsOnCurve(pointX, pointY) {
+			return 0, nil, ErrUnsupportedPublicKey
+		}
+		return algorithm, &ecdsa.PublicKey{Curve: elliptic.P256(), X: pointX, Y: pointY}, nil
+	case keyType == 1 && algorithm == EdDSA && curve == 6 && len(x) == ed25519.PublicKeySize:
+		return algorithm, ed25519.PublicKey(x), nil
+	default:
+		return 0, nil, ErrUnsupportedPublicKey
+	}
+}
+
+func verifySignature(algorithm int64, key crypto.PublicKey, data, signature []byte) error {
+	switch typed := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if algorithm == ES256 && ecdsa.VerifyASN1(typed, digest[:], signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if algorithm == EdDSA && ed25519.Verify(typed, data, signature) {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
+
+// Verifies a "packed" attestation statement.
+func verifyPacked(statement map[interface{}]interface{}, data *authenticatorData, signed []byte,
+	algorithm int64, key crypto.PublicKey) ([]*x509.Certificate, error) {
+	statementAlgorithm, _ := statement["alg"].(int64)
+	signature, _ := statement["sig"].([]byte)
+	if chain, ok := statement["x5c"].([]interface{}); !ok {
+		// Self attestation: signed by the credential itself.
+		if statementAlgorithm != algorithm {
+			return nil, ErrBadAttestation
+		}
+		return nil, verifySignature(algorithm, key, signed, signature)
+	} else if len(chain) == 0 {
+		return nil, ErrBadAttestation
+	} else {
+		certificates := make([]*x509.Certificate, len(chain))
+		for index, item := range chain {
+			if raw, ok := item.([]byte); !ok {
+				return nil, ErrBadAttestation
+			} else if certificate, err := x509.ParseCertificate(raw); err != nil {
+				return nil, ErrBadAttestation
+			} else {
+				certificates[index] = certificate
+			}
+		}
+		leaf := certificates[0]
+		if leaf.Version != 3 || leaf.IsCA {
+			return nil, ErrBadAttestation
+		}
+		for _, extension := range leaf.Extensions {
+			if extension.Id.Equal(aaguidExtension) {
+				var aaguid []byte
+				if _, err := asn1.Unmarshal(extension.Value, &aaguid); err != nil || !bytes.Equal(aaguid, data.aaguid) {
+					return nil, ErrBadAttestation
+				}
+			}
+		}
+		return certificates, verifySignature(statementAlgorithm, leaf.PublicKey, signed, signature)
+	}
+}
+
+// Returns the data signed in attestations and assertions.
+func signedData(authenticatorData, clientDataJSON []byte) []byte {
+	digest := sha256.Sum256(clientDataJSON)
+	return append(append([]byte(nil), authenticatorData...), digest[:]...)
+}
+
+// Finishes a registration ceremony, verifying the response. The
+// ceremony is finished even if the verification fails.
+func (relyingParty *RelyingParty) FinishRegistration(response *AttestationResponse) (*Registration, error) {
+	challenge, err := relyingParty.checkClientData(response.ClientDataJSON, "webauthn.create")
+	if err != nil {
+		return nil, err
+	}
+	userID, err := relyingParty.finish(true, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := cbor.Decode(response.AttestationObject)
+	if err != nil {
+		return nil, ErrBadAttestation
+	}
+	object, _ := decoded.(map[interface{}]interface{})
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[interface{}]interface{})
+	rawData, _ := object["authData"].([]byte)
+	if statement == nil || rawData == nil {
+		return nil, ErrBadAttestation
+	}
+	data, err := parseAuthenticatorData(rawData)
+	if err != nil {
+		return nil, err
+	} else if err := relyingParty.checkAuthenticatorData(data); err != nil {
+		return nil, err
+	} else if data.flags&flagAttested == 0 || len(data.credentialID) == 0 {
+		return nil, ErrBadAttestation
+	} else if len(response.ID) > 0 && !bytes.Equal(response.ID, data.credentialID) {
+		return nil, ErrBadAttestation
+	}
+	algorithm, key, err := parsePublicKey(data.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	registration := &Registration{
+		Credential: webauthn.PublicKeyCredential{
+			ID:        append([]byte(nil), data.credentialID...),
+			PublicKey: append([]byte(nil), data.publicKey...),
+			SignCount: data.signCount,
+		},
+		UserID: []byte(userID.(string)),
+		Format: format,
+		AAGUID: append([]byte(nil), data.aaguid...),
+	}
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return nil, ErrBadAttestation
+		}
+	case "packed":
+		if registration.Certificates, err = verifyPacked(statement, data, signedData(rawData, response.ClientDataJSON), algorithm, key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedAttestation
+	}
+	return registration, nil
+}
+
+// Finishes an authentication ceremony, verifying the response. The
+// lookup function takes the key given when the ceremony began, and
+// returns the public key credentials registered for it. On success,
+// the used public key credential is returned, with the signature
+// counter reported by the authenticator: the caller must check it
+// against the stored one via CheckSignCount (when storing it, so
+// concurrent logins are checked too). The ceremony is finished even
+// if the verification fails.
+func (relyingParty *RelyingParty) FinishLogin(response *AssertionResponse, lookup func(key interface{}) ([]webauthn.PublicKeyCredential, error)) (webauthn.PublicKeyCredential, error) {
+	var used webauthn.PublicKeyCredential
+	challenge, err := relyingParty.checkClientData(response.ClientDataJSON, "webauthn.get")
+	if err != nil {
+		return used, err
+	}
+	key, err := relyingParty.finish(false, challenge)
+	if err != nil {
+		return used, err
+	}
+	registered, err := lookup(key)
+	if err != nil {
+		return used, err
+	}
+	found := false
+	for _, candidate := range registered {
+		if bytes.Equal(candidate.ID, response.ID) {
+			used, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return used, ErrUnknownCredential
+	}
+
+	data, err := parseAuthenticatorData(response.AuthenticatorData)
+	if err != nil {
+		return used, err
+	} else if err := relyingParty.checkAuthenticatorData(data); err != nil {
+		return used, err
+	}
+	algorithm, publicKey, err := parsePublicKey(used.PublicKey)
+	if err != nil {
+		return used, err
+	} else if err := verifySignature(algorithm, publicKey, signedData(response.AuthenticatorData, response.ClientDataJSON), response.Signature); err != nil {
+		return used, err
+	}
+	used.SignCount = data.signCount
+	return used, nil
+}
+
+// Checks the signature counter reported by an authenticator against
+// the stored one, which must be lower. Authenticators not implementing
+// the counter always report 0. Returns ErrSignCountRegression otherwise
+// (e.g. for cloned authenticators, or replayed logins).
+func CheckSignCount(stored, reported uint32) error {
+	if (reported != 0 || stored != 0) && reported <= stored {
+		return ErrSignCountRegression
+	}
+	return nil
+}
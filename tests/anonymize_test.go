@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/tagged"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login/activity"
+	"github.com/universe-10th/identity/realms/login/password"
+	"testing"
+	"time"
+)
+
+func TestAnonymize(t *testing.T) {
+	hashed, _ := DummyHasher(0).Hash("user1$123")
+	broker := NewMemoryBroker().Put(&User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hashed}})
+	userRealm := realms.NewRealm(credentials.NewSource(broker, &User{}), activity.ActivityStep(0), password.PasswordCheckingStep(0))
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	record, err := userRealm.Anonymize(credential, nil)
+	if err != nil {
+		t.Fatalf("Anonymizing must not fail. Error: %s\n", err)
+	}
+	if record.Index != 1 || len(record.Erased) != 5 || record.Tombstone == nil || record.Tombstone == "U1" {
+		t.Errorf("Unexpected erasure record: %+v\n", record)
+	}
+
+	user := credential.(*User)
+	if user.hashedPassword != "" || user.recoveryToken != "" || user.active || user.identifier != record.Tombstone {
+		t.Errorf("The credential must be scrubbed. Got: %+v\n", user.BaseUser)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for an anonymized user must fail with realm.ErrLoginFailed. Error: %s\n", err)
+	}
+	if byIndex, _ := userRealm.ByIndex(1); byIndex != credential {
+		t.Error("The anonymized credential must keep its index")
+	}
+}
+
+func TestAnonymizeScrubsEveryTrait(t *testing.T) {
+	userRealm, _ := MakeHistoryExampleInstances()
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	now := time.Now()
+	credential.SetTOTPSecret([]byte("secret"), true)
+	credential.SetTOTPLastStep(10)
+	credential.SetRecoveryCodes([]string{"code"})
+	credential.SetPasscode("passcode", now.Add(time.Minute), 1)
+	credential.SetWebAuthnCredentials([]webauthn.PublicKeyCredential{{ID: []byte("passkey")}})
+	credential.SetFailedLogins(2, now)
+	credential.SetLoginHistory(now, now, []history.Entry{{Time: now, ClientIP: "127.0.0.1"}})
+	credential.Punish(nil, "spam", nil)
+
+	record, err := userRealm.Anonymize(credential, "tombstone")
+	if err != nil {
+		t.Fatalf("Anonymizing must not fail. Error: %s\n", err)
+	}
+	if len(record.Erased) != 11 {
+		t.Errorf("Every known trait must be erased. Got: %v\n", record.Erased)
+	}
+	if secret, enrolled := credential.TOTPSecret(); secret != nil || enrolled || credential.TOTPLastStep() != 0 {
+		t.Error("The TOTP secret must be erased")
+	}
+	if hashed, _, _ := credential.Passcode(); hashed != "" || credential.RecoveryCodes() != nil {
+		t.Error("The passcode and recovery codes must be erased")
+	}
+	if credential.WebAuthnCredentials() != nil || credential.LoginHistory() != nil || !credential.LastLogin().IsZero() {
+		t.Error("The passkeys and login history must be erased")
+	}
+	if count, _ := credential.FailedLogins(); count != 0 {
+		t.Error("The failed logins must be erased")
+	}
+	if punishedOn, _, _, _ := credential.PunishedFor(); punishedOn != nil {
+		t.Error("The punishment must be erased")
+	}
+
+	published := recorder.Take()
+	if len(published) != 1 {
+		t.Fatalf("Anonymizing must publish an event. Got: %d\n", len(published))
+	}
+	if anonymized, ok := published[0].(*events.Anonymized); !ok || anonymized.Credential != credential ||
+		anonymized.Tombstone != "tombstone" || len(anonymized.Erased) != len(record.Erased) {
+		t.Errorf("Anonymizing must publish Anonymized. Got: %#v\n", published[0])
+	}
+}
+
+func TestAnonymizeRollback(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	broker.Err = errSaveFailed
+	if _, err := userRealm.Anonymize(credential, "tombstone"); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %s\n", err)
+	}
+	if user := credential.(*User); user.identifier != "U1" || !user.active || user.hashedPassword == "" {
+		t.Errorf("The credential must be restored when the save fails. Got: %+v\n", user.BaseUser)
+	}
+}
+
+func TestAnonymizeTagged(t *testing.T) {
+	hashed, _ := DummyHasher(0).Hash("tagged$123")
+	user := &TaggedUser{Login: "T1", Password: hashed, Enabled: true}
+	factory := func() credentials.Credential {
+		return tagged.MustWrap(&TaggedUser{}, DummyHasher(0))
+	}
+	taggedRealm := realms.NewTypedRealm(credentials.NewTypedSource(&taggedBroker{stored: tagged.MustWrap(user, DummyHasher(0))}, factory))
+	credential, _ := taggedRealm.ByIdentifier("T1")
+	if _, err := taggedRealm.Anonymize(credential, "tombstone"); err != nil {
+		t.Fatalf("Anonymizing a tagged credential must not fail. Error: %s\n", err)
+	}
+	if user.Login != "tombstone" || user.Password != "" || user.Enabled {
+		t.Errorf("The tagged fields must be scrubbed. Got: %+v\n", user)
+	}
+}
+
+// A tagged user with no identifier field.
+type IndexedTaggedUser struct {
+	ID       int    `identity:"index"`
+	Password string `identity:"password"`
+}
+
+func TestAnonymizeNotReidentifiable(t *testing.T) {
+	factory := func() credentials.Credential {
+		return tagged.MustWrap(&IndexedTaggedUser{ID: 1}, DummyHasher(0))
+	}
+	indexedRealm := realms.NewTypedRealm(credentials.NewTypedSource(NewMemoryBroker(factory()), factory))
+	if _, err := indexedRealm.Anonymize(factory(), "tombstone"); err != realms.ErrNotAnonymizable {
+		t.Errorf("Anonymizing a non-reidentifiable credential must fail with realm.ErrNotAnonymizable. Error: %s\n", err)
+	}
+}
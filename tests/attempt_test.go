@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/login/password"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptContext(t *testing.T) {
+	step := &RecordingStep{}
+	userRealm := MakeAttemptExampleInstances(step)
+
+	attempt := &login.Attempt{
+		Identifier: "U1", Password: "user1$123", ClientIP: net.ParseIP("192.0.2.10"),
+		UserAgent: "TestAgent/1.0", DeviceID: "device-1", Realm: "users",
+	}
+	if _, err := userRealm.LoginAttempt(attempt); err != nil {
+		t.Fatalf("Login for user U1 must succeed. Error: %s\n", err)
+	}
+	if len(step.Attempts) != 1 {
+		t.Fatalf("The step must receive one attempt. Got: %d\n", len(step.Attempts))
+	}
+	received := step.Attempts[0]
+	if received.Identifier != "U1" || !received.ClientIP.Equal(attempt.ClientIP) || received.UserAgent != "TestAgent/1.0" ||
+		received.DeviceID != "device-1" || received.Realm != "users" {
+		t.Errorf("The step must receive the attempt context. Got: %v\n", received)
+	}
+	if received.Time.IsZero() || time.Since(received.Time) > time.Minute {
+		t.Errorf("The time of the attempt must be set. Got: %s\n", received.Time)
+	}
+}
+
+func TestLoginAttemptRejections(t *testing.T) {
+	_, blocked, _ := net.ParseCIDR("198.51.100.0/24")
+	step := &RecordingStep{Blocked: blocked}
+	userRealm := MakeAttemptExampleInstances(step)
+
+	attempt := login.NewAttempt("U1", "user1$123")
+	attempt.ClientIP = net.ParseIP("198.51.100.7")
+	if _, err := userRealm.LoginAttempt(attempt); err != ErrBlockedNetwork {
+		t.Errorf("Login from a blocked network must fail with ErrBlockedNetwork. Error: %v\n", err)
+	}
+
+	// Legacy steps still run before: a bad password is rejected
+	// by them, and unknown users still run every step.
+	step.Attempts = nil
+	attempt = login.NewAttempt("U1", "user1$124")
+	if _, err := userRealm.LoginAttempt(attempt); err != realms.ErrLoginFailed || len(step.Attempts) != 0 {
+		t.Errorf("Login with a bad password must fail in the password step. Error: %v\n", err)
+	}
+	if _, err := userRealm.LoginAttempt(login.NewAttempt("U9", "user9$123")); err != realms.ErrLoginFailed || len(step.Attempts) != 1 {
+		t.Errorf("Login of an unknown user must fail and run the steps. Error: %v\n", err)
+	}
+
+	// Plain logins are attempts with no context.
+	step.Attempts = nil
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil || len(step.Attempts) != 1 || step.Attempts[0].ClientIP != nil {
+		t.Errorf("Login must run the steps with a context-less attempt. Error: %v\n", err)
+	}
+}
+
+func TestAdaptPipelineSteps(t *testing.T) {
+	step := &RecordingStep{}
+	if login.Adapt(step) != login.AttemptStep(step) {
+		t.Error("Attempt steps must be adapted as they are")
+	}
+	_, sampleRealms := MakeUserExampleInstances()
+	credential, _ := sampleRealms[1].ByIdentifier("U1")
+	adapted := login.Adapt(password.PasswordCheckingStep(0))
+	if err := adapted.LoginAttempt(credential, login.NewAttempt("U1", "user1$123")); err != nil {
+		t.Errorf("Adapted steps must receive the password of the attempt. Error: %s\n", err)
+	}
+	if err := adapted.LoginAttempt(credential, login.NewAttempt("U1", "user1$124")); err != realms.ErrLoginFailed {
+		t.Errorf("Adapted steps must reject a bad password. Error: %v\n", err)
+	}
+}
@@ -0,0 +1,345 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/universe-10th/identity/audit"
+	"github.com/universe-10th/identity/realms/lockout"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"github.com/universe-10th/identity/realms/webauthn"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var auditKey = []byte("audit-key")
+
+// Makes a log with the given actions, for credential U1.
+func makeAuditLog(t *testing.T, actions ...string) string {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("The log must be created. Error: %s\n", err)
+	}
+	defer log.Close()
+	_, sampleRealms := MakeUserExampleInstances()
+	credential, _ := sampleRealms[1].ByIdentifier("U1")
+	auditor := audit.NewAuditor(log)
+	for _, action := range actions {
+		if err := auditor.Record("admin", action, credential, map[string]string{"scope": "scope2"}); err != nil {
+			t.Fatalf("The action must be recorded. Error: %s\n", err)
+		}
+	}
+	return path
+}
+
+// Tells the reason of a verification error.
+func verificationReason(err error) (uint64, error) {
+	var verificationErr *audit.VerificationError
+	if errors.As(err, &verificationErr) {
+		return verificationErr.Sequence, verificationErr.Err
+	}
+	return 0, err
+}
+
+func TestAuditRealmEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("The log must be created. Error: %s\n", err)
+	}
+	defer log.Close()
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+	auditor := audit.NewAuditor(log)
+	userRealm.Subscribe(auditor.Handle)
+
+	start := time.Now()
+	admin, _ := sampleRealms[0].ByIdentifier("S1")
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U1", "user1$124")
+	_, _ = userRealm.Login("U9", "user9$123")
+	_ = userRealm.ChangePassword(credential, "user1$123", "user1$456")
+	_ = userRealm.PreparePasswordReset(credential, "secret-token", time.Hour, nil)
+	_ = userRealm.Punish(credential, nil, "spam", admin)
+	_ = userRealm.Unpunish(credential, admin)
+	_ = userRealm.SetActive(credential, false, admin)
+	_ = auditor.Record(audit.Actor(admin), audit.ScopeGranted, credential, map[string]string{"scope": "scope2"})
+
+	if log.Head().Sequence != 9 {
+		t.Errorf("Every operation must be recorded. Head: %v\n", log.Head())
+	}
+	records, err := log.Query(audit.Filter{Index: "1", From: start})
+	if err != nil || len(records) != 8 {
+		t.Fatalf("The records of U1 must be queried. Records: %d, error: %v\n", len(records), err)
+	}
+	expected := []string{
+		audit.LoginSucceeded, audit.LoginFailed, audit.PasswordChanged, audit.ResetPrepared,
+		audit.Punished, audit.Unpunished, audit.ActivationChanged, audit.ScopeGranted,
+	}
+	for index, record := range records {
+		if record.Action != expected[index] {
+			t.Errorf("Record %d must be %s. Got: %s\n", index, expected[index], record.Action)
+		}
+	}
+	if records[0].Actor != audit.Actor(credential) || records[4].Actor != audit.Actor(admin) || records[4].Details["reason"] != "spam" {
+		t.Errorf("The actors must be recorded. Got: %q and %q\n", records[0].Actor, records[4].Actor)
+	}
+	if records[2].Actor != audit.Actor(credential) || records[3].Actor != "" || records[5].Actor != audit.Actor(admin) || records[6].Actor != audit.Actor(admin) {
+		t.Errorf("The actors given to the realm must be recorded. Got: %q, %q, %q and %q\n", records[2].Actor, records[3].Actor, records[5].Actor, records[6].Actor)
+	}
+	if records[1].Details["step"] != "password.PasswordCheckingStep" || records[6].Details["active"] != "false" {
+		t.Errorf("The details must be recorded. Got: %v and %v\n", records[1].Details, records[6].Details)
+	}
+
+	if unknown, _ := log.Query(audit.Filter{Actions: []string{audit.LoginFailed}}); len(unknown) != 2 || unknown[1].Identifier != "U9" || unknown[1].Index != "" {
+		t.Errorf("Failed logins of unknown users must be recorded by identifier. Got: %v\n", unknown)
+	}
+	if later, _ := log.Query(audit.Filter{From: time.Now().Add(time.Minute)}); len(later) != 0 {
+		t.Errorf("The time range must be applied. Got: %d\n", len(later))
+	}
+	if content, _ := os.ReadFile(path); bytes.Contains(content, []byte("secret-token")) {
+		t.Error("Reset tokens must not be recorded")
+	}
+}
+
+func TestAuditSecurityEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("The log must be created. Error: %s\n", err)
+	}
+	defer log.Close()
+	policy := lockout.NewPolicy(2, time.Hour)
+	policy.Punish = true
+	userRealm, _ := MakeLockoutExampleInstances(policy)
+	relyingParty := webauthn.NewRelyingParty("example.com", "Example", "https://example.com")
+	userRealm.Subscribe(audit.NewAuditor(log).Handle)
+
+	factor := totp.NewFactor("Example")
+	credential, _ := userRealm.ByIdentifier("U1")
+	_, _ = userRealm.EnrollTOTP(credential, factor, "")
+	secret, _ := credential.TOTPSecret()
+	_ = userRealm.ConfirmTOTP(credential, factor, totp.Code(secret, factor.Step(time.Now()), totp.DefaultDigits))
+	codes, _ := userRealm.RegenerateRecoveryCodes(credential, 2)
+	registerWebAuthn(t, userRealm, relyingParty, NewSoftwareAuthenticator(webauthn.ES256, "none"), "U1")
+	_ = userRealm.DisableTOTP(credential, credential)
+	_, _ = userRealm.Login("U1", "user1$124")
+	_, _ = userRealm.Login("U1", "user1$124")
+	credential, _ = userRealm.ByIdentifier("U1")
+	_, _ = userRealm.Anonymize(credential, "tombstone")
+
+	records, err := log.Query(audit.Filter{Actions: []string{
+		audit.TOTPEnrolled, audit.TOTPDisabled, audit.RecoveryCodesGenerated, audit.WebAuthnRegistered,
+		audit.Punished, audit.Anonymized,
+	}})
+	if err != nil || len(records) != 6 {
+		t.Fatalf("Every security change must be recorded. Records: %d, error: %v\n", len(records), err)
+	}
+	expected := []string{
+		audit.TOTPEnrolled, audit.RecoveryCodesGenerated, audit.WebAuthnRegistered, audit.TOTPDisabled,
+		audit.Punished, audit.Anonymized,
+	}
+	for index, record := range records {
+		if record.Action != expected[index] {
+			t.Errorf("Record %d must be %s. Got: %s\n", index, expected[index], record.Action)
+		}
+	}
+	if records[0].Actor != audit.Actor(credential) || records[1].Details["count"] != "2" || records[2].Details["id"] == "" {
+		t.Errorf("The details must be recorded. Got: %v, %v and %v\n", records[0], records[1].Details, records[2].Details)
+	}
+	if records[3].Actor != audit.Actor(credential) {
+		t.Errorf("The actor disabling TOTP must be recorded. Got: %q\n", records[3].Actor)
+	}
+	if records[4].Actor != "" || records[4].Details["reason"] != lockout.TooManyFailures {
+		t.Errorf("Lockout punishments must be recorded without actor. Got: %v\n", records[4])
+	}
+	if records[5].Identifier != "tombstone" || records[5].Details["erased"] == "" {
+		t.Errorf("Anonymizations must record the erased data. Got: %v\n", records[5])
+	}
+	if content, _ := os.ReadFile(path); bytes.Contains(content, []byte(codes[0])) {
+		t.Error("Recovery codes must not be recorded")
+	}
+}
+
+func TestAuditScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("The log must be created. Error: %s\n", err)
+	}
+	defer log.Close()
+	_, sampleRealms := MakeUserExampleInstances()
+	adminRealm := sampleRealms[0]
+	adminRealm.Subscribe(audit.NewAuditor(log).Handle)
+
+	granter, _ := adminRealm.ByIdentifier("SU")
+	credential, _ := adminRealm.ByIdentifier("S1")
+	_ = adminRealm.GrantScope(credential, DummyScope(5), granter)
+	_ = adminRealm.RevokeScope(credential, DummyScope(2).Key(), granter)
+	records, _ := log.Query(audit.Filter{Index: "1"})
+	if len(records) != 2 || records[0].Action != audit.ScopeGranted || records[1].Action != audit.ScopeRevoked {
+		t.Fatalf("Scope changes must be recorded. Got: %v\n", records)
+	}
+	if records[0].Actor != audit.Actor(granter) || records[0].Details["scope"] != DummyScope(5).Key() ||
+		records[1].Details["scope"] != DummyScope(2).Key() {
+		t.Errorf("The granter and scopes must be recorded. Got: %v\n", records)
+	}
+}
+
+func TestAuditVerification(t *testing.T) {
+	path := makeAuditLog(t, audit.ScopeGranted, audit.ScopeRevoked, audit.ScopeGranted)
+	if head, err := audit.VerifyFile(path, auditKey); err != nil || head.Sequence != 3 {
+		t.Fatalf("A valid log must be verified. Head: %v, error: %v\n", head, err)
+	}
+	if _, err := audit.VerifyFile(path, []byte("other-key")); err == nil {
+		t.Error("A log must not be verified with another key")
+	}
+
+	// Appending after reopening continues the chain.
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("A valid log must be reopened. Error: %s\n", err)
+	}
+	if record, err := log.Append(audit.Record{Action: audit.ScopeRevoked}); err != nil || record.Sequence != 4 {
+		t.Errorf("The record must follow the existing ones. Record: %v, error: %v\n", record, err)
+	}
+	log.Close()
+	if _, err := audit.VerifyFile(path, auditKey); err != nil {
+		t.Errorf("The reopened log must be verified. Error: %s\n", err)
+	}
+}
+
+func TestAuditMissingHead(t *testing.T) {
+	path := makeAuditLog(t, audit.ScopeGranted, audit.ScopeRevoked)
+	if err := os.Remove(path + audit.HeadSuffix); err != nil {
+		t.Fatalf("The head must be removed. Error: %s\n", err)
+	}
+	if _, err := audit.VerifyFile(path, auditKey); err != audit.ErrHeadMissing {
+		t.Errorf("A log without its head must fail with audit.ErrHeadMissing. Error: %v\n", err)
+	}
+	if _, err := audit.OpenFile(path, auditKey); err != audit.ErrHeadMissing {
+		t.Errorf("A log without its head must not be opened. Error: %v\n", err)
+	}
+
+	// Empty logs need no head.
+	empty := filepath.Join(t.TempDir(), "audit.jsonl")
+	_ = os.WriteFile(empty, nil, 0600)
+	if _, err := audit.VerifyFile(empty, auditKey); err != nil {
+		t.Errorf("An empty log must be verified without its head. Error: %s\n", err)
+	}
+}
+
+func TestAuditFailedAppend(t *testing.T) {
+	path := makeAuditLog(t, audit.ScopeGranted)
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("A valid log must be reopened. Error: %s\n", err)
+	}
+	defer log.Close()
+
+	// Writing the head fails while its temporary file is taken.
+	temporary := path + audit.HeadSuffix + ".tmp"
+	_ = os.Mkdir(temporary, 0700)
+	if _, err := log.Append(audit.Record{Action: audit.ScopeRevoked}); err == nil {
+		t.Fatal("Appending must fail when the head cannot be written")
+	}
+	if head, err := audit.VerifyFile(path, auditKey); err != nil || head.Sequence != 1 {
+		t.Errorf("The failed record must be truncated. Head: %v, error: %v\n", head, err)
+	}
+
+	_ = os.Remove(temporary)
+	if record, err := log.Append(audit.Record{Action: audit.ScopeRevoked}); err != nil || record.Sequence != 2 {
+		t.Errorf("The log must be appended to after a failure. Record: %v, error: %v\n", record, err)
+	}
+	if head, err := audit.VerifyFile(path, auditKey); err != nil || head.Sequence != 2 {
+		t.Errorf("The log must be verified after a failure. Head: %v, error: %v\n", head, err)
+	}
+}
+
+func TestAuditUnusable(t *testing.T) {
+	path := makeAuditLog(t, audit.ScopeGranted)
+	log, err := audit.OpenFile(path, auditKey)
+	if err != nil {
+		t.Fatalf("A valid log must be reopened. Error: %s\n", err)
+	}
+
+	// Neither writing nor truncating a closed file works.
+	_ = log.Close()
+	if _, err := log.Append(audit.Record{Action: audit.ScopeRevoked}); !errors.Is(err, audit.ErrUnusable) {
+		t.Errorf("Appending must fail with ErrUnusable when the log cannot be truncated. Got: %v\n", err)
+	}
+	if _, err := log.Append(audit.Record{Action: audit.ScopeRevoked}); !errors.Is(err, audit.ErrUnusable) {
+		t.Errorf("An unusable log must refuse later appends. Got: %v\n", err)
+	}
+	if head, err := audit.VerifyFile(path, auditKey); err != nil || head.Sequence != 1 {
+		t.Errorf("The log must be kept as it was. Head: %v, error: %v\n", head, err)
+	}
+}
+
+// Rewrites the lines of a log.
+func rewriteAuditLog(t *testing.T, path string, rewrite func(lines []string) []string) {
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if err := os.WriteFile(path, []byte(strings.Join(rewrite(lines), "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("The log must be rewritten. Error: %s\n", err)
+	}
+}
+
+func TestAuditTampering(t *testing.T) {
+	cases := map[string]struct {
+		rewrite  func(lines []string) []string
+		sequence uint64
+		reason   error
+	}{
+		"modified": {func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], "scope2", "scope7", 1)
+			return lines
+		}, 2, audit.ErrModified},
+		"removed": {func(lines []string) []string {
+			return append(lines[:1:1], lines[2:]...)
+		}, 2, audit.ErrBrokenChain},
+		"reordered": {func(lines []string) []string {
+			lines[0], lines[1] = lines[1], lines[0]
+			return lines
+		}, 1, audit.ErrBrokenChain},
+		"truncated": {func(lines []string) []string {
+			return lines[:2]
+		}, 3, audit.ErrTruncated},
+		"malformed": {func(lines []string) []string {
+			lines[2] = lines[2][:len(lines[2])/2]
+			return lines
+		}, 3, audit.ErrMalformed},
+	}
+	for name, current := range cases {
+		path := makeAuditLog(t, audit.ScopeGranted, audit.ScopeRevoked, audit.ScopeGranted)
+		rewriteAuditLog(t, path, current.rewrite)
+		if sequence, reason := verificationReason(func() error { _, err := audit.VerifyFile(path, auditKey); return err }()); sequence != current.sequence || reason != current.reason {
+			t.Errorf("A %s log must fail on record %d with %v. Got: %d, %v\n", name, current.sequence, current.reason, sequence, reason)
+		}
+		if _, err := audit.OpenFile(path, auditKey); err == nil {
+			t.Errorf("A %s log must not be opened\n", name)
+		}
+	}
+}
+
+func TestAuditForgery(t *testing.T) {
+	path := makeAuditLog(t, audit.ScopeGranted, audit.ScopeRevoked)
+	// Rewriting a record and recomputing its hash without the
+	// key is detected.
+	rewriteAuditLog(t, path, func(lines []string) []string {
+		records, _ := audit.Query(strings.NewReader(strings.Join(lines, "\n")), auditKey, audit.Filter{})
+		forged := records[1]
+		forged.Details = map[string]string{"scope": "scope7"}
+		forged.Hash = audit.ComputeHash(forged, nil)
+		encoded, _ := json.Marshal(forged)
+		lines[1] = string(encoded)
+		return lines
+	})
+	if _, reason := verificationReason(func() error { _, err := audit.VerifyFile(path, auditKey); return err }()); reason != audit.ErrModified {
+		t.Errorf("A forged record must fail with audit.ErrModified. Got: %v\n", reason)
+	}
+}
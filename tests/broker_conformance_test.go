@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials/brokers/cached"
+	"github.com/universe-10th/identity/credentials/brokertest"
+	"testing"
+	"time"
+)
+
+func TestConformance(t *testing.T) {
+	copying := func(broker *MemoryBroker) *MemoryBroker {
+		broker.Copies = true
+		return broker
+	}
+	memoryUser := func() *MemoryBroker {
+		return copying(NewMemoryBroker().Put(&User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true}}))
+	}
+	_, versionedBroker := MakeVersionedExampleInstances()
+
+	for _, test := range []struct {
+		name   string
+		config brokertest.Config
+	}{
+		{"ExampleBroker", brokertest.Config{
+			Broker: copying(MakeUserExampleBroker()), Template: &User{}, Foreign: &VersionedUser{},
+			Identifier: "U1", Unknown: "U9", UnknownIndex: 9,
+		}},
+		{"ExampleBrokerAdmins", brokertest.Config{
+			Broker: copying(MakeUserExampleBroker()), Template: &Admin{}, Foreign: &VersionedUser{},
+			Identifier: "S1", Unknown: "S9", UnknownIndex: 9,
+		}},
+		{"MemoryBroker", brokertest.Config{
+			Broker: memoryUser(), Template: &User{}, Foreign: &Admin{},
+			Identifier: "U1", Unknown: "U9", UnknownIndex: 9,
+		}},
+		{"VersionedBroker", brokertest.Config{
+			Broker: versionedBroker, Template: &VersionedUser{}, Foreign: &User{},
+			Identifier: "U1", Unknown: "U9", UnknownIndex: 9,
+		}},
+		{"CachedBroker", brokertest.Config{
+			Broker:   cached.NewBroker(memoryUser(), cached.Options{TTL: time.Hour, NegativeTTL: time.Hour}),
+			Template: &User{}, Identifier: "U1", Unknown: "U9", UnknownIndex: 9,
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			brokertest.Run(t, test.config)
+		})
+	}
+}
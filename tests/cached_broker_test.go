@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/brokers/cached"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/realms"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCachedLookups(t *testing.T) {
+	userRealm, counting, _ := MakeCachedExampleInstances(cached.Options{TTL: time.Hour})
+
+	_, _ = userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U1", "user1$123")
+	if counting.ByIdentifierCalls != 1 {
+		t.Errorf("The second lookup by identifier must hit the cache. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+
+	// The credential is also cached by its index.
+	if credential, _ := userRealm.ByIndex(1); credential == nil {
+		t.Error("The credential must be found by its index")
+	} else if counting.ByIndexCalls != 0 {
+		t.Errorf("The lookup by index must hit the cache. Calls: %d\n", counting.ByIndexCalls)
+	}
+}
+
+func TestCachedExpiration(t *testing.T) {
+	userRealm, counting, _ := MakeCachedExampleInstances(cached.Options{TTL: 50 * time.Millisecond})
+
+	_, _ = userRealm.ByIdentifier("U1")
+	time.Sleep(100 * time.Millisecond)
+	_, _ = userRealm.ByIdentifier("U1")
+	if counting.ByIdentifierCalls != 2 {
+		t.Errorf("An expired entry must be loaded again. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+}
+
+func TestCachedNegativeResults(t *testing.T) {
+	userRealm, counting, _ := MakeCachedExampleInstances(cached.Options{TTL: time.Hour})
+
+	_, _ = userRealm.ByIdentifier("U9")
+	_, _ = userRealm.ByIdentifier("U9")
+	if counting.ByIdentifierCalls != 2 {
+		t.Errorf("Negative results must not be cached when NegativeTTL is 0. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+
+	userRealm, counting, _ = MakeCachedExampleInstances(cached.Options{TTL: time.Hour, NegativeTTL: time.Hour})
+	_, _ = userRealm.ByIdentifier("U9")
+	if credential, err := userRealm.ByIdentifier("U9"); credential != nil || err != nil {
+		t.Errorf("A cached negative result must be (nil, nil). Got: %v, %v\n", credential, err)
+	} else if counting.ByIdentifierCalls != 1 {
+		t.Errorf("Negative results must be cached when NegativeTTL > 0. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+}
+
+func TestCachedSizeLimit(t *testing.T) {
+	userRealm, counting, _ := MakeCachedExampleInstances(cached.Options{TTL: time.Hour, MaxEntries: 2})
+
+	// Each credential takes two entries: by identifier and by index.
+	_, _ = userRealm.ByIdentifier("U1")
+	_, _ = userRealm.ByIdentifier("U3")
+	_, _ = userRealm.ByIdentifier("U1")
+	if counting.ByIdentifierCalls != 3 {
+		t.Errorf("Entries beyond the size limit must be evicted. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+}
+
+func TestCachedSaveInvalidation(t *testing.T) {
+	var invalidations []cached.Invalidation
+	userRealm, counting, cachedBroker := MakeCachedExampleInstances(cached.Options{
+		TTL: time.Hour,
+		OnInvalidate: func(invalidation cached.Invalidation) {
+			invalidations = append(invalidations, invalidation)
+		},
+	})
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_ = userRealm.SetPassword(credential, "user1$456", nil)
+	_, _ = userRealm.ByIdentifier("U1")
+	_, _ = userRealm.ByIndex(1)
+	if counting.ByIdentifierCalls != 2 || counting.ByIndexCalls != 0 {
+		t.Errorf("Saving must invalidate both keys. Calls by identifier: %d, by index: %d\n",
+			counting.ByIdentifierCalls, counting.ByIndexCalls)
+	}
+
+	if len(invalidations) != 1 {
+		t.Fatalf("Saving must trigger the invalidation hook once. Triggered: %d\n", len(invalidations))
+	} else if invalidations[0].Identifier != "U1" || invalidations[0].Index != 1 ||
+		invalidations[0].Type != reflect.TypeOf(credential) {
+		t.Errorf("Unexpected invalidation: %+v\n", invalidations[0])
+	}
+
+	// External invalidations do not trigger the hook.
+	cachedBroker.Invalidate(invalidations[0])
+	_, _ = userRealm.ByIdentifier("U1")
+	if counting.ByIdentifierCalls != 3 || len(invalidations) != 1 {
+		t.Errorf("External invalidations must remove entries without triggering the hook. Calls: %d\n",
+			counting.ByIdentifierCalls)
+	}
+}
+
+func TestCachedRenameInvalidation(t *testing.T) {
+	userRealm, counting, _ := MakeCachedExampleInstances(cached.Options{TTL: time.Hour})
+	counting.Copies = true
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	if _, err := userRealm.Anonymize(credential, "tombstone"); err != nil {
+		t.Fatalf("Anonymizing must not fail. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != realms.ErrLoginFailed {
+		t.Errorf("The entry by the former identifier must be invalidated. Error: %v\n", err)
+	} else if counting.ByIdentifierCalls != 2 {
+		t.Errorf("The former identifier must be loaded again. Calls: %d\n", counting.ByIdentifierCalls)
+	}
+}
+
+func TestCachedCopies(t *testing.T) {
+	userRealm, _, _ := MakeCachedExampleInstances(cached.Options{TTL: time.Hour})
+
+	first, _ := userRealm.ByIdentifier("U1")
+	second, _ := userRealm.ByIdentifier("U1")
+	if first == second {
+		t.Fatal("Each lookup must return its own copy of the cached credential")
+	}
+	first.SetHashedPassword("changed")
+	if third, _ := userRealm.ByIdentifier("U1"); third.HashedPassword() == "changed" {
+		t.Error("Unsaved changes to a returned credential must not affect the cache")
+	}
+}
+
+func TestCachedCopiesTraitData(t *testing.T) {
+	cachedBroker := cached.NewBroker(MakeUserExampleBroker(), cached.Options{TTL: time.Hour})
+	adminRealm := realms.NewRealm(credentials.NewSource(cachedBroker, &Admin{}))
+
+	first, _ := adminRealm.ByIdentifier("S1")
+	first.(scoped.Scoped).Scopes()[DummyScope(5).Key()] = DummyScope(5)
+	if second, _ := adminRealm.ByIdentifier("S1"); len(second.(scoped.Scoped).Scopes()) != 2 {
+		t.Errorf("Changes to the scopes of a returned credential must not affect the cache. Got: %v\n",
+			second.(scoped.Scoped).Scopes())
+	}
+}
+
+// A broker pausing each lookup by identifier after loading,
+// until told to resume.
+type stallingBroker struct {
+	*MemoryBroker
+	loaded chan struct{}
+	resume chan struct{}
+}
+
+func (broker *stallingBroker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	credential, err := broker.MemoryBroker.ByIdentifier(identifier, template)
+	broker.loaded <- struct{}{}
+	<-broker.resume
+	return credential, err
+}
+
+func TestCachedLookupRacingSave(t *testing.T) {
+	counting := MakeUserExampleBroker()
+	counting.Copies = true
+	stalling := &stallingBroker{counting, make(chan struct{}), make(chan struct{})}
+	cachedBroker := cached.NewBroker(stalling, cached.Options{TTL: time.Hour})
+	userRealm := realms.NewRealm(credentials.NewSource(cachedBroker, &User{}))
+
+	done := make(chan credentials.Credential)
+	go func() {
+		stale, _ := userRealm.ByIdentifier("U1")
+		done <- stale
+	}()
+	<-stalling.loaded
+
+	// The save happens after the load, but before the result
+	// is cached: that result must not be cached.
+	credential, _ := counting.ByIdentifier("U1", &User{})
+	credential.SetHashedPassword("changed")
+	if err := cachedBroker.Save(credential); err != nil {
+		t.Fatalf("Saving must not fail. Error: %s\n", err)
+	}
+	stalling.resume <- struct{}{}
+	<-done
+
+	go func() {
+		<-stalling.loaded
+		stalling.resume <- struct{}{}
+	}()
+	if fresh, _ := userRealm.ByIdentifier("U1"); fresh.HashedPassword() != "changed" {
+		t.Error("A lookup racing with a save must not cache the stale credential")
+	}
+}
@@ -0,0 +1,57 @@
+// Code generated by identity-gen. DO NOT EDIT.
+
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/hashing"
+	"reflect"
+	"time"
+)
+
+func (c *ConcreteUser) HashedPassword() string {
+	return c.Password
+}
+
+func (c *ConcreteUser) SetHashedPassword(password string) {
+	c.Password = password
+}
+
+func (c *ConcreteUser) Hasher() hashing.HashingEngine {
+	return DummyHasher(0)
+}
+
+func (c *ConcreteUser) PunishedFor() (punishedOn *time.Time, forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	punishedOn = c.BannedOn
+	forTime = c.BannedFor
+	if !reflect.ValueOf(&c.BanReason).Elem().IsZero() {
+		reason = c.BanReason
+	}
+	if c.BannedBy != nil {
+		by = c.BannedBy
+	}
+	return
+}
+
+func (c *ConcreteUser) Punish(forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	now := time.Now()
+	c.BannedOn = &now
+	c.BannedFor = nil
+	if forTime != nil {
+		duration := *forTime
+		c.BannedFor = &duration
+	}
+	c.BanReason, _ = reason.(string)
+	c.BannedBy, _ = by.(*User)
+}
+
+func (c *ConcreteUser) Unpunish() {
+	c.BannedOn = nil
+	c.BannedFor = nil
+	c.BanReason = *new(string)
+	c.BannedBy = nil
+}
+
+func (c *ConcreteUser) Identification() interface{} {
+	return c.Login
+}
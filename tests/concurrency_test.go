@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/realms"
+	"testing"
+	"time"
+)
+
+func TestConcurrentModificationWithoutRetries(t *testing.T) {
+	userRealm, _ := MakeVersionedExampleInstances()
+
+	first, _ := userRealm.ByIdentifier("U1")
+	second, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.SetPassword(first, "user1$456", nil); err != nil {
+		t.Fatalf("The first save must succeed. Error: %s\n", err)
+	}
+	if err := userRealm.PreparePasswordReset(second, "abc123", time.Hour, nil); err != credentials.ErrConcurrentModification {
+		t.Errorf("Saving a stale credential must fail with credentials.ErrConcurrentModification. Error: %s\n", err)
+	}
+}
+
+func TestConcurrentModificationWithRetries(t *testing.T) {
+	userRealm, _ := MakeVersionedExampleInstances()
+	userRealm.SetRetries(1)
+
+	first, _ := userRealm.ByIdentifier("U1")
+	second, _ := userRealm.ByIdentifier("U1")
+	_ = userRealm.SetPassword(first, "user1$456", nil)
+	if err := userRealm.PreparePasswordReset(second, "abc123", time.Hour, nil); err != nil {
+		t.Fatalf("Saving a stale credential must succeed after reloading it. Error: %s\n", err)
+	}
+
+	// Both changes must be kept.
+	if credential, err := userRealm.Login("U1", "user1$456"); err != nil {
+		t.Errorf("The password change must be kept. Error: %s\n", err)
+	} else if token := credential.(recoverable.Recoverable).RecoveryToken(); token != "abc123" {
+		t.Errorf("The recovery token must be kept. Token: %s\n", token)
+	}
+}
+
+func TestConcurrentModificationRechecksToken(t *testing.T) {
+	userRealm, _ := MakeVersionedExampleInstances()
+	userRealm.SetRetries(1)
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	first, _ := userRealm.ByIdentifier("U1")
+	second, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.ConfirmPasswordReset(first, "abc123", "user1$456"); err != nil {
+		t.Fatalf("The first password reset must succeed. Error: %s\n", err)
+	}
+	if err := userRealm.ConfirmPasswordReset(second, "abc123", "user1$789"); err != realms.ErrBadToken {
+		t.Errorf("The token must not be redeemed twice. Error: %s\n", err)
+	}
+}
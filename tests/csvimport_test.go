@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials/csvimport"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const csvUsers = `identifier,password,active,scopes
+U1,$2b$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW,true,read write
+U2,5f4dcc3b5aa765d61d8327deb882cf99,false,
+U3,plain:secret,,
+U4,md5:5f4dcc3b5aa765d61d8327deb882cf99
+,nobody,true
+U5,x,maybe
+U6,a,true,read,extra
+U1,plain:other
+`
+
+func TestCSVImport(t *testing.T) {
+	importer, broker := MakeCSVImportExampleInstances(false)
+	report, err := importer.Import(strings.NewReader(csvUsers))
+	if err != nil {
+		t.Fatalf("The import must not fail as a whole. Error: %s\n", err)
+	}
+	if report.Created != 4 {
+		t.Errorf("Expected 4 created credentials. Got: %d\n", report.Created)
+	}
+
+	expected := map[string]string{
+		"U1": "bcrypt:$2b$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW",
+		"U2": "md5:5f4dcc3b5aa765d61d8327deb882cf99",
+		"U3": "Hashed[secret, 0]",
+		"U4": "md5:5f4dcc3b5aa765d61d8327deb882cf99",
+	}
+	for identifier, hash := range expected {
+		if credential, _ := broker.ByIdentifier(identifier, &User{}); credential == nil {
+			t.Errorf("The credential %s must be created\n", identifier)
+		} else if credential.HashedPassword() != hash {
+			t.Errorf("Unexpected hash for %s: %s\n", identifier, credential.HashedPassword())
+		}
+	}
+	if credential, _ := broker.ByIdentifier("U2", &User{}); credential != nil && credential.(*User).Active() {
+		t.Error("The credential U2 must be inactive")
+	}
+
+	lines := []int{6, 7, 8, 9}
+	if len(report.Errors) != len(lines) {
+		t.Fatalf("Expected %d row errors. Got: %v\n", len(lines), report.Errors)
+	}
+	for position, rowError := range report.Errors {
+		if rowError.Line != lines[position] {
+			t.Errorf("Expected a row error on line %d. Got: %s\n", lines[position], rowError)
+		}
+	}
+	if !errors.Is(report.Errors[0], csvimport.ErrEmptyIdentifier) {
+		t.Errorf("Expected csvimport.ErrEmptyIdentifier. Got: %s\n", report.Errors[0])
+	}
+	if !errors.Is(report.Errors[2], csvimport.ErrBadColumns) {
+		t.Errorf("Expected csvimport.ErrBadColumns. Got: %s\n", report.Errors[2])
+	}
+}
+
+func TestCSVImportRejectingPlaintext(t *testing.T) {
+	importer, broker := MakeCSVImportExampleInstances(true)
+	report, _ := importer.Import(strings.NewReader("U1,plain:secret\nU3,5f4dcc3b5aa765d61d8327deb882cf99\n"))
+	if report.Created != 1 || !broker.Has("U3") {
+		t.Errorf("Only the hashed password must be imported. Created: %d\n", report.Created)
+	}
+	for _, rowError := range report.Errors {
+		if !errors.Is(rowError, csvimport.ErrPlaintextPassword) {
+			t.Errorf("Expected csvimport.ErrPlaintextPassword. Got: %s\n", rowError)
+		}
+	}
+}
+
+func TestCSVHashedPassword(t *testing.T) {
+	custom := csvimport.Format{Name: "dummy[0]", Pattern: regexp.MustCompile(`^Hashed\[.*, 0\]$`)}
+	if hashed, _ := csvimport.HashedPassword("Hashed[secret, 0]", []csvimport.Format{custom}, nil); hashed != "dummy[0]:Hashed[secret, 0]" {
+		t.Errorf("Custom formats must be detected. Got: %s\n", hashed)
+	}
+	if hashed, _ := csvimport.HashedPassword("plain:5f4dcc3b5aa765d61d8327deb882cf99", nil, DummyHasher(1)); hashed != "Hashed[5f4dcc3b5aa765d61d8327deb882cf99, 1]" {
+		t.Errorf("Prefixed plain text passwords must be hashed. Got: %s\n", hashed)
+	}
+	if hashed, err := csvimport.HashedPassword("", nil, nil); hashed != "" || err != nil {
+		t.Errorf("Empty passwords must be kept empty. Got: %s, %v\n", hashed, err)
+	}
+	for _, password := range []string{"secret", "{SSHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", "md5:secret", "deadbeef"} {
+		if _, err := csvimport.HashedPassword(password, nil, DummyHasher(1)); err != csvimport.ErrUnknownFormat {
+			t.Errorf("Unprefixed passwords in unknown formats must fail with csvimport.ErrUnknownFormat. Got: %v for %s\n", err, password)
+		}
+	}
+	if hashed, _ := csvimport.HashedPassword("sha1:5f4dcc3b5aa765d61d8327deb882cf99aaaaaaaa", nil, nil); hashed != "sha1:5f4dcc3b5aa765d61d8327deb882cf99aaaaaaaa" {
+		t.Errorf("Prefixed hashes must be kept as they are. Got: %s\n", hashed)
+	}
+}
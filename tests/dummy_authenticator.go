@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/universe-10th/identity/realms/webauthn"
+	"github.com/universe-10th/identity/realms/webauthn/cbor"
+	"math/big"
+	"time"
+)
+
+// A software WebAuthn authenticator, to run the ceremonies without
+// a browser. Format is "none", "packed" (self attestation) or
+// "packed-x5c" (with a self-signed attestation certificate).
+type SoftwareAuthenticator struct {
+	Origin    string
+	RPID      string
+	Algorithm int64
+	Format    string
+	Counter   uint32
+	AAGUID    []byte
+	keys      map[string]crypto.Signer
+}
+
+func NewSoftwareAuthenticator(algorithm int64, format string) *SoftwareAuthenticator {
+	return &SoftwareAuthenticator{
+		Origin: "https://example.com", RPID: "example.com", Algorithm: algorithm, Format: format,
+		AAGUID: []byte("0123456789abcdef"), keys: map[string]crypto.Signer{},
+	}
+}
+
+func (authenticator *SoftwareAuthenticator) clientData(kind string, challenge []byte) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type": kind, "challenge": base64.RawURLEncoding.EncodeToString(challenge), "origin": authenticator.Origin,
+	})
+	return data
+}
+
+func (authenticator *SoftwareAuthenticator) authenticatorData(flags byte, attested []byte) []byte {
+	hash := sha256.Sum256([]byte(authenticator.RPID))
+	counter := make([]byte, 4)
+	binary.BigEndian.PutUint32(counter, authenticator.Counter)
+	return append(append(append(hash[:], flags), counter...), attested...)
+}
+
+func sign(key crypto.Signer, data []byte) []byte {
+	if edKey, ok := key.(ed25519.PrivateKey); ok {
+		return ed25519.Sign(edKey, data)
+	}
+	digest := sha256.Sum256(data)
+	signature, _ := ecdsa.SignASN1(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+	return signature
+}
+
+func signedData(authenticatorData, clientData []byte) []byte {
+	digest := sha256.Sum256(clientData)
+	return append(append([]byte(nil), authenticatorData...), digest[:]...)
+}
+
+func (authenticator *SoftwareAuthenticator) Register(options *webauthn.CreationOptions) *webauthn.AttestationResponse {
+	var key crypto.Signer
+	var coseKey map[interface{}]interface{}
+	if authenticator.Algorithm == webauthn.EdDSA {
+		public, private, _ := ed25519.GenerateKey(rand.Reader)
+		key = private
+		coseKey = map[interface{}]interface{}{1: 1, 3: webauthn.EdDSA, -1: 6, -2: []byte(public)}
+	} else {
+		private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		key = private
+		x, y := make([]byte, 32), make([]byte, 32)
+		private.PublicKey.X.FillBytes(x)
+		private.PublicKey.Y.FillBytes(y)
+		coseKey = map[interface{}]interface{}{1: 2, 3: webauthn.ES256, -1: 1, -2: x, -3: y}
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	authenticator.keys[string(id)] = key
+
+	encodedKey, _ := cbor.Encode(coseKey)
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(id)))
+	attested := append(append(append(append([]byte(nil), authenticator.AAGUID...), length...), id...), encodedKey...)
+	authData := authenticator.authenticatorData(0x41, attested)
+	clientData := authenticator.clientData("webauthn.create", options.Challenge)
+
+	statement := map[interface{}]interface{}{}
+	format := authenticator.Format
+	switch format {
+	case "packed":
+		statement["alg"] = authenticator.Algorithm
+		statement["sig"] = sign(key, signedData(authData, clientData))
+	case "packed-x5c":
+		format = "packed"
+		attestationKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		aaguid, _ := asn1.Marshal(authenticator.AAGUID)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "Software Authenticator"},
+			NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour), BasicConstraintsValid: true,
+			ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}, Value: aaguid}},
+		}
+		certificate, _ := x509.CreateCertificate(rand.Reader, template, template, &attestationKey.PublicKey, attestationKey)
+		statement["alg"] = webauthn.ES256
+		statement["sig"] = sign(attestationKey, signedData(authData, clientData))
+		statement["x5c"] = []interface{}{certificate}
+	}
+	object, _ := cbor.Encode(map[interface{}]interface{}{"fmt": format, "attStmt": statement, "authData": authData})
+	return &webauthn.AttestationResponse{ID: id, ClientDataJSON: clientData, AttestationObject: object}
+}
+
+func (authenticator *SoftwareAuthenticator) Login(options *webauthn.RequestOptions, id []byte) *webauthn.AssertionResponse {
+	authenticator.Counter++
+	authData := authenticator.authenticatorData(0x01, nil)
+	clientData := authenticator.clientData("webauthn.get", options.Challenge)
+	signature := sign(authenticator.keys[string(id)], signedData(authData, clientData))
+	return &webauthn.AssertionResponse{ID: id, ClientDataJSON: clientData, AuthenticatorData: authData, Signature: signature}
+}
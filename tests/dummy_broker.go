@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/filtering"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/indexed"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// An in-memory broker, configurable to behave like the
+// different stores the features are tested against. The
+// credentials are kept by type and index, and must be
+// identified and indexed (by an int).
+type MemoryBroker struct {
+	// Stores and returns copies of the credentials, so each
+	// lookup returns a different instance (like a database).
+	// Versioned credentials are only checked in this mode.
+	Copies bool
+	// Delays each save, to test concurrent ones.
+	Delay time.Duration
+	// When not nil, each save, creation and deletion fails with it.
+	Err error
+
+	ByIdentifierCalls int
+	ByIndexCalls      int
+	SaveCalls         int
+	MaxInFlight       int
+
+	mutex    sync.Mutex
+	inFlight int
+	data     map[reflect.Type]map[int]credentials.Credential
+}
+
+// Creates a memory broker allowing the types of the given
+// templates.
+func NewMemoryBroker(templates ...credentials.Credential) *MemoryBroker {
+	broker := &MemoryBroker{data: map[reflect.Type]map[int]credentials.Credential{}}
+	for _, template := range templates {
+		broker.data[reflect.TypeOf(template)] = map[int]credentials.Credential{}
+	}
+	return broker
+}
+
+// Stores the credentials, allowing their types.
+func (broker *MemoryBroker) Put(stored ...credentials.Credential) *MemoryBroker {
+	for _, credential := range stored {
+		credentialType := reflect.TypeOf(credential)
+		if broker.data[credentialType] == nil {
+			broker.data[credentialType] = map[int]credentials.Credential{}
+		}
+		broker.data[credentialType][indexOf(credential)] = credential
+	}
+	return broker
+}
+
+func indexOf(credential credentials.Credential) int {
+	return credential.(indexed.Indexed).Index().(int)
+}
+
+// Makes a shallow copy of a credential.
+func clone(credential credentials.Credential) credentials.Credential {
+	value := reflect.ValueOf(credential)
+	copied := reflect.New(value.Elem().Type())
+	copied.Elem().Set(value.Elem())
+	return copied.Interface().(credentials.Credential)
+}
+
+func (broker *MemoryBroker) output(credential credentials.Credential) credentials.Credential {
+	if broker.Copies {
+		return clone(credential)
+	}
+	return credential
+}
+
+func (broker *MemoryBroker) Allows(template credentials.Credential) bool {
+	_, ok := broker.data[reflect.TypeOf(template)]
+	return ok
+}
+
+func (broker *MemoryBroker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.ByIdentifierCalls++
+	for _, result := range broker.data[reflect.TypeOf(template)] {
+		if result.(identified.Identified).Identification() == identifier {
+			return broker.output(result), nil
+		}
+	}
+	return nil, nil
+}
+
+func (broker *MemoryBroker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.ByIndexCalls++
+	if intIndex, ok := index.(int); !ok {
+		return nil, nil
+	} else if result, ok := broker.data[reflect.TypeOf(template)][intIndex]; !ok {
+		return nil, nil
+	} else {
+		return broker.output(result), nil
+	}
+}
+
+func (broker *MemoryBroker) List(template credentials.Credential, filter credentials.Filter, cursor string, limit int) ([]credentials.Credential, string, error) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	data := broker.data[reflect.TypeOf(template)]
+	indices := make([]int, 0, len(data))
+	for index := range data {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	all := make([]credentials.Credential, len(indices))
+	for position, index := range indices {
+		all[position] = broker.output(data[index])
+	}
+	return filtering.Page(all, indexKey, filter, cursor, limit)
+}
+
+// Sorts the credentials by their (non-negative) index.
+func indexKey(credential credentials.Credential) string {
+	return fmt.Sprintf("%020d", indexOf(credential))
+}
+
+func (broker *MemoryBroker) delay() {
+	broker.mutex.Lock()
+	broker.inFlight++
+	if broker.inFlight > broker.MaxInFlight {
+		broker.MaxInFlight = broker.inFlight
+	}
+	broker.mutex.Unlock()
+	time.Sleep(broker.Delay)
+	broker.mutex.Lock()
+	broker.inFlight--
+	broker.mutex.Unlock()
+}
+
+func (broker *MemoryBroker) Save(credential credentials.Credential) error {
+	if broker.Delay > 0 {
+		broker.delay()
+	}
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.SaveCalls++
+	if broker.Err != nil {
+		return broker.Err
+	}
+	data := broker.data[reflect.TypeOf(credential)]
+	stored, ok := data[indexOf(credential)]
+	if !ok {
+		return errors.New("credential does not exist")
+	}
+	if broker.Copies {
+		if versionedCred, ok := credential.(versioned.Versioned); ok {
+			if stored.(versioned.Versioned).Version() != versionedCred.Version() {
+				return credentials.ErrConcurrentModification
+			}
+			versionedCred.SetVersion(versionedCred.Version() + 1)
+		}
+	}
+	data[indexOf(credential)] = broker.output(credential)
+	return nil
+}
+
+func (broker *MemoryBroker) Create(credential credentials.Credential) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if broker.Err != nil {
+		return broker.Err
+	}
+	data, ok := broker.data[reflect.TypeOf(credential)]
+	if !ok {
+		return errors.New("credential type not allowed")
+	} else if _, ok := data[indexOf(credential)]; ok {
+		return errors.New("credential already exists")
+	}
+	identifier := credential.(identified.Identified).Identification()
+	for _, existing := range data {
+		if existing.(identified.Identified).Identification() == identifier {
+			return errors.New("credential already exists")
+		}
+	}
+	data[indexOf(credential)] = broker.output(credential)
+	return nil
+}
+
+func (broker *MemoryBroker) Delete(credential credentials.Credential) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if broker.Err != nil {
+		return broker.Err
+	}
+	delete(broker.data[reflect.TypeOf(credential)], indexOf(credential))
+	return nil
+}
+
+// Tells whether a credential (of any type) is stored under
+// the given identifier.
+func (broker *MemoryBroker) Has(identifier interface{}) bool {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for _, data := range broker.data {
+		for _, result := range data {
+			if result.(identified.Identified).Identification() == identifier {
+				return true
+			}
+		}
+	}
+	return false
+}
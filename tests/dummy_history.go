@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/login/activity"
+	"github.com/universe-10th/identity/realms/login/password"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"time"
+)
+
+// A user with a login history (see history_test.go). It embeds
+// the second factor user, so the histories of challenged logins
+// can be tested as well.
+type TrackedUser struct {
+	TwoFactorUser
+	lastLogin       time.Time
+	lastFailedLogin time.Time
+	loginHistory    []history.Entry
+}
+
+func (user *TrackedUser) LastLogin() time.Time {
+	return user.lastLogin
+}
+
+func (user *TrackedUser) LastFailedLogin() time.Time {
+	return user.lastFailedLogin
+}
+
+func (user *TrackedUser) LoginHistory() []history.Entry {
+	return user.loginHistory
+}
+
+func (user *TrackedUser) SetLoginHistory(lastLogin, lastFailedLogin time.Time, entries []history.Entry) {
+	user.lastLogin = lastLogin
+	user.lastFailedLogin = lastFailedLogin
+	user.loginHistory = entries
+}
+
+func MakeHistoryExampleInstances(factors ...twofactor.SecondFactor) (*realms.TypedRealm[*TrackedUser], *MemoryBroker) {
+	hash := func(input string) string {
+		hashed, _ := DummyHasher(0).Hash(input)
+		return hashed
+	}
+	broker := NewMemoryBroker().Put(
+		&TrackedUser{TwoFactorUser: TwoFactorUser{User: User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hash("user1$123")}}}},
+		&TrackedUser{TwoFactorUser: TwoFactorUser{User: User{BaseUser: BaseUser{identifier: "U2", index: 2, active: true, hashedPassword: hash("user2$123")}}}},
+	)
+	users := credentials.NewTypedSource[*TrackedUser](broker, func() *TrackedUser { return &TrackedUser{} })
+	userRealm := realms.NewTypedRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0))
+	userRealm.SetSecondFactors(factors...)
+	return userRealm, broker
+}
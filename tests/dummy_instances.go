@@ -6,17 +6,21 @@ import (
 	scoped2 "github.com/universe-10th/identity/authreqs/scoped"
 	"github.com/universe-10th/identity/authreqs/superuser"
 	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/brokers/cached"
+	"github.com/universe-10th/identity/credentials/brokers/migration"
+	"github.com/universe-10th/identity/credentials/brokers/multi"
+	"github.com/universe-10th/identity/credentials/csvimport"
 	"github.com/universe-10th/identity/credentials/traits/scoped"
 	"github.com/universe-10th/identity/hashing"
 	"github.com/universe-10th/identity/realms"
 	"github.com/universe-10th/identity/realms/login/activity"
 	"github.com/universe-10th/identity/realms/login/password"
 	"github.com/universe-10th/identity/realms/login/punish"
-	"reflect"
+	"github.com/universe-10th/identity/realms/ratelimit"
 	"time"
 )
 
-func MakeUserExampleInstances() ([]authreqs.AuthorizationRequirement, []*realms.Realm) {
+func MakeUserExampleBroker() *MemoryBroker {
 	hasher := (&BaseUser{}).Hasher()
 	hash := func(input string) string {
 		hashed, _ := hasher.Hash(input)
@@ -36,23 +40,23 @@ func MakeUserExampleInstances() ([]authreqs.AuthorizationRequirement, []*realms.
 	scope5 := DummyScope(5)
 	scope7 := DummyScope(7)
 
-	adminSU := &Admin{BaseUser: BaseUser{active: true, hashedPassword: hash("admin-su$123")}, superuser: true, scopes: nil}
-	adminS1 := &Admin{BaseUser: BaseUser{active: true, hashedPassword: hash("admin-s1$123")}, superuser: false, scopes: map[string]scoped.Scope{
+	adminSU := &Admin{BaseUser: BaseUser{identifier: "SU", index: 0, active: true, hashedPassword: hash("admin-su$123")}, superuser: true, scopes: nil}
+	adminS1 := &Admin{BaseUser: BaseUser{identifier: "S1", index: 1, active: true, hashedPassword: hash("admin-s1$123")}, superuser: false, scopes: map[string]scoped.Scope{
 		scope2.Key(): scope2,
 		scope3.Key(): scope3,
 	}}
-	adminS2 := &Admin{BaseUser: BaseUser{active: true, hashedPassword: hash("admin-s2$123")}, superuser: false, scopes: map[string]scoped.Scope{
+	adminS2 := &Admin{BaseUser: BaseUser{identifier: "S2", index: 2, active: true, hashedPassword: hash("admin-s2$123")}, superuser: false, scopes: map[string]scoped.Scope{
 		scope5.Key(): scope5,
 		scope7.Key(): scope7,
 	}}
-	adminS3 := &Admin{BaseUser: BaseUser{active: true, hashedPassword: hash("admin-s3$123")}, superuser: false, scopes: map[string]scoped.Scope{
+	adminS3 := &Admin{BaseUser: BaseUser{identifier: "S3", index: 3, active: true, hashedPassword: hash("admin-s3$123")}, superuser: false, scopes: map[string]scoped.Scope{
 		scope5.Key(): scope5,
 		scope3.Key(): scope3,
 	}}
-	user1 := &User{BaseUser: BaseUser{active: true, hashedPassword: hash("user1$123")}}
-	user2 := &User{BaseUser: BaseUser{active: false, hashedPassword: hash("user2$123")}}
+	user1 := &User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hash("user1$123")}}
+	user2 := &User{BaseUser: BaseUser{identifier: "U2", index: 2, active: false, hashedPassword: hash("user2$123")}}
 	user3 := &User{
-		BaseUser: BaseUser{active: true, hashedPassword: hash("user3$123")},
+		BaseUser: BaseUser{identifier: "U3", index: 3, active: true, hashedPassword: hash("user3$123")},
 		// Punishment: expired
 		punishedOn:  ago(time.Hour * 24 * 7),
 		punishedFor: ptr(time.Hour * 24 * 3),
@@ -60,7 +64,7 @@ func MakeUserExampleInstances() ([]authreqs.AuthorizationRequirement, []*realms.
 		punisher:    adminS1,
 	}
 	user4 := &User{
-		BaseUser: BaseUser{active: true, hashedPassword: hash("user4$123")},
+		BaseUser: BaseUser{identifier: "U4", index: 4, active: true, hashedPassword: hash("user4$123")},
 		// Punishment: current
 		punishedOn:  ago(time.Hour * 24 * 7),
 		punishedFor: ptr(time.Hour * 24 * 8),
@@ -68,45 +72,22 @@ func MakeUserExampleInstances() ([]authreqs.AuthorizationRequirement, []*realms.
 		punisher:    adminS1,
 	}
 	user5 := &User{
-		BaseUser: BaseUser{active: true, hashedPassword: hash("user5$123")},
+		BaseUser: BaseUser{identifier: "U5", index: 5, active: true, hashedPassword: hash("user5$123")},
 		// Punishment: eternal
 		punishedOn:  ago(time.Hour * 24 * 7),
 		punishedFor: nil,
 		punishment:  "Sample Punishment (eternal)",
 		punisher:    adminS1,
 	}
-	broker := &DummyBroker{
-		dataByIndex: map[reflect.Type]map[int]credentials.Credential{
-			reflect.TypeOf(&Admin{}): {
-				0: adminSU,
-				1: adminS1,
-				2: adminS2,
-				3: adminS3,
-			},
-			reflect.TypeOf(&User{}): {
-				1: user1,
-				2: user2,
-				3: user3,
-				4: user4,
-				5: user5,
-			},
-		},
-		dataByIdentifier: map[reflect.Type]map[string]credentials.Credential{
-			reflect.TypeOf(&Admin{}): {
-				"SU": adminSU,
-				"S1": adminS1,
-				"S2": adminS2,
-				"S3": adminS3,
-			},
-			reflect.TypeOf(&User{}): {
-				"U1": user1,
-				"U2": user2,
-				"U3": user3,
-				"U4": user4,
-				"U5": user5,
-			},
-		},
-	}
+	return NewMemoryBroker().Put(adminSU, adminS1, adminS2, adminS3, user1, user2, user3, user4, user5)
+}
+
+func MakeUserExampleInstances() ([]authreqs.AuthorizationRequirement, []*realms.Realm) {
+	broker := MakeUserExampleBroker()
+	scope2 := DummyScope(2)
+	scope3 := DummyScope(3)
+	scope5 := DummyScope(5)
+	scope7 := DummyScope(7)
 
 	users := credentials.NewSource(broker, &User{})
 	admins := credentials.NewSource(broker, &Admin{})
@@ -136,3 +117,98 @@ func MakeMultiHasherExampleInstances() (hashing.HashingEngine, DummyHasher, Dumm
 	multi := hashing.NewMultipleHashingEngine(h0, h1)
 	return multi, h0, h1
 }
+
+func MakeMultiLookupExampleInstances() (*realms.Realm, *int) {
+	broker := MakeUserExampleBroker()
+	emails := map[string]string{
+		"u1@example.com": "U1",
+		"u3@example.com": "U3",
+	}
+	emailLookups := new(int)
+	byEmail := &multi.LookupFunc{
+		Matcher: multi.Matching(multi.EmailPattern),
+		Finder: func(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+			*emailLookups++
+			if username, ok := emails[identifier.(string)]; ok {
+				return broker.ByIdentifier(username, template)
+			} else {
+				return nil, nil
+			}
+		},
+	}
+	multiBroker := multi.NewBroker(broker, multi.Direct(broker, nil), byEmail)
+	users := credentials.NewSource(multiBroker, &User{})
+	return realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0)), emailLookups
+}
+
+func MakeCachedExampleInstances(options cached.Options) (*realms.Realm, *MemoryBroker, *cached.Broker) {
+	counting := MakeUserExampleBroker()
+	cachedBroker := cached.NewBroker(counting, options)
+	users := credentials.NewSource(cachedBroker, &User{})
+	return realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0)), counting, cachedBroker
+}
+
+func MakeVersionedExampleInstances() (*realms.Realm, *MemoryBroker) {
+	hashed, _ := DummyHasher(0).Hash("user1$123")
+	broker := NewMemoryBroker().Put(&VersionedUser{User: User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hashed}}})
+	broker.Copies = true
+	users := credentials.NewSource(broker, &VersionedUser{})
+	return realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0)), broker
+}
+
+func MakeFailingExampleInstances() (*realms.Realm, *MemoryBroker) {
+	broker := MakeUserExampleBroker()
+	users := credentials.NewSource(broker, &User{})
+	return realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0)), broker
+}
+
+func MakeTypedExampleInstances() *realms.TypedRealm[*User] {
+	users := credentials.NewTypedSource(MakeUserExampleBroker(), func() *User { return &User{} })
+	return realms.NewTypedRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), &punish.PunishmentCheckStep{TimeFormat: "2006-01-02T15:04:05"})
+}
+
+func MakeMigrationExampleInstances(deleteLegacy bool) (*realms.Realm, *MemoryBroker, *MemoryBroker) {
+	hashed, _ := DummyHasher(0).Hash("user1$123")
+	primary := NewMemoryBroker(&User{})
+	legacy := NewMemoryBroker().Put(&User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hashed}})
+	mapper := func(legacyCred credentials.Credential) (credentials.Credential, error) {
+		user := *legacyCred.(*User)
+		return &user, nil
+	}
+	broker := migration.NewBroker(primary, legacy, &User{}, mapper, deleteLegacy)
+	users := credentials.NewSource(broker, &User{})
+	userRealm := realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), &migration.MigrationStep{Broker: broker})
+	return userRealm, primary, legacy
+}
+
+func MakeLockingExampleInstances(locker realms.Locker) (*realms.Realm, *MemoryBroker) {
+	broker := MakeUserExampleBroker()
+	broker.Delay = 5 * time.Millisecond
+	users := credentials.NewSource(broker, &User{})
+	userRealm := realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), &punish.PunishmentCheckStep{TimeFormat: "2006-01-02T15:04:05"})
+	userRealm.SetLocker(locker)
+	return userRealm, broker
+}
+
+func MakeCSVImportExampleInstances(rejectPlaintext bool) (*csvimport.Importer, *MemoryBroker) {
+	broker := NewMemoryBroker(&User{})
+	index := 0
+	factory := func(row *csvimport.Row) (credentials.Credential, error) {
+		index++
+		return &User{BaseUser: BaseUser{identifier: row.Identifier, index: index}}, nil
+	}
+	return &csvimport.Importer{Broker: broker, Factory: factory, RejectPlaintext: rejectPlaintext}, broker
+}
+
+func MakeAttemptExampleInstances(step *RecordingStep) *realms.Realm {
+	users := credentials.NewSource(MakeUserExampleBroker(), &User{})
+	return realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), step)
+}
+
+func MakeRateLimitExampleInstances(limiter *ratelimit.Limiter) (*realms.Realm, *MemoryBroker) {
+	counting := MakeUserExampleBroker()
+	users := credentials.NewSource(counting, &User{})
+	userRealm := realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0))
+	userRealm.SetRateLimiter(limiter)
+	return userRealm, counting
+}
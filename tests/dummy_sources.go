@@ -4,11 +4,12 @@ import (
 	"github.com/universe-10th/identity/credentials"
 	"github.com/universe-10th/identity/credentials/traits/scoped"
 	"github.com/universe-10th/identity/hashing"
-	"reflect"
 	"time"
 )
 
 type BaseUser struct {
+	identifier     string
+	index          int
 	active         bool
 	hashedPassword string
 	recoveryToken  string
@@ -17,6 +18,18 @@ type BaseUser struct {
 	recoveryValid time.Time
 }
 
+func (user *BaseUser) Identification() interface{} {
+	return user.identifier
+}
+
+func (user *BaseUser) SetIdentification(identification interface{}) {
+	user.identifier = identification.(string)
+}
+
+func (user *BaseUser) Index() interface{} {
+	return user.index
+}
+
 func (user *BaseUser) Active() bool {
 	return user.active
 }
@@ -50,6 +63,10 @@ func (user *BaseUser) RecoveryToken() string {
 	return user.recoveryToken
 }
 
+func (user *BaseUser) RecoveryTokenExpiration() time.Time {
+	return user.recoveryValid
+}
+
 type User struct {
 	BaseUser
 	punishedOn  *time.Time
@@ -101,32 +118,19 @@ func (admin *Admin) Scopes() map[string]scoped.Scope {
 	return admin.scopes
 }
 
-type DummyBroker struct {
-	dataByIdentifier map[reflect.Type]map[string]credentials.Credential
-	dataByIndex      map[reflect.Type]map[int]credentials.Credential
-}
-
-func (broker *DummyBroker) Allows(template credentials.Credential) bool {
-	_, ok := broker.dataByIndex[reflect.TypeOf(template)]
-	return ok
+func (admin *Admin) SetScopes(scopes map[string]scoped.Scope) {
+	admin.scopes = scopes
 }
 
-func (broker *DummyBroker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
-	if result, ok := broker.dataByIdentifier[reflect.TypeOf(template)][identifier.(string)]; !ok {
-		return nil, nil
-	} else {
-		return result, nil
-	}
+type VersionedUser struct {
+	User
+	version uint64
 }
 
-func (broker *DummyBroker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
-	if result, ok := broker.dataByIndex[reflect.TypeOf(template)][index.(int)]; !ok {
-		return nil, nil
-	} else {
-		return result, nil
-	}
+func (user *VersionedUser) Version() uint64 {
+	return user.version
 }
 
-func (broker *DummyBroker) Save(credential credentials.Credential) error {
-	return nil
+func (user *VersionedUser) SetVersion(version uint64) {
+	user.version = version
 }
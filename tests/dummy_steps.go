@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/realms/login"
+	"net"
+)
+
+var ErrBlockedNetwork = errors.New("blocked network")
+
+// A step recording the attempts it receives, and rejecting
+// the ones coming from a blocked network.
+type RecordingStep struct {
+	Blocked  *net.IPNet
+	Attempts []login.Attempt
+}
+
+func (step *RecordingStep) Login(credential credentials.Credential, password string) error {
+	return step.LoginAttempt(credential, login.NewAttempt(nil, password))
+}
+
+func (step *RecordingStep) LoginAttempt(credential credentials.Credential, attempt *login.Attempt) error {
+	step.Attempts = append(step.Attempts, *attempt)
+	if step.Blocked != nil && step.Blocked.Contains(attempt.ClientIP) {
+		return ErrBlockedNetwork
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	webauthntrait "github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/lockout"
+	"github.com/universe-10th/identity/realms/login/activity"
+	"github.com/universe-10th/identity/realms/login/password"
+	"github.com/universe-10th/identity/realms/login/punish"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/passcode"
+	"github.com/universe-10th/identity/realms/webauthn"
+	"time"
+)
+
+// Keeps the TOTP secret (see totp_test.go).
+type totpFields struct {
+	totpSecret   []byte
+	totpEnrolled bool
+	totpLastStep int64
+}
+
+func (fields *totpFields) TOTPSecret() ([]byte, bool) {
+	return fields.totpSecret, fields.totpEnrolled
+}
+
+func (fields *totpFields) SetTOTPSecret(secret []byte, enrolled bool) {
+	fields.totpSecret = secret
+	fields.totpEnrolled = enrolled
+}
+
+func (fields *totpFields) TOTPLastStep() int64 {
+	return fields.totpLastStep
+}
+
+func (fields *totpFields) SetTOTPLastStep(step int64) {
+	fields.totpLastStep = step
+}
+
+// Keeps the hashed recovery codes (see recovery_codes_test.go).
+type recoveryCodesFields struct {
+	recoveryCodes []string
+}
+
+func (fields *recoveryCodesFields) RecoveryCodes() []string {
+	return fields.recoveryCodes
+}
+
+func (fields *recoveryCodesFields) SetRecoveryCodes(hashedCodes []string) {
+	fields.recoveryCodes = hashedCodes
+}
+
+// Keeps the out-of-band passcode (see passcode_test.go).
+type passcodeFields struct {
+	passcodeEnrolled  bool
+	passcode          string
+	passcodeExpiresAt time.Time
+	passcodeAttempts  int
+}
+
+func (fields *passcodeFields) PasscodeEnrolled() bool {
+	return fields.passcodeEnrolled
+}
+
+func (fields *passcodeFields) SetPasscodeEnrolled(enrolled bool) {
+	fields.passcodeEnrolled = enrolled
+}
+
+func (fields *passcodeFields) Passcode() (string, time.Time, int) {
+	return fields.passcode, fields.passcodeExpiresAt, fields.passcodeAttempts
+}
+
+func (fields *passcodeFields) SetPasscode(hashedCode string, expiresAt time.Time, attempts int) {
+	fields.passcode = hashedCode
+	fields.passcodeExpiresAt = expiresAt
+	fields.passcodeAttempts = attempts
+}
+
+// Keeps the WebAuthn public key credentials (see webauthn_test.go).
+type webAuthnFields struct {
+	webAuthn []webauthntrait.PublicKeyCredential
+}
+
+func (fields *webAuthnFields) WebAuthnCredentials() []webauthntrait.PublicKeyCredential {
+	return fields.webAuthn
+}
+
+func (fields *webAuthnFields) SetWebAuthnCredentials(credentials []webauthntrait.PublicKeyCredential) {
+	fields.webAuthn = credentials
+}
+
+// Keeps the failed logins count (see lockout_test.go).
+type failureCountingFields struct {
+	failedLogins int
+	lastFailure  time.Time
+}
+
+func (fields *failureCountingFields) FailedLogins() (int, time.Time) {
+	return fields.failedLogins, fields.lastFailure
+}
+
+func (fields *failureCountingFields) SetFailedLogins(count int, last time.Time) {
+	fields.failedLogins = count
+	fields.lastFailure = last
+}
+
+// A user implementing the traits of every second factor, and
+// counting its failed logins. Each trait is kept by its own
+// embedded fields, so the tests of a feature only rely on the
+// fields of that feature (and the realm picks the factors to
+// use via SetSecondFactors, regardless of the traits).
+type TwoFactorUser struct {
+	User
+	totpFields
+	recoveryCodesFields
+	passcodeFields
+	webAuthnFields
+	failureCountingFields
+}
+
+func MakeTwoFactorExampleBroker() *MemoryBroker {
+	hash := func(input string) string {
+		hashed, _ := DummyHasher(0).Hash(input)
+		return hashed
+	}
+	return NewMemoryBroker().Put(
+		&TwoFactorUser{User: User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hash("user1$123")}}},
+		&TwoFactorUser{User: User{BaseUser: BaseUser{identifier: "U2", index: 2, active: false, hashedPassword: hash("user2$123")}}},
+	)
+}
+
+func MakeTwoFactorExampleInstances(factors ...twofactor.SecondFactor) (*realms.TypedRealm[*TwoFactorUser], *MemoryBroker) {
+	broker := MakeTwoFactorExampleBroker()
+	users := credentials.NewTypedSource[*TwoFactorUser](broker, func() *TwoFactorUser { return &TwoFactorUser{} })
+	userRealm := realms.NewTypedRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0))
+	userRealm.SetSecondFactors(factors...)
+	return userRealm, broker
+}
+
+func MakePasswordlessExampleInstances(factor *passcode.Factor) (*realms.TypedRealm[*TwoFactorUser], *MemoryBroker) {
+	broker := MakeTwoFactorExampleBroker()
+	users := credentials.NewTypedSource[*TwoFactorUser](broker, func() *TwoFactorUser { return &TwoFactorUser{} })
+	userRealm := realms.NewTypedRealm(users, activity.ActivityStep(0))
+	userRealm.SetSecondFactors(factor)
+	return userRealm, broker
+}
+
+func MakeWebAuthnExampleInstances() (*realms.TypedRealm[*TwoFactorUser], *MemoryBroker, *webauthn.RelyingParty) {
+	broker := MakeTwoFactorExampleBroker()
+	users := credentials.NewTypedSource[*TwoFactorUser](broker, func() *TwoFactorUser { return &TwoFactorUser{} })
+	userRealm := realms.NewTypedRealm(users, activity.ActivityStep(0))
+	return userRealm, broker, webauthn.NewRelyingParty("example.com", "Example", "https://example.com")
+}
+
+func MakeLockoutExampleInstances(policy *lockout.Policy) (*realms.TypedRealm[*TwoFactorUser], *MemoryBroker) {
+	broker := MakeTwoFactorExampleBroker()
+	users := credentials.NewTypedSource[*TwoFactorUser](broker, func() *TwoFactorUser { return &TwoFactorUser{} })
+	userRealm := realms.NewTypedRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), &punish.PunishmentCheckStep{TimeFormat: "2006-01-02T15:04:05"})
+	userRealm.SetLockout(policy)
+	return userRealm, broker
+}
@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/login/password"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Records the published events.
+type eventRecorder struct {
+	mutex  sync.Mutex
+	events []events.Event
+}
+
+func (recorder *eventRecorder) Handle(event events.Event) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.events = append(recorder.events, event)
+}
+
+func (recorder *eventRecorder) Take() []events.Event {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	result := recorder.events
+	recorder.events = nil
+	return result
+}
+
+func TestLoginEvents(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U1", "user1$124")
+	_, _ = userRealm.Login("U9", "user9$123")
+	published := recorder.Take()
+	if len(published) != 3 {
+		t.Fatalf("Each login must publish an event. Got: %d\n", len(published))
+	}
+	if succeeded, ok := published[0].(*events.LoginSucceeded); !ok || succeeded.Credential != credential || succeeded.Attempt.Identifier != "U1" {
+		t.Errorf("A successful login must publish LoginSucceeded. Got: %#v\n", published[0])
+	}
+	if failed, ok := published[1].(*events.LoginFailed); !ok || failed.Credential != credential || failed.Step != password.PasswordCheckingStep(0) || failed.Reason != realms.ErrLoginFailed {
+		t.Errorf("A bad password must publish LoginFailed with the password step. Got: %#v\n", published[1])
+	}
+	if failed, ok := published[2].(*events.LoginFailed); !ok || failed.Credential != nil || failed.Identifier != "U9" || failed.Step != nil {
+		t.Errorf("An unknown user must publish LoginFailed with no credential. Got: %#v\n", published[2])
+	}
+	if published[0].At().IsZero() {
+		t.Error("The events must have a time")
+	}
+}
+
+func TestLoginEventsHidePasswords(t *testing.T) {
+	factor := totp.NewFactor("Example")
+	userRealm, _ := MakeTwoFactorExampleInstances(factor)
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+
+	attempt := login.NewAttempt("U1", "user1$124")
+	attempt.Metadata = map[string]interface{}{"country": "AR"}
+	_, _ = userRealm.LoginAttempt(attempt)
+	_, _ = userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U9", "user9$123")
+	credential, _ := userRealm.ByIdentifier("U1")
+	secret, _ := totp.GenerateSecret()
+	credential.SetTOTPSecret(secret, true)
+	_, err := userRealm.Login("U1", "user1$123")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("Login must require a second factor. Error: %v\n", err)
+	}
+	_, _ = userRealm.CompleteLogin(required.Handle, "bad")
+	_, _ = userRealm.CompleteLogin(required.Handle, totp.Code(secret, factor.Step(time.Now()), totp.DefaultDigits))
+
+	published := recorder.Take()
+	if len(published) != 5 {
+		t.Fatalf("Each login must publish an event. Got: %d\n", len(published))
+	}
+	for _, event := range published {
+		var published *login.Attempt
+		switch event := event.(type) {
+		case *events.LoginSucceeded:
+			published = event.Attempt
+		case *events.LoginFailed:
+			published = event.Attempt
+		}
+		if published == nil || published.Password != "" || published.Identifier == nil {
+			t.Errorf("The published attempts must not have the password. Got: %#v\n", published)
+		}
+	}
+	if attempt.Password != "user1$124" {
+		t.Error("The attempt given to the realm must not be changed")
+	}
+	if failed, ok := published[0].(*events.LoginFailed); !ok || failed.Attempt.Metadata["country"] != "AR" {
+		t.Errorf("The published attempts must have the metadata. Got: %#v\n", published[0])
+	} else if failed.Attempt.Metadata["country"] = "UY"; attempt.Metadata["country"] != "AR" {
+		t.Error("The metadata given to the realm must not be shared with the subscribers")
+	}
+}
+
+func TestPasswordEvents(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+	recorder := &eventRecorder{}
+	unsubscribe := userRealm.Subscribe(recorder.Handle)
+	credential, _ := userRealm.ByIdentifier("U1")
+
+	_ = userRealm.SetPassword(credential, "user1$456", nil)
+	_ = userRealm.ChangePassword(credential, "user1$000", "user1$789")
+	_ = userRealm.ChangePassword(credential, "user1$456", "user1$789")
+	_ = userRealm.UnsetPassword(credential, nil)
+	_ = userRealm.PreparePasswordReset(credential, "token1", time.Hour, nil)
+	_ = userRealm.CancelPasswordReset(credential, nil)
+	_ = userRealm.PreparePasswordReset(credential, "token2", time.Hour, nil)
+	_ = userRealm.ConfirmPasswordReset(credential, "token2", "user1$123")
+	published := recorder.Take()
+	if len(published) != 7 {
+		t.Fatalf("Each successful operation must publish an event. Got: %d\n", len(published))
+	}
+	if changed, ok := published[0].(*events.PasswordChanged); !ok || changed.ByUser || changed.Credential != credential {
+		t.Errorf("SetPassword must publish PasswordChanged. Got: %#v\n", published[0])
+	}
+	if changed, ok := published[1].(*events.PasswordChanged); !ok || !changed.ByUser {
+		t.Errorf("ChangePassword must publish PasswordChanged by the user. Got: %#v\n", published[1])
+	}
+	if _, ok := published[2].(*events.PasswordUnset); !ok {
+		t.Errorf("UnsetPassword must publish PasswordUnset. Got: %#v\n", published[2])
+	}
+	if prepared, ok := published[3].(*events.ResetPrepared); !ok || prepared.Duration != time.Hour {
+		t.Errorf("PreparePasswordReset must publish ResetPrepared. Got: %#v\n", published[3])
+	}
+	if _, ok := published[4].(*events.ResetCancelled); !ok {
+		t.Errorf("CancelPasswordReset must publish ResetCancelled. Got: %#v\n", published[4])
+	}
+	if _, ok := published[6].(*events.ResetConfirmed); !ok {
+		t.Errorf("ConfirmPasswordReset must publish ResetConfirmed. Got: %#v\n", published[6])
+	}
+
+	unsubscribe()
+	_ = userRealm.UnsetPassword(credential, nil)
+	if published := recorder.Take(); len(published) != 0 {
+		t.Errorf("Unsubscribed handlers must not receive events. Got: %d\n", len(published))
+	}
+}
+
+func TestAsyncDispatcher(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+	recorder := &eventRecorder{}
+	dispatcher := events.NewDispatcher(recorder.Handle, 16)
+	userRealm.Subscribe(dispatcher.Handle)
+
+	for index := 0; index < 4; index++ {
+		_, _ = userRealm.Login("U1", "user1$123")
+	}
+	dispatcher.Close()
+	if published := recorder.Take(); len(published) != 4 {
+		t.Errorf("The queued events must be delivered on close. Got: %d\n", len(published))
+	}
+	_, _ = userRealm.Login("U1", "user1$123")
+	if dispatcher.Dropped() != 1 {
+		t.Errorf("Events after close must be dropped. Dropped: %d\n", dispatcher.Dropped())
+	}
+
+	// A full buffer drops the events instead of blocking.
+	release := make(chan struct{})
+	blocked := events.NewDispatcher(func(event events.Event) { <-release }, 1)
+	for index := 0; index < 4; index++ {
+		blocked.Handle(&events.PasswordUnset{})
+	}
+	close(release)
+	blocked.Close()
+	if dropped := blocked.Dropped(); dropped < 2 {
+		t.Errorf("Events beyond the buffer must be dropped. Dropped: %d\n", dropped)
+	}
+}
+
+func TestSecondFactorEvents(t *testing.T) {
+	factor := totp.NewFactor("Example")
+	userRealm, _ := MakeTwoFactorExampleInstances(factor)
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	secret, _ := totp.GenerateSecret()
+	credential.SetTOTPSecret(secret, true)
+	_, err := userRealm.Login("U1", "user1$123")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("Login must require a second factor. Error: %v\n", err)
+	}
+	if published := recorder.Take(); len(published) != 0 {
+		t.Errorf("Requiring a second factor must not publish events. Got: %d\n", len(published))
+	}
+
+	_, _ = userRealm.CompleteLogin(required.Handle, "bad")
+	code := totp.Code(secret, factor.Step(time.Now()), totp.DefaultDigits)
+	_, _ = userRealm.CompleteLogin(required.Handle, code)
+	_, _ = userRealm.CompleteLogin("unknown", code)
+	published := recorder.Take()
+	if len(published) != 3 {
+		t.Fatalf("Each completion must publish an event. Got: %d\n", len(published))
+	}
+	if failed, ok := published[0].(*events.LoginFailed); !ok || failed.Credential != credential || failed.Reason != twofactor.ErrBadCode {
+		t.Errorf("A bad code must publish LoginFailed. Got: %#v\n", published[0])
+	}
+	if succeeded, ok := published[1].(*events.LoginSucceeded); !ok || succeeded.Credential != credential {
+		t.Errorf("A good code must publish LoginSucceeded. Got: %#v\n", published[1])
+	}
+	if failed, ok := published[2].(*events.LoginFailed); !ok || failed.Reason != realms.ErrBadChallenge {
+		t.Errorf("A bad challenge must publish LoginFailed. Got: %#v\n", published[2])
+	}
+}
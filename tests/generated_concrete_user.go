@@ -0,0 +1,18 @@
+package tests
+
+import (
+	"time"
+)
+
+//go:generate go run ../cmd/identity-gen -type ConcreteUser -hasher DummyHasher(0)
+
+// A generated credential whose punishment reason and punisher
+// have concrete types.
+type ConcreteUser struct {
+	Login     string         `identity:"identifier"`
+	Password  string         `identity:"password"`
+	BannedOn  *time.Time     `identity:"punished_on"`
+	BannedFor *time.Duration `identity:"punished_for"`
+	BanReason string         `identity:"punishment_reason"`
+	BannedBy  *User          `identity:"punished_by"`
+}
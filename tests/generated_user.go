@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"time"
+)
+
+//go:generate go run ../cmd/identity-gen -type GeneratedUser -hasher DummyHasher(0)
+
+type GeneratedUser struct {
+	Login      string                  `identity:"identifier"`
+	ID         int                     `identity:"index"`
+	Password   string                  `identity:"password"`
+	Enabled    bool                    `identity:"active"`
+	Token      string                  `identity:"recovery_token"`
+	Expiration time.Time               `identity:"recovery_expiration"`
+	BannedOn   *time.Time              `identity:"punished_on"`
+	BannedFor  *time.Duration          `identity:"punished_for"`
+	BanReason  interface{}             `identity:"punishment_reason"`
+	BannedBy   credentials.Credential  `identity:"punished_by"`
+	Grants     map[string]scoped.Scope `identity:"scopes"`
+	IsStaff    bool                    `identity:"staff"`
+	Revision   uint64                  `identity:"version"`
+}
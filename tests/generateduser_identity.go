@@ -0,0 +1,97 @@
+// Code generated by identity-gen. DO NOT EDIT.
+
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/hashing"
+	"time"
+)
+
+func (g *GeneratedUser) HashedPassword() string {
+	return g.Password
+}
+
+func (g *GeneratedUser) SetHashedPassword(password string) {
+	g.Password = password
+}
+
+func (g *GeneratedUser) Hasher() hashing.HashingEngine {
+	return DummyHasher(0)
+}
+
+func (g *GeneratedUser) Active() bool {
+	return g.Enabled
+}
+
+func (g *GeneratedUser) SetActive(active bool) {
+	g.Enabled = active
+}
+
+func (g *GeneratedUser) PunishedFor() (punishedOn *time.Time, forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	punishedOn = g.BannedOn
+	forTime = g.BannedFor
+	reason = g.BanReason
+	by, _ = interface{}(g.BannedBy).(credentials.Credential)
+	return
+}
+
+func (g *GeneratedUser) Punish(forTime *time.Duration, reason interface{}, by credentials.Credential) {
+	now := time.Now()
+	g.BannedOn = &now
+	g.BannedFor = nil
+	if forTime != nil {
+		duration := *forTime
+		g.BannedFor = &duration
+	}
+	g.BanReason, _ = reason.(interface{})
+	g.BannedBy, _ = by.(credentials.Credential)
+}
+
+func (g *GeneratedUser) Unpunish() {
+	g.BannedOn = nil
+	g.BannedFor = nil
+	g.BanReason = *new(interface{})
+	g.BannedBy = nil
+}
+
+func (g *GeneratedUser) SetRecoveryToken(token string, duration time.Duration) {
+	g.Token = token
+	g.Expiration = time.Now().Add(duration)
+}
+
+func (g *GeneratedUser) RecoveryToken() string {
+	if g.Token != "" && g.Expiration.Before(time.Now()) {
+		g.Token = ""
+	}
+	return g.Token
+}
+
+func (g *GeneratedUser) RecoveryTokenExpiration() time.Time {
+	return g.Expiration
+}
+
+func (g *GeneratedUser) Identification() interface{} {
+	return g.Login
+}
+
+func (g *GeneratedUser) Index() interface{} {
+	return g.ID
+}
+
+func (g *GeneratedUser) Scopes() map[string]scoped.Scope {
+	return g.Grants
+}
+
+func (g *GeneratedUser) Staff() bool {
+	return g.IsStaff
+}
+
+func (g *GeneratedUser) Version() uint64 {
+	return g.Revision
+}
+
+func (g *GeneratedUser) SetVersion(version uint64) {
+	g.Revision = version
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/lockout"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLoginHistory(t *testing.T) {
+	userRealm, broker := MakeHistoryExampleInstances()
+	userRealm.SetLoginHistorySize(3)
+	start := time.Now()
+
+	attempt := login.NewAttempt("U1", "user1$123")
+	attempt.ClientIP, attempt.UserAgent, attempt.DeviceID = net.ParseIP("192.0.2.1"), "TestAgent/1.0", "device-1"
+	if _, err := userRealm.LoginAttempt(attempt); err != nil {
+		t.Fatalf("Login for user U1 must succeed. Error: %s\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if !credential.LastLogin().Equal(attempt.Time) || !credential.LastFailedLogin().IsZero() {
+		t.Errorf("The last login must be tracked. Got: %s and %s\n", credential.LastLogin(), credential.LastFailedLogin())
+	}
+	entries := credential.LoginHistory()
+	if len(entries) != 1 || entries[0].Outcome != history.Succeeded || entries[0].ClientIP != "192.0.2.1" ||
+		entries[0].UserAgent != "TestAgent/1.0" || entries[0].DeviceID != "device-1" {
+		t.Errorf("The attempt must be kept in the history. Got: %v\n", entries)
+	}
+
+	// Failures are saved as they happen.
+	saves := broker.SaveCalls
+	for index := 0; index < 3; index++ {
+		_, _ = userRealm.Login("U1", "user1$124")
+	}
+	if broker.SaveCalls != saves+3 {
+		t.Errorf("Each failed login must be saved. Saves: %d\n", broker.SaveCalls-saves)
+	}
+	entries = credential.LoginHistory()
+	if len(entries) != 3 {
+		t.Fatalf("The history must be bounded. Got: %d entries\n", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Outcome != history.Failed {
+			t.Errorf("The newest attempts must be kept first. Got: %v\n", entries)
+		}
+	}
+	if credential.LastFailedLogin().Before(start) || !credential.LastLogin().Equal(attempt.Time) {
+		t.Errorf("The last failed login must be tracked. Got: %s and %s\n", credential.LastLogin(), credential.LastFailedLogin())
+	}
+
+	// Unknown users are not tracked.
+	if _, err := userRealm.Login("U9", "user9$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for unknown users must fail. Error: %v\n", err)
+	}
+}
+
+func TestLoginHistorySecondFactor(t *testing.T) {
+	factor := totp.NewFactor("Example")
+	userRealm, _ := MakeHistoryExampleInstances(factor)
+	credential, _ := userRealm.ByIdentifier("U1")
+	secret, _ := totp.GenerateSecret()
+	credential.SetTOTPSecret(secret, true)
+
+	attempt := login.NewAttempt("U1", "user1$123")
+	attempt.ClientIP = net.ParseIP("192.0.2.1")
+	_, err := userRealm.LoginAttempt(attempt)
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("Login must require a second factor. Error: %v\n", err)
+	}
+	if entries := credential.LoginHistory(); len(entries) != 1 || entries[0].Outcome != history.Challenged || !credential.LastLogin().IsZero() {
+		t.Errorf("A challenged login must not count as a login yet. Got: %v\n", entries)
+	}
+	if _, err := userRealm.CompleteLogin(required.Handle, totp.Code(secret, factor.Step(time.Now()), 6)); err != nil {
+		t.Fatalf("The login must be completed. Error: %s\n", err)
+	}
+	if entries := credential.LoginHistory(); len(entries) != 2 || entries[0].Outcome != history.Succeeded || entries[0].ClientIP != "192.0.2.1" {
+		t.Errorf("A completed login must be tracked with the original attempt. Got: %v\n", entries)
+	}
+	if !credential.LastLogin().Equal(attempt.Time) {
+		t.Errorf("A completed login must be the last login. Got: %s\n", credential.LastLogin())
+	}
+}
+
+func TestLoginHistoryLockedOut(t *testing.T) {
+	userRealm, _ := MakeHistoryExampleInstances()
+	userRealm.SetLockout(lockout.NewPolicy(1, time.Hour))
+	_, _ = userRealm.Login("U1", "user1$124")
+	if _, err := userRealm.Login("U1", "user1$123"); err == nil {
+		t.Fatal("Login must fail while locked out")
+	}
+
+	// Locked out logins are saved like the failed ones.
+	credential, _ := userRealm.ByIdentifier("U1")
+	if entries := credential.LoginHistory(); len(entries) != 2 || entries[0].Outcome != history.LockedOut || entries[1].Outcome != history.Failed {
+		t.Errorf("The locked out attempts must be saved. Got: %v\n", entries)
+	}
+	if credential.LastFailedLogin().IsZero() {
+		t.Error("A locked out login must be the last failed login")
+	}
+}
+
+func TestLoginHistorySaveFailure(t *testing.T) {
+	userRealm, broker := MakeHistoryExampleInstances()
+	broker.Err = errors.New("save failed")
+	if _, err := userRealm.Login("U1", "user1$123"); err != broker.Err {
+		t.Errorf("Login must fail when the history cannot be saved. Error: %v\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if len(credential.LoginHistory()) != 0 || !credential.LastLogin().IsZero() {
+		t.Error("The history must be rolled back")
+	}
+}
+
+func TestListInactive(t *testing.T) {
+	userRealm, _ := MakeHistoryExampleInstances()
+	since := time.Now()
+	_, _ = userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U2", "user2$124")
+
+	inactive, next, err := userRealm.ListInactive(since, credentials.Filter{}, "", 10)
+	if err != nil || next != "" {
+		t.Fatalf("The inactive users must be listed. Error: %v\n", err)
+	}
+	if len(inactive) != 1 || inactive[0].Identification() != "U2" {
+		t.Errorf("Only U2 must be inactive. Got: %v\n", inactive)
+	}
+	if inactive, _, _ := userRealm.ListInactive(time.Now().Add(time.Hour), credentials.Filter{}, "", 10); len(inactive) != 2 {
+		t.Errorf("Both users must be inactive since a later time. Got: %d\n", len(inactive))
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/instrumentation"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/login/password"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentedBroker(t *testing.T) {
+	registry := instrumentation.NewRegistry()
+	broker := instrumentation.WrapBroker(registry, "users", MakeUserExampleBroker())
+	userRealm := realms.NewRealm(credentials.NewSource(broker, &User{}), password.PasswordCheckingStep(0))
+
+	_, _ = userRealm.Login("U1", "user1$123")
+	_, _ = userRealm.Login("U9", "user9$123")
+	if user, err := userRealm.ByIndex(1); err != nil {
+		t.Errorf("The lookup by index must succeed. Got: %v\n", err)
+	} else if err := userRealm.SetPassword(user, "user1$123", nil); err != nil {
+		t.Errorf("The password change must succeed. Got: %v\n", err)
+	}
+	failingBroker := MakeUserExampleBroker()
+	failingBroker.Err = credentials.ErrConcurrentModification
+	failing := instrumentation.WrapBroker(registry, "users", failingBroker)
+	_ = failing.Save(&User{})
+
+	if calls, errors := registry.Counts(instrumentation.BrokerComponent, "users", "ByIdentifier"); calls != 2 || errors != 0 {
+		t.Errorf("Expected 2 lookups by identifier without errors. Got: %d, %d\n", calls, errors)
+	}
+	if calls, _ := registry.Counts(instrumentation.BrokerComponent, "users", "ByIndex"); calls != 1 {
+		t.Errorf("Expected 1 lookup by index. Got: %d\n", calls)
+	}
+	if calls, errors := registry.Counts(instrumentation.BrokerComponent, "users", "Save"); calls != 2 || errors != 1 {
+		t.Errorf("Expected 2 saves, one of them failed. Got: %d, %d\n", calls, errors)
+	}
+}
+
+func TestInstrumentedHashingEngine(t *testing.T) {
+	registry := instrumentation.NewRegistry()
+	engine := instrumentation.WrapHashingEngine(registry, "", DummyHasher(0))
+	if engine.Name() != DummyHasher(0).Name() {
+		t.Errorf("The wrapped engine must keep the underlying name. Got: %s\n", engine.Name())
+	}
+
+	hashed, _ := engine.Hash("secret")
+	_ = engine.Validate("secret", hashed)
+	_ = engine.Validate("wrong", hashed)
+	if calls, errors := registry.Counts(instrumentation.HashingComponent, engine.Name(), "Hash"); calls != 1 || errors != 0 {
+		t.Errorf("Expected 1 hash without errors. Got: %d, %d\n", calls, errors)
+	}
+	if calls, errors := registry.Counts(instrumentation.HashingComponent, engine.Name(), "Validate"); calls != 2 || errors != 1 {
+		t.Errorf("Expected 2 validations, one of them failed. Got: %d, %d\n", calls, errors)
+	}
+}
+
+func TestInstrumentationExposition(t *testing.T) {
+	registry := instrumentation.NewRegistry()
+	broker := instrumentation.WrapBroker(registry, "users", MakeUserExampleBroker())
+	engine := instrumentation.WrapHashingEngine(registry, "dummy", DummyHasher(0))
+	_, _ = broker.ByIdentifier("U1", &User{})
+	_, _ = engine.Hash("secret")
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	for _, line := range []string{
+		"# TYPE identity_broker_calls_total counter",
+		`identity_broker_calls_total{broker="users",operation="ByIdentifier"} 1`,
+		`identity_broker_errors_total{broker="users",operation="ByIdentifier"} 0`,
+		"# TYPE identity_broker_duration_seconds histogram",
+		`identity_broker_duration_seconds_bucket{broker="users",operation="ByIdentifier",le="+Inf"} 1`,
+		`identity_broker_duration_seconds_count{broker="users",operation="ByIdentifier"} 1`,
+		`identity_hashing_calls_total{engine="dummy",operation="Hash"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("The Prometheus output must contain: %s\nGot:\n%s", line, body)
+		}
+	}
+
+	var exported map[string]map[string]map[string]struct {
+		Calls  uint64 `json:"calls"`
+		Errors uint64 `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(registry.String()), &exported); err != nil {
+		t.Errorf("The expvar output must be valid JSON. Got: %v\n", err)
+	} else if exported["broker"]["users"]["ByIdentifier"].Calls != 1 {
+		t.Errorf("The expvar output must count the calls. Got: %s\n", registry.String())
+	}
+}
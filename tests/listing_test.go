@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/filtering"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/realms"
+	"testing"
+)
+
+func listedIdentifiers(list []credentials.Credential) []interface{} {
+	result := make([]interface{}, len(list))
+	for index, credential := range list {
+		result[index] = credential.(identified.Identified).Identification()
+	}
+	return result
+}
+
+func TestListAll(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+
+	if list, next, err := userRealm.List(credentials.Filter{}, "", 0); err != nil {
+		t.Errorf("Listing must not fail. Error: %s\n", err)
+	} else if len(list) != 5 || next != "" {
+		t.Errorf("Listing with no filter must return all the 5 users in one page. Got: %v (next: %q)\n", listedIdentifiers(list), next)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	userRealm := sampleRealms[1]
+
+	var all []interface{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatalf("Listing 5 users by pages of 2 must take 3 pages. Got so far: %v\n", all)
+		}
+		list, next, err := userRealm.List(credentials.Filter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("Listing must not fail. Error: %s\n", err)
+		}
+		all = append(all, listedIdentifiers(list)...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	expected := []interface{}{"U1", "U2", "U3", "U4", "U5"}
+	if len(all) != len(expected) {
+		t.Fatalf("Unexpected listing: %v\n", all)
+	}
+	for index := range all {
+		if all[index] != expected[index] {
+			t.Errorf("Unexpected listing: %v\n", all)
+			break
+		}
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	adminRealm := sampleRealms[0]
+	userRealm := sampleRealms[1]
+	yes := true
+	no := false
+
+	if list, _, _ := userRealm.List(credentials.Filter{Active: &no}, "", 0); len(list) != 1 {
+		t.Errorf("Only U2 is inactive. Got: %v\n", listedIdentifiers(list))
+	}
+	if list, _, _ := userRealm.List(credentials.Filter{Punished: &yes}, "", 0); len(list) != 2 {
+		t.Errorf("Only U4 and U5 are punished. Got: %v\n", listedIdentifiers(list))
+	}
+	if list, _, _ := userRealm.List(credentials.Filter{Staff: &yes}, "", 0); len(list) != 0 {
+		t.Errorf("No user is staff. Got: %v\n", listedIdentifiers(list))
+	}
+	if list, _, _ := adminRealm.List(credentials.Filter{Superuser: &no, Scope: DummyScope(3).Key()}, "", 0); len(list) != 2 {
+		t.Errorf("Only S1 and S3 are non-superusers with scope 3. Got: %v\n", listedIdentifiers(list))
+	}
+}
+
+func TestListPaginationWhileChanging(t *testing.T) {
+	broker := MakeUserExampleBroker()
+	userRealm := realms.NewRealm(credentials.NewSource(broker, &User{}))
+
+	first, next, _ := userRealm.List(credentials.Filter{}, "", 2)
+	// Removing a listed credential must not skip the next one.
+	_ = broker.Delete(first[0])
+	second, _, err := userRealm.List(credentials.Filter{}, next, 2)
+	if err != nil {
+		t.Fatalf("Listing must not fail. Error: %s\n", err)
+	}
+	if identifiers := listedIdentifiers(second); len(identifiers) != 2 || identifiers[0] != "U3" || identifiers[1] != "U4" {
+		t.Errorf("The next page must resume after the last listed credential. Got: %v\n", identifiers)
+	}
+	if _, _, err := userRealm.List(credentials.Filter{}, "not a cursor!", 2); err != filtering.ErrBadCursor {
+		t.Errorf("Listing with an invalid cursor must fail with filtering.ErrBadCursor. Error: %v\n", err)
+	}
+}
+
+func TestListNotSupported(t *testing.T) {
+	// The embedding hides the List method of the broker.
+	users := credentials.NewSource(struct{ credentials.Broker }{MakeUserExampleBroker()}, &User{})
+	userRealm := realms.NewRealm(users)
+
+	if _, _, err := userRealm.List(credentials.Filter{}, "", 0); err != credentials.ErrListingNotSupported {
+		t.Errorf("Listing through a non-lister broker must fail with credentials.ErrListingNotSupported. Error: %s\n", err)
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/filelock"
+	"github.com/universe-10th/identity/realms/login/punish"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockerSerializesMutations(t *testing.T) {
+	userRealm, broker := MakeLockingExampleInstances(realms.NewMemoryLocker())
+	credential, _ := userRealm.ByIdentifier("U1")
+
+	group := sync.WaitGroup{}
+	for index := 0; index < 8; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			_ = userRealm.PreparePasswordReset(credential, fmt.Sprintf("token%d", index), time.Hour, nil)
+		}(index)
+	}
+	group.Wait()
+	if broker.MaxInFlight != 1 {
+		t.Errorf("The saves of the same credential must not overlap. Max in flight: %d\n", broker.MaxInFlight)
+	}
+}
+
+func TestLockerChangePasswordRace(t *testing.T) {
+	// Each request loads its own copy (like a database), so the
+	// losing changes are only detected by checking the current
+	// password again on the reloaded credential.
+	userRealm, _ := MakeVersionedExampleInstances()
+	userRealm.SetLocker(realms.NewMemoryLocker())
+	userRealm.SetRetries(1)
+
+	group := sync.WaitGroup{}
+	mutex := sync.Mutex{}
+	succeeded := 0
+	for index := 0; index < 8; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			credential, _ := userRealm.ByIdentifier("U1")
+			if err := userRealm.ChangePassword(credential, "user1$123", fmt.Sprintf("user1$%d", index)); err == nil {
+				mutex.Lock()
+				succeeded++
+				mutex.Unlock()
+			} else if err != realms.ErrBadCurrentPassword {
+				t.Errorf("Losing password changes must fail with realms.ErrBadCurrentPassword. Error: %s\n", err)
+			}
+		}(index)
+	}
+	group.Wait()
+	if succeeded != 1 {
+		t.Errorf("Exactly one password change must succeed. Succeeded: %d\n", succeeded)
+	}
+}
+
+func TestRealmPunishment(t *testing.T) {
+	userRealm, _ := MakeLockingExampleInstances(realms.NewMemoryLocker())
+	credential, _ := userRealm.ByIdentifier("U1")
+
+	duration := time.Hour
+	if err := userRealm.Punish(credential, &duration, "Spamming", nil); err != nil {
+		t.Fatalf("Punishing a punishable credential must succeed. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err == nil {
+		t.Error("A punished credential must fail to login")
+	} else if _, ok := err.(*punish.PunishedError); !ok {
+		t.Errorf("A punished credential must fail with *punish.PunishedError. Error: %s\n", err)
+	}
+	if err := userRealm.Unpunish(credential, nil); err != nil {
+		t.Fatalf("Unpunishing a punishable credential must succeed. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("An unpunished credential must login. Error: %s\n", err)
+	}
+
+	_, realmsList := MakeUserExampleInstances()
+	admin, _ := realmsList[0].ByIdentifier("S1")
+	if err := realmsList[0].Punish(admin, nil, "Spamming", nil); err != realms.ErrNotPunishable {
+		t.Errorf("Punishing a non-punishable credential must fail with realms.ErrNotPunishable. Error: %v\n", err)
+	}
+}
+
+func TestFileLocker(t *testing.T) {
+	directory := t.TempDir()
+	first := filelock.New(directory, filelock.Options{})
+	second := filelock.New(directory, filelock.Options{Timeout: 30 * time.Millisecond})
+
+	unlock, err := first.Lock("*tests.User/1")
+	if err != nil {
+		t.Fatalf("Locking a free key must succeed. Error: %s\n", err)
+	}
+	if _, err := second.Lock("*tests.User/1"); err != filelock.ErrLockTimeout {
+		t.Errorf("Locking a held key must time out. Error: %v\n", err)
+	}
+	if otherUnlock, err := second.Lock("*tests.User/2"); err != nil {
+		t.Errorf("Locking another key must succeed. Error: %s\n", err)
+	} else {
+		otherUnlock()
+	}
+	unlock()
+	if unlock, err := second.Lock("*tests.User/1"); err != nil {
+		t.Errorf("Locking a released key must succeed. Error: %s\n", err)
+	} else {
+		unlock()
+	}
+}
+
+func TestFileLockerStaleLocks(t *testing.T) {
+	directory := t.TempDir()
+	first := filelock.New(directory, filelock.Options{})
+	second := filelock.New(directory, filelock.Options{StaleAfter: 20 * time.Millisecond})
+
+	// The first lock is not released in time (e.g. a hung process).
+	staleUnlock, err := first.Lock("*tests.User/1")
+	if err != nil {
+		t.Fatalf("Locking a free key must succeed. Error: %s\n", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	unlock, err := second.Lock("*tests.User/1")
+	if err != nil {
+		t.Fatalf("A stale lock must be broken. Error: %s\n", err)
+	}
+
+	// Releasing the broken lock must not release the new one.
+	staleUnlock()
+	third := filelock.New(directory, filelock.Options{Timeout: 30 * time.Millisecond})
+	if _, err := third.Lock("*tests.User/1"); err != filelock.ErrLockTimeout {
+		t.Errorf("Releasing a broken lock must keep the lock of the new owner. Error: %v\n", err)
+	}
+	unlock()
+}
+
+func TestFileLockerRefreshesLocks(t *testing.T) {
+	directory := t.TempDir()
+	options := filelock.Options{StaleAfter: 30 * time.Millisecond}
+	first := filelock.New(directory, options)
+	options.Timeout = 100 * time.Millisecond
+	second := filelock.New(directory, options)
+
+	// The first lock is held for longer than StaleAfter (e.g. a
+	// long mutation), but it is refreshed meanwhile.
+	unlock, err := first.Lock("*tests.User/1")
+	if err != nil {
+		t.Fatalf("Locking a free key must succeed. Error: %s\n", err)
+	}
+	if _, err := second.Lock("*tests.User/1"); err != filelock.ErrLockTimeout {
+		t.Errorf("A refreshed lock must not be broken. Error: %v\n", err)
+	}
+	unlock()
+	unlock()
+	if entries, _ := os.ReadDir(directory); len(entries) != 0 {
+		t.Errorf("Released locks must leave no files. Got: %d\n", len(entries))
+	}
+	if unlock, err := second.Lock("*tests.User/1"); err != nil {
+		t.Errorf("Locking a released key must succeed. Error: %s\n", err)
+	} else {
+		unlock()
+	}
+}
+
+func TestRealmWithFileLocker(t *testing.T) {
+	directory := t.TempDir()
+	userRealm, _ := MakeLockingExampleInstances(filelock.New(directory, filelock.Options{Timeout: 30 * time.Millisecond}))
+	credential, _ := userRealm.ByIdentifier("U1")
+
+	// Another process holds the lock of the credential.
+	unlock, _ := filelock.New(directory, filelock.Options{}).Lock("*tests.User/1")
+	if err := userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil); err != filelock.ErrLockTimeout {
+		t.Errorf("Mutating a locked credential must time out. Error: %v\n", err)
+	}
+	unlock()
+	if err := userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil); err != nil {
+		t.Errorf("Mutating an unlocked credential must succeed. Error: %s\n", err)
+	}
+}
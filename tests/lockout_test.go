@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/events"
+	"github.com/universe-10th/identity/realms/lockout"
+	"github.com/universe-10th/identity/realms/login"
+	"testing"
+	"time"
+)
+
+// Attempts a login at the given time.
+func loginAt(userRealm *realms.TypedRealm[*TwoFactorUser], identifier, password string, when time.Time) error {
+	attempt := login.NewAttempt(identifier, password)
+	attempt.Time = when
+	_, err := userRealm.LoginAttempt(attempt)
+	return err
+}
+
+func TestLockoutAfterFailures(t *testing.T) {
+	userRealm, broker := MakeLockoutExampleInstances(lockout.NewPolicy(3, time.Minute))
+	now := time.Now()
+
+	for index := 0; index < 2; index++ {
+		if err := loginAt(userRealm, "U1", "user1$124", now); err != realms.ErrLoginFailed {
+			t.Fatalf("Login with a bad password must fail with realms.ErrLoginFailed. Error: %v\n", err)
+		}
+	}
+	if err := loginAt(userRealm, "U1", "user1$124", now); err == nil {
+		t.Fatal("Login with a bad password must fail")
+	} else if lockedErr, ok := err.(*lockout.LockedOutError); !ok || lockedErr.RetryAfter != time.Minute {
+		t.Fatalf("The third failure must lock the user out for a minute. Error: %v\n", err)
+	}
+	if broker.SaveCalls != 3 {
+		t.Errorf("Each failure must be saved. Saves: %d\n", broker.SaveCalls)
+	}
+	if err := loginAt(userRealm, "U1", "user1$123", now.Add(30*time.Second)); err == nil {
+		t.Error("Login must fail while locked out, even with the right password")
+	} else if lockedErr, ok := err.(*lockout.LockedOutError); !ok || lockedErr.RetryAfter != 30*time.Second {
+		t.Errorf("Login must fail with the remaining lockout time. Error: %v\n", err)
+	}
+
+	// After the lockout, a successful login resets the count.
+	if err := loginAt(userRealm, "U1", "user1$123", now.Add(time.Minute)); err != nil {
+		t.Errorf("Login must succeed after the lockout. Error: %s\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if count, _ := credential.FailedLogins(); count != 0 {
+		t.Errorf("A successful login must reset the count. Count: %d\n", count)
+	}
+}
+
+func TestLockoutEscalation(t *testing.T) {
+	policy := lockout.NewPolicy(2, time.Minute)
+	policy.MaxDuration = 3 * time.Minute
+	userRealm, _ := MakeLockoutExampleInstances(policy)
+	now := time.Now()
+
+	for lockoutIndex, expected := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		_ = loginAt(userRealm, "U1", "user1$124", now)
+		if err, ok := loginAt(userRealm, "U1", "user1$124", now).(*lockout.LockedOutError); !ok || err.RetryAfter != expected {
+			t.Errorf("Lockout %d must last %s. Error: %v\n", lockoutIndex+1, expected, err)
+		}
+		now = now.Add(expected)
+	}
+	if policy.Duration(10) != 3*time.Minute {
+		t.Errorf("Lockouts must not last more than the maximum duration. Got: %s\n", policy.Duration(10))
+	}
+}
+
+func TestLockoutUnknownIdentifiers(t *testing.T) {
+	userRealm, _ := MakeLockoutExampleInstances(lockout.NewPolicy(3, time.Minute))
+	now := time.Now()
+
+	for _, identifier := range []string{"U1", "U9"} {
+		var errs []error
+		for index := 0; index < 4; index++ {
+			errs = append(errs, loginAt(userRealm, identifier, "user1$124", now))
+		}
+		if errs[0] != realms.ErrLoginFailed || errs[1] != realms.ErrLoginFailed {
+			t.Errorf("The first failures of %s must fail with realms.ErrLoginFailed. Errors: %v\n", identifier, errs)
+		}
+		for _, err := range errs[2:] {
+			if lockedErr, ok := err.(*lockout.LockedOutError); !ok || lockedErr.RetryAfter != time.Minute {
+				t.Errorf("The next failures of %s must be locked out for a minute. Errors: %v\n", identifier, errs)
+			}
+		}
+	}
+}
+
+func TestLockoutPunishment(t *testing.T) {
+	policy := lockout.NewPolicy(2, time.Hour)
+	policy.Punish = true
+	userRealm, _ := MakeLockoutExampleInstances(policy)
+
+	_, _ = userRealm.Login("U1", "user1$124")
+	if _, err := userRealm.Login("U1", "user1$124"); err == nil {
+		t.Fatal("Login with a bad password must fail")
+	} else if _, ok := err.(*lockout.LockedOutError); !ok {
+		t.Fatalf("The second failure must lock the user out. Error: %v\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if punishedOn, forTime, reason, _ := credential.PunishedFor(); punishedOn == nil || forTime == nil || *forTime != time.Hour || reason != lockout.TooManyFailures {
+		t.Fatalf("The user must be punished for an hour. Reason: %v\n", reason)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err == nil {
+		t.Error("Login must fail while punished")
+	} else if _, ok := err.(*lockout.LockedOutError); !ok {
+		t.Errorf("Login must fail with the lockout error while punished. Error: %v\n", err)
+	}
+
+	// Lifting the punishment lifts the lockout.
+	_ = userRealm.Unpunish(credential, nil)
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Login must succeed after the punishment is lifted. Error: %s\n", err)
+	}
+}
+
+func TestLockoutPunishmentEvents(t *testing.T) {
+	policy := lockout.NewPolicy(2, time.Hour)
+	policy.Punish = true
+	userRealm, _ := MakeLockoutExampleInstances(policy)
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+
+	_, _ = userRealm.Login("U1", "user1$124")
+	_, _ = userRealm.Login("U1", "user1$124")
+	var punished *events.Punished
+	for _, event := range recorder.Take() {
+		if event, ok := event.(*events.Punished); ok {
+			punished = event
+		}
+	}
+	if punished == nil || punished.Duration == nil || *punished.Duration != time.Hour || punished.Reason != lockout.TooManyFailures {
+		t.Errorf("The lockout must publish Punished. Got: %#v\n", punished)
+	}
+}
+
+func TestLockoutKeepsOtherPunishments(t *testing.T) {
+	policy := lockout.NewPolicy(2, time.Hour)
+	policy.Punish = true
+	userRealm, _ := MakeLockoutExampleInstances(policy)
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	forTime := 24 * time.Hour
+	_ = userRealm.Punish(credential, &forTime, "spam", nil)
+	recorder := &eventRecorder{}
+	userRealm.Subscribe(recorder.Handle)
+	for index := 0; index < 3; index++ {
+		_, _ = userRealm.Login("U1", "user1$124")
+	}
+	credential, _ = userRealm.ByIdentifier("U1")
+	if _, duration, reason, _ := credential.PunishedFor(); duration == nil || *duration != forTime || reason != "spam" {
+		t.Errorf("The other punishment must be kept. Reason: %v\n", reason)
+	}
+	for _, event := range recorder.Take() {
+		if _, ok := event.(*events.Punished); ok {
+			t.Error("No lockout punishment must be published while otherwise punished")
+		}
+	}
+}
+
+func TestLockoutPhantomEviction(t *testing.T) {
+	policy := lockout.NewPolicy(3, time.Minute)
+	policy.MaxPhantoms = 2
+	now := time.Now()
+
+	// The other identifiers filling the table do not stop the
+	// counts of a new one.
+	_, _ = policy.FailPhantom("A", now)
+	_, _ = policy.FailPhantom("B", now)
+	for index := 0; index < 2; index++ {
+		if _, locked := policy.FailPhantom("C", now); locked {
+			t.Fatal("An unknown identifier must not be locked out before the threshold")
+		}
+	}
+	if _, locked := policy.FailPhantom("C", now); !locked {
+		t.Error("An unknown identifier must be locked out even when the table was full")
+	}
+	if _, locked := policy.CheckPhantom("A", now); locked {
+		t.Error("The least recently failed identifier must be forgotten")
+	}
+}
+
+func TestLockoutSaveFailure(t *testing.T) {
+	userRealm, broker := MakeLockoutExampleInstances(lockout.NewPolicy(3, time.Minute))
+	broker.Err = errors.New("save failed")
+	if _, err := userRealm.Login("U1", "user1$124"); err != broker.Err {
+		t.Errorf("Login must fail with the save error. Error: %v\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if count, _ := credential.FailedLogins(); count != 0 {
+		t.Errorf("The count must be rolled back. Count: %d\n", count)
+	}
+
+}
+
+func TestLockoutLikeUnknownIdentifiers(t *testing.T) {
+	userRealm, broker := MakeLockoutExampleInstances(lockout.NewPolicy(3, time.Minute))
+
+	// Inactive users (rejected by a step not counting failures)
+	// get locked out like the unknown identifiers, without
+	// saving them.
+	for _, identifier := range []string{"U2", "U9"} {
+		saves := broker.SaveCalls
+		var errs []error
+		for index := 0; index < 4; index++ {
+			_, err := userRealm.Login(identifier, "user2$123")
+			errs = append(errs, err)
+		}
+		if errs[0] != realms.ErrLoginFailed || errs[1] != realms.ErrLoginFailed {
+			t.Errorf("The first failures of %s must fail with realms.ErrLoginFailed. Errors: %v\n", identifier, errs)
+		}
+		for _, err := range errs[2:] {
+			if _, ok := err.(*lockout.LockedOutError); !ok {
+				t.Errorf("The next failures of %s must be locked out. Errors: %v\n", identifier, errs)
+			}
+		}
+		if broker.SaveCalls != saves {
+			t.Errorf("The failures of %s must not be saved. Saves: %d\n", identifier, broker.SaveCalls-saves)
+		}
+	}
+}
+
+func TestLockoutWithoutFailureCounting(t *testing.T) {
+	userRealm, _ := MakeFailingExampleInstances()
+	userRealm.SetLockout(lockout.NewPolicy(2, time.Minute))
+
+	// Credentials not counting failures are never locked out,
+	// and neither are the unknown identifiers.
+	for _, identifier := range []string{"U1", "U9"} {
+		for index := 0; index < 4; index++ {
+			if _, err := userRealm.Login(identifier, "user1$124"); err != realms.ErrLoginFailed {
+				t.Errorf("Login of %s must fail with realms.ErrLoginFailed. Error: %v\n", identifier, err)
+			}
+		}
+	}
+}
+
+func TestLockoutPhantomCost(t *testing.T) {
+	userRealm, broker := MakeLockoutExampleInstances(lockout.NewPolicy(10, time.Minute))
+	broker.Delay = 30 * time.Millisecond
+	_, _ = userRealm.Login("U1", "user1$124")
+
+	// The failures of unknown identifiers take as long as the
+	// saved ones.
+	started := time.Now()
+	_, _ = userRealm.Login("U9", "user1$124")
+	if elapsed := time.Since(started); elapsed < 20*time.Millisecond {
+		t.Errorf("The failure of an unknown identifier must take as long as a saved one. Took: %s\n", elapsed)
+	}
+}
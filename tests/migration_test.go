@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/brokers/migration"
+	"github.com/universe-10th/identity/realms"
+	"testing"
+	"time"
+)
+
+func TestMigrationOnLogin(t *testing.T) {
+	userRealm, primary, legacy := MakeMigrationExampleInstances(true)
+
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Fatalf("Login for legacy user U1 must succeed. Error: %s\n", err)
+	}
+	if !primary.Has("U1") {
+		t.Error("After a successful login, U1 must be in the primary store")
+	}
+	if legacy.Has("U1") {
+		t.Error("After a successful login, U1 must be deleted from the legacy store")
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Login for migrated user U1 must succeed. Error: %s\n", err)
+	}
+}
+
+func TestMigrationKeepingLegacy(t *testing.T) {
+	userRealm, primary, legacy := MakeMigrationExampleInstances(false)
+
+	_, _ = userRealm.Login("U1", "user1$123")
+	if !primary.Has("U1") || !legacy.Has("U1") {
+		t.Error("After a successful login, U1 must be in both stores")
+	}
+}
+
+func TestMigrationNotOnFailedLogin(t *testing.T) {
+	userRealm, primary, legacy := MakeMigrationExampleInstances(true)
+
+	if _, err := userRealm.Login("U1", "user1$124"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for legacy user U1 must fail with an invalid password. Error: %s\n", err)
+	}
+	if primary.Has("U1") || !legacy.Has("U1") {
+		t.Error("After a failed login, U1 must remain only in the legacy store")
+	}
+	if _, err := userRealm.Login("U2", "user2$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for unknown user U2 must fail with realm.ErrLoginFailed. Error: %s\n", err)
+	}
+}
+
+func TestMigrationOnSave(t *testing.T) {
+	userRealm, primary, _ := MakeMigrationExampleInstances(true)
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.SetPassword(credential, "user1$456", nil); err != nil {
+		t.Fatalf("Saving a legacy credential must succeed. Error: %s\n", err)
+	}
+	if !primary.Has("U1") {
+		t.Error("After saving, U1 must be in the primary store")
+	}
+	if _, err := userRealm.Login("U1", "user1$456"); err != nil {
+		t.Errorf("Login for migrated user U1 with the new password must succeed. Error: %s\n", err)
+	}
+}
+
+func TestMigrationDeleteFailure(t *testing.T) {
+	userRealm, primary, legacy := MakeMigrationExampleInstances(true)
+
+	legacy.Err = errSaveFailed
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Failing to delete the legacy credential must not fail the login. Error: %s\n", err)
+	}
+	if !primary.Has("U1") || !legacy.Has("U1") {
+		t.Error("The credential must be migrated, and kept in the legacy store")
+	}
+}
+
+func TestMigrationPendingLimit(t *testing.T) {
+	hashed, _ := DummyHasher(0).Hash("user1$123")
+	primary := NewMemoryBroker(&User{})
+	legacy := NewMemoryBroker().Put(
+		&User{BaseUser: BaseUser{identifier: "U1", index: 1, active: true, hashedPassword: hashed}},
+		&User{BaseUser: BaseUser{identifier: "U2", index: 2, active: true, hashedPassword: hashed}},
+	)
+	broker := migration.NewBroker(primary, legacy, &User{}, func(legacyCred credentials.Credential) (credentials.Credential, error) {
+		user := *legacyCred.(*User)
+		return &user, nil
+	}, false)
+	broker.SetPendingLimits(time.Hour, 1)
+
+	first, _ := broker.ByIdentifier("U1", &User{})
+	_, _ = broker.ByIdentifier("U2", &User{})
+	if err := broker.Migrate(first); err != nil || primary.Has("U1") {
+		t.Errorf("The oldest pending credential must be forgotten beyond the limit. Error: %v\n", err)
+	}
+
+	broker.SetPendingLimits(time.Millisecond, 0)
+	second, _ := broker.ByIdentifier("U2", &User{})
+	time.Sleep(5 * time.Millisecond)
+	if err := broker.Migrate(second); err != nil || primary.Has("U2") {
+		t.Errorf("Expired pending credentials must be forgotten. Error: %v\n", err)
+	}
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/realms"
+	"testing"
+)
+
+func TestMultiLookupByUsername(t *testing.T) {
+	userRealm, emailLookups := MakeMultiLookupExampleInstances()
+
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Login for user U1 by username must succeed. Error: %s\n", err)
+	} else if *emailLookups != 0 {
+		t.Errorf("Login by username must not try the email lookup. Email lookups: %d\n", *emailLookups)
+	}
+}
+
+func TestMultiLookupByEmail(t *testing.T) {
+	userRealm, _ := MakeMultiLookupExampleInstances()
+
+	if _, err := userRealm.Login("u1@example.com", "user1$123"); err != nil {
+		t.Errorf("Login for user U1 by email must succeed. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("u1@example.com", "user1$124"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for user U1 by email must fail with an invalid password. Current error: %s\n", err)
+	}
+}
+
+func TestMultiLookupUnknownEmail(t *testing.T) {
+	userRealm, emailLookups := MakeMultiLookupExampleInstances()
+
+	if _, err := userRealm.Login("u9@example.com", "user1$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for an unknown email must fail with realm.ErrLoginFailed. Current error: %s\n", err)
+	}
+	// The direct lookup also accepts emails, and it comes first.
+	// It finds nothing, but the email lookup must run anyway.
+	if *emailLookups != 1 {
+		t.Errorf("Every accepting lookup must be tried exactly once. Email lookups: %d\n", *emailLookups)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/passcode"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Returns the code of the last message sent to the outbox.
+func lastPasscode(t *testing.T, path string) passcode.Message {
+	messages, err := passcode.ReadOutbox(path)
+	if err != nil || len(messages) == 0 {
+		t.Fatalf("A message must be in the outbox. Error: %v\n", err)
+	}
+	return messages[len(messages)-1]
+}
+
+// Enrolls the U1 credential to receive passcodes.
+func enrollPasscode(t *testing.T, userRealm *realms.TypedRealm[*TwoFactorUser]) {
+	credential, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.SetPasscodeEnrolled(credential, true); err != nil {
+		t.Fatalf("The credential must be enrolled. Error: %s\n", err)
+	}
+}
+
+func TestPasscodeSecondFactor(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	userRealm, broker := MakeTwoFactorExampleInstances(passcode.NewFactor(&passcode.FileNotifier{Path: outbox}))
+
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("A login must not require the passcode before enrolling. Error: %v\n", err)
+	}
+	enrollPasscode(t, userRealm)
+	saves := broker.SaveCalls
+	_, err := userRealm.Login("U1", "user1$123")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("A login must require the passcode. Error: %v\n", err)
+	}
+	message := lastPasscode(t, outbox)
+	credential, _ := userRealm.ByIdentifier("U1")
+	hashed, _, _ := credential.Passcode()
+	if message.To != "U1" || len(message.Code) != passcode.DefaultDigits {
+		t.Errorf("Unexpected message: %+v\n", message)
+	} else if hashed == "" || hashed == message.Code || broker.SaveCalls != saves+1 {
+		t.Errorf("The passcode must be hashed and saved before sending it. Stored: %s\n", hashed)
+	}
+
+	if _, err := userRealm.CompleteLogin(required.Handle, "bad"); err != twofactor.ErrBadCode {
+		t.Errorf("A bad passcode must fail with twofactor.ErrBadCode. Error: %v\n", err)
+	} else if _, _, attempts := credential.Passcode(); attempts != 1 || broker.SaveCalls != saves+2 {
+		t.Errorf("The failed attempt must be counted and saved. Attempts: %d\n", attempts)
+	}
+	if _, err := userRealm.CompleteLogin(required.Handle, message.Code); err != nil {
+		t.Fatalf("The passcode must be accepted. Error: %s\n", err)
+	} else if hashed, _, _ := credential.Passcode(); hashed != "" {
+		t.Error("The passcode must be consumed")
+	}
+
+	// Disabling the passcodes makes the login single-staged again.
+	if err := userRealm.SetPasscodeEnrolled(credential, false); err != nil {
+		t.Fatalf("The passcodes must be disabled. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("A login must not require the passcode after disabling it. Error: %v\n", err)
+	}
+}
+
+func TestPasscodeLimits(t *testing.T) {
+	now := time.Now()
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	factor := passcode.NewFactor(&passcode.FileNotifier{Path: outbox})
+	factor.MaxAttempts = 2
+	factor.Now = func() time.Time { return now }
+	userRealm, _ := MakeTwoFactorExampleInstances(factor)
+	enrollPasscode(t, userRealm)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	code := lastPasscode(t, outbox).Code
+	_, _ = userRealm.CompleteLogin(handle, "bad")
+	_, _ = userRealm.CompleteLogin(handle, "bad")
+	if _, err := userRealm.CompleteLogin(handle, code); err != twofactor.ErrBadCode {
+		t.Errorf("A passcode must not be accepted after running out of attempts. Error: %v\n", err)
+	}
+
+	_, err = userRealm.Login("U1", "user1$123")
+	handle = err.(*realms.SecondFactorRequiredError).Handle
+	code = lastPasscode(t, outbox).Code
+	now = now.Add(passcode.DefaultTTL)
+	if _, err := userRealm.CompleteLogin(handle, code); err != twofactor.ErrBadCode {
+		t.Errorf("An expired passcode must fail with twofactor.ErrBadCode. Error: %v\n", err)
+	}
+}
+
+func TestPasscodeFactorDefaults(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	userRealm, _ := MakeTwoFactorExampleInstances(&passcode.Factor{Notifier: &passcode.FileNotifier{Path: outbox}})
+	enrollPasscode(t, userRealm)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	message := lastPasscode(t, outbox)
+	if len(message.Code) != passcode.DefaultDigits || !message.ExpiresAt.After(time.Now()) {
+		t.Errorf("A factor without settings must use the default ones. Got: %+v\n", message)
+	}
+	if _, err := userRealm.CompleteLogin(handle, message.Code); err != nil {
+		t.Errorf("A factor without settings must accept the passcodes. Error: %v\n", err)
+	}
+}
+
+func TestPasswordlessLogin(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	factor := passcode.NewFactor(&passcode.FileNotifier{Path: outbox})
+	factor.Passwordless = true
+	userRealm, _ := MakePasswordlessExampleInstances(factor)
+
+	_, err := userRealm.Login("U1", "")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("A passwordless login must require the passcode. Error: %v\n", err)
+	}
+	if credential, err := userRealm.CompleteLogin(required.Handle, lastPasscode(t, outbox).Code); err != nil {
+		t.Errorf("The passcode must be accepted. Error: %s\n", err)
+	} else if credential.Identification() != "U1" {
+		t.Errorf("Unexpected credential: %v\n", credential.Identification())
+	}
+
+	// The pipeline still applies: inactive credentials get no passcode.
+	if _, err := userRealm.Login("U2", ""); err == nil {
+		t.Error("An inactive credential must fail to login")
+	} else if _, ok := err.(*realms.SecondFactorRequiredError); ok {
+		t.Error("An inactive credential must not be sent a passcode")
+	}
+	if messages, _ := passcode.ReadOutbox(outbox); len(messages) != 1 {
+		t.Errorf("Only one passcode must be sent. Got: %d\n", len(messages))
+	}
+}
+
+func TestPasswordlessUnknownIdentifier(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	factor := passcode.NewFactor(&passcode.FileNotifier{Path: outbox})
+	factor.Passwordless = true
+	userRealm, _ := MakePasswordlessExampleInstances(factor)
+
+	// Unknown identifiers get a challenge like the known ones,
+	// but nothing is sent and no code is accepted.
+	_, err := userRealm.Login("U9", "")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("An unknown identifier must get a fake challenge. Error: %v\n", err)
+	} else if len(required.Factors) != 1 || required.Factors[0] != factor.Name() {
+		t.Errorf("The fake challenge must tell the same factors. Got: %v\n", required.Factors)
+	}
+	if messages, _ := passcode.ReadOutbox(outbox); len(messages) != 0 {
+		t.Errorf("No passcode must be sent for an unknown identifier. Got: %d\n", len(messages))
+	}
+	for attempt := 0; attempt < realms.DefaultChallengeAttempts; attempt++ {
+		if _, err := userRealm.CompleteLogin(required.Handle, "000000"); err != twofactor.ErrBadCode {
+			t.Errorf("A fake challenge must reject the codes with twofactor.ErrBadCode. Error: %v\n", err)
+		}
+	}
+	if _, err := userRealm.CompleteLogin(required.Handle, "000000"); err != realms.ErrBadChallenge {
+		t.Errorf("A fake challenge must run out of attempts. Error: %v\n", err)
+	}
+}
@@ -15,7 +15,7 @@ func TestSetPassword(t *testing.T) {
 	userRealm := sampleRealms[1]
 
 	credential, _ := userRealm.Login("U1", "user1$123")
-	_ = userRealm.SetPassword(credential, "user1$456")
+	_ = userRealm.SetPassword(credential, "user1$456", nil)
 
 	if _, err := userRealm.Login("U1", "user1$123"); err != realms.ErrLoginFailed {
 		t.Errorf("After password change, the old password attempt must return realm.ErrLoginFailed. Error returned instead: %s\n", err)
@@ -31,7 +31,7 @@ func TestUnsetPassword(t *testing.T) {
 	userRealm := sampleRealms[1]
 
 	credential, _ := userRealm.Login("U1", "user1$123")
-	_ = userRealm.UnsetPassword(credential)
+	_ = userRealm.UnsetPassword(credential, nil)
 
 	if _, err := userRealm.Login("U1", "user1$123"); err != realms.ErrLoginFailed {
 		t.Errorf("After password change, the old password attempt must return realm.ErrLoginFailed. Error returned instead: %s\n", err)
@@ -53,7 +53,7 @@ func TestPasswordResetWithExpiredToken(t *testing.T) {
 	userRealm := sampleRealms[1]
 
 	credential, _ := userRealm.Login("U1", "user1$123")
-	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Second)
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Second, nil)
 	time.Sleep(2 * time.Second)
 	if err := userRealm.ConfirmPasswordReset(credential, "abc123", "new-password"); err != realms.ErrBadToken {
 		t.Errorf("Trying to reset the password using an expired token must return realm.ErrBadToken. Error returned instead: %s\n", err)
@@ -65,7 +65,7 @@ func TestPasswordResetSuccess(t *testing.T) {
 	userRealm := sampleRealms[1]
 
 	credential, _ := userRealm.Login("U1", "user1$123")
-	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour)
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
 	if err := userRealm.ConfirmPasswordReset(credential, "abc123", "user1$456"); err != nil {
 		t.Errorf("Trying to reset the password using a good token must return no error. Error returned instead: %s\n", err)
 	}
@@ -85,7 +85,7 @@ func TestPasswordResetCancelWithNoToken(t *testing.T) {
 	userRealm := sampleRealms[1]
 
 	credential, _ := userRealm.Login("U1", "user1$123")
-	if err := userRealm.CancelPasswordReset(credential); err != nil {
+	if err := userRealm.CancelPasswordReset(credential, nil); err != nil {
 		t.Errorf("Cancelling a password reset should not return an error on itself. Error returned instead: %s\n", err)
 	}
 
@@ -100,8 +100,8 @@ func TestPasswordResetCancelWithToken(t *testing.T) {
 
 	credential, _ := userRealm.Login("U1", "user1$123")
 
-	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour)
-	if err := userRealm.CancelPasswordReset(credential); err != nil {
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	if err := userRealm.CancelPasswordReset(credential, nil); err != nil {
 		t.Errorf("Cancelling a password reset should not return an error on itself. Error returned instead: %s\n", err)
 	}
 
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/portability"
+	"github.com/universe-10th/identity/credentials/traits/history"
+	"github.com/universe-10th/identity/credentials/traits/webauthn"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/login/activity"
+	"github.com/universe-10th/identity/realms/login/password"
+	"github.com/universe-10th/identity/realms/login/punish"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportWithoutSecrets(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	credential, _ := sampleRealms[1].ByIdentifier("U4")
+	_ = sampleRealms[1].PreparePasswordReset(credential, "abc123", time.Hour, nil)
+
+	data, err := portability.Marshal(credential, false)
+	if err != nil {
+		t.Fatalf("Exporting must not fail. Error: %s\n", err)
+	}
+	if strings.Contains(string(data), "Hashed[") || strings.Contains(string(data), "abc123") {
+		t.Errorf("Secrets must not be exported. Exported: %s\n", data)
+	}
+
+	document := portability.Export(credential, false)
+	if document.Identification != "U4" || document.Index != 4 || document.Active == nil || !*document.Active {
+		t.Errorf("Unexpected exported document: %+v\n", document)
+	} else if document.Punishment == nil || document.Punishment.Until == nil || document.Punishment.By != "S1" {
+		t.Errorf("Unexpected exported punishment: %+v\n", document.Punishment)
+	} else if document.Recovery == nil || !document.Recovery.Pending || document.Recovery.Token != "" {
+		t.Errorf("Unexpected exported recovery state: %+v\n", document.Recovery)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	target := NewMemoryBroker(&User{})
+	importer := &portability.Importer{
+		Broker: target,
+		Factory: func(document *portability.Document) (credentials.Credential, error) {
+			return &User{BaseUser: BaseUser{
+				identifier: document.Identification.(string), index: int(document.Index.(float64)),
+			}}, nil
+		},
+	}
+
+	for _, identifier := range []string{"U1", "U2", "U4"} {
+		credential, _ := sampleRealms[1].ByIdentifier(identifier)
+		data, _ := portability.Marshal(credential, true)
+		if document, err := portability.Unmarshal(data); err != nil {
+			t.Fatalf("Parsing an exported document must not fail. Error: %s\n", err)
+		} else if _, err := importer.Import(document); err != nil {
+			t.Fatalf("Importing a document must not fail. Error: %s\n", err)
+		}
+	}
+
+	users := credentials.NewSource(target, &User{})
+	userRealm := realms.NewRealm(users, activity.ActivityStep(0), password.PasswordCheckingStep(0), &punish.PunishmentCheckStep{TimeFormat: "2006-01-02T15:04:05"})
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Login for imported user U1 must succeed. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U2", "user2$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for imported user U2 must fail since it is inactive. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U4", "user4$123"); err == nil {
+		t.Error("Login for imported user U4 must fail since it is punished")
+	} else if _, ok := err.(*punish.PunishedError); !ok {
+		t.Errorf("Login for imported user U4 must fail with PunishedError. Error: %s\n", err)
+	}
+}
+
+func TestExportImportTraitData(t *testing.T) {
+	userRealm, _ := MakeHistoryExampleInstances()
+	credential, _ := userRealm.ByIdentifier("U1")
+	now := time.Now().UTC().Truncate(time.Second)
+	entries := []history.Entry{{Time: now, ClientIP: "192.0.2.1", UserAgent: "agent", DeviceID: "device", Outcome: history.Succeeded}}
+	credential.SetLoginHistory(now, time.Time{}, entries)
+	registered := []webauthn.PublicKeyCredential{{ID: []byte{1, 2}, PublicKey: []byte{3, 4}, SignCount: 5}}
+	credential.SetWebAuthnCredentials(registered)
+	credential.SetTOTPSecret([]byte("totp-secret"), true)
+	credential.SetTOTPLastStep(42)
+
+	document := portability.Export(credential, false)
+	if document.History == nil || document.History.LastLogin == nil || document.History.LastFailedLogin != nil ||
+		len(document.History.Entries) != 1 || document.History.Entries[0].ClientIP != "192.0.2.1" {
+		t.Errorf("The login history must be exported. Got: %+v\n", document.History)
+	}
+	if len(document.WebAuthn) != 1 || document.WebAuthn[0].SignCount != 5 {
+		t.Errorf("The WebAuthn credentials must be exported. Got: %+v\n", document.WebAuthn)
+	}
+	if document.TOTP == nil || !document.TOTP.Enrolled || document.TOTP.Secret != nil || document.TOTP.LastStep != 0 {
+		t.Errorf("The TOTP enrollment must be exported without its secret. Got: %+v\n", document.TOTP)
+	}
+
+	target := NewMemoryBroker(&TrackedUser{})
+	importer := &portability.Importer{
+		Broker: target,
+		Factory: func(document *portability.Document) (credentials.Credential, error) {
+			return &TrackedUser{TwoFactorUser: TwoFactorUser{User: User{BaseUser: BaseUser{
+				identifier: document.Identification.(string), index: int(document.Index.(float64)),
+			}}}}, nil
+		},
+	}
+	data, _ := portability.Marshal(credential, true)
+	parsed, err := portability.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Parsing an exported document must not fail. Error: %s\n", err)
+	}
+	imported, err := importer.Import(parsed)
+	if err != nil {
+		t.Fatalf("Importing a document must not fail. Error: %s\n", err)
+	}
+	user := imported.(*TrackedUser)
+	if !user.LastLogin().Equal(now) || !user.LastFailedLogin().IsZero() || !reflect.DeepEqual(user.LoginHistory(), entries) {
+		t.Errorf("The login history must be imported. Got: %v, %v, %+v\n", user.LastLogin(), user.LastFailedLogin(), user.LoginHistory())
+	}
+	if !reflect.DeepEqual(user.WebAuthnCredentials(), registered) {
+		t.Errorf("The WebAuthn credentials must be imported. Got: %+v\n", user.WebAuthnCredentials())
+	}
+	if secret, enrolled := user.TOTPSecret(); string(secret) != "totp-secret" || !enrolled || user.TOTPLastStep() != 42 {
+		t.Errorf("The TOTP enrollment must be imported. Got: %q, %v, %d\n", secret, enrolled, user.TOTPLastStep())
+	}
+}
+
+func TestExportExpiredToken(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	credential, _ := sampleRealms[1].ByIdentifier("U1")
+	user := credential.(*User)
+	user.SetRecoveryToken("abc123", -time.Minute)
+
+	if document := portability.Export(credential, true); document.Recovery == nil || document.Recovery.Pending || document.Recovery.Token != "" {
+		t.Errorf("Expired recovery tokens must not be exported. Got: %+v\n", document.Recovery)
+	}
+	if user.recoveryToken != "abc123" {
+		t.Error("Exporting must not clear the expired recovery token")
+	}
+}
+
+func TestImportUnsupportedVersion(t *testing.T) {
+	if _, err := portability.Unmarshal([]byte(`{"version": 99}`)); err != portability.ErrUnsupportedVersion {
+		t.Errorf("Parsing a document of an unsupported version must fail with ErrUnsupportedVersion. Error: %s\n", err)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/ratelimit"
+	"net"
+	"testing"
+	"time"
+)
+
+// Attempts a login from an address, at the given time.
+func loginFrom(userRealm *realms.Realm, identifier, password, address string, when time.Time) error {
+	attempt := login.NewAttempt(identifier, password)
+	attempt.ClientIP = net.ParseIP(address)
+	attempt.Time = when
+	_, err := userRealm.LoginAttempt(attempt)
+	return err
+}
+
+// Tells the scope and retry-after of a rate limiting error.
+func rateLimited(err error) (string, time.Duration) {
+	if limitedErr, ok := err.(*ratelimit.RateLimitedError); ok {
+		return limitedErr.Scope, limitedErr.RetryAfter
+	}
+	return "", 0
+}
+
+func TestRateLimitByIdentifier(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	limiter.PerIdentifier = &ratelimit.Limit{Burst: 2, Interval: time.Minute}
+	userRealm, broker := MakeRateLimitExampleInstances(limiter)
+	now := time.Now()
+
+	for _, identifier := range []string{"U1", "U9"} {
+		for index := 0; index < 2; index++ {
+			if err := loginFrom(userRealm, identifier, "user1$124", "192.0.2.1", now); err != realms.ErrLoginFailed {
+				t.Errorf("The first attempts of %s must not be limited. Error: %v\n", identifier, err)
+			}
+		}
+		calls := broker.ByIdentifierCalls
+		if scope, retryAfter := rateLimited(loginFrom(userRealm, identifier, "user1$123", "192.0.2.1", now)); scope != ratelimit.IdentifierScope || retryAfter != time.Minute {
+			t.Errorf("The third attempt of %s must be limited for a minute. Scope: %q, retry after: %s\n", identifier, scope, retryAfter)
+		}
+		if broker.ByIdentifierCalls != calls {
+			t.Errorf("Limited attempts of %s must not look the credential up\n", identifier)
+		}
+	}
+
+	// Tokens are regained over time.
+	if scope, retryAfter := rateLimited(loginFrom(userRealm, "U1", "user1$123", "192.0.2.1", now.Add(30*time.Second))); retryAfter != 30*time.Second {
+		t.Errorf("The attempt must be limited for half a minute. Scope: %q, retry after: %s\n", scope, retryAfter)
+	}
+	if err := loginFrom(userRealm, "U1", "user1$123", "192.0.2.1", now.Add(time.Minute)); err != nil {
+		t.Errorf("The attempt must be allowed after a minute. Error: %s\n", err)
+	}
+	if err := loginFrom(userRealm, "U2", "user2$123", "192.0.2.1", now); err != realms.ErrLoginFailed {
+		t.Errorf("Other identifiers must not be limited. Error: %v\n", err)
+	}
+}
+
+func TestRateLimitByAddress(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	limiter.PerAddress = &ratelimit.Limit{Burst: 1, Interval: time.Minute}
+	limiter.PerSubnet = &ratelimit.Limit{Burst: 2, Interval: time.Minute}
+	userRealm, _ := MakeRateLimitExampleInstances(limiter)
+	now := time.Now()
+
+	if err := loginFrom(userRealm, "U1", "user1$123", "192.0.2.1", now); err != nil {
+		t.Errorf("The first attempt must be allowed. Error: %s\n", err)
+	}
+	if scope, _ := rateLimited(loginFrom(userRealm, "U1", "user1$123", "192.0.2.1", now)); scope != ratelimit.AddressScope {
+		t.Errorf("The second attempt from the same address must be limited. Scope: %q\n", scope)
+	}
+	if err := loginFrom(userRealm, "U1", "user1$123", "192.0.2.2", now); err != nil {
+		t.Errorf("An attempt from another address must be allowed. Error: %s\n", err)
+	}
+	if scope, _ := rateLimited(loginFrom(userRealm, "U1", "user1$123", "192.0.2.3", now)); scope != ratelimit.SubnetScope {
+		t.Errorf("Attempts from the same subnet must be limited. Scope: %q\n", scope)
+	}
+	if err := loginFrom(userRealm, "U1", "user1$123", "198.51.100.1", now); err != nil {
+		t.Errorf("An attempt from another subnet must be allowed. Error: %s\n", err)
+	}
+	if err := loginFrom(userRealm, "U1", "user1$123", "2001:db8::1", now); err != nil {
+		t.Errorf("An IPv6 attempt must be allowed. Error: %s\n", err)
+	}
+	if scope, _ := rateLimited(loginFrom(userRealm, "U1", "user1$123", "2001:db8::1", now)); scope != ratelimit.AddressScope {
+		t.Errorf("The second IPv6 attempt must be limited. Scope: %q\n", scope)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Attempts without an address must not be limited by address. Error: %s\n", err)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(key string, limit ratelimit.Limit, now time.Time) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestRateLimitGlobal(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	limiter.Global = &ratelimit.Limit{Burst: 2, Interval: time.Second}
+	userRealm, _ := MakeRateLimitExampleInstances(limiter)
+	now := time.Now()
+
+	_ = loginFrom(userRealm, "U1", "user1$123", "192.0.2.1", now)
+	_ = loginFrom(userRealm, "U3", "user3$123", "198.51.100.1", now)
+	if scope, retryAfter := rateLimited(loginFrom(userRealm, "U5", "user5$123", "203.0.113.1", now)); scope != ratelimit.GlobalScope || retryAfter != time.Second {
+		t.Errorf("The third attempt must be limited globally. Scope: %q, retry after: %s\n", scope, retryAfter)
+	}
+
+	limiter.Store = failingStore{}
+	if _, err := userRealm.Login("U1", "user1$123"); err == nil || err.Error() != "store unavailable" {
+		t.Errorf("The errors of the store must be returned. Error: %v\n", err)
+	}
+}
+
+func TestMemoryStorePruning(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	store.MaxBuckets = 2
+	limit := ratelimit.Limit{Burst: 1, Interval: time.Minute}
+	now := time.Now()
+
+	_, _, _ = store.Take("a", limit, now)
+	_, _, _ = store.Take("b", limit, now)
+	// After a minute, both buckets are full again and may be
+	// forgotten, while "c" is taken normally.
+	if allowed, _, _ := store.Take("c", limit, now.Add(time.Minute)); !allowed {
+		t.Error("A new bucket must allow the attempt")
+	}
+	if allowed, _, _ := store.Take("a", limit, now.Add(time.Minute)); !allowed {
+		t.Error("A refilled bucket must allow the attempt")
+	}
+	if allowed, retryAfter, _ := store.Take("a", limit, now.Add(time.Minute)); allowed || retryAfter != time.Minute {
+		t.Errorf("An empty bucket must reject the attempt. Retry after: %s\n", retryAfter)
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/recoverycodes"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecoveryCodesGeneration(t *testing.T) {
+	userRealm, _ := MakeTwoFactorExampleInstances(recoverycodes.Factor(0))
+	credential, _ := userRealm.ByIdentifier("U1")
+	codes, err := userRealm.RegenerateRecoveryCodes(credential, 0)
+	if err != nil {
+		t.Fatalf("The codes must be generated. Error: %s\n", err)
+	} else if len(codes) != recoverycodes.DefaultCount {
+		t.Errorf("Expected %d codes. Got: %d\n", recoverycodes.DefaultCount, len(codes))
+	}
+	for index, hashed := range credential.RecoveryCodes() {
+		if hashed == codes[index] || credential.Hasher().Validate(recoverycodes.Normalize(codes[index]), hashed) != nil {
+			t.Errorf("The codes must be hashed through the credential's hasher. Got: %s\n", hashed)
+		}
+	}
+
+	// Recovery codes alone do not require a second factor.
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Recovery codes must not require a second factor by themselves. Error: %s\n", err)
+	}
+
+	previous := codes
+	if codes, _ = userRealm.RegenerateRecoveryCodes(credential, 3); len(credential.RecoveryCodes()) != 3 {
+		t.Errorf("Regenerating the codes must replace them. Got: %d\n", len(credential.RecoveryCodes()))
+	}
+	if recoverycodes.Factor(0).Verify(credential, previous[0]) != twofactor.ErrBadCode {
+		t.Error("The previous codes must not be valid after regenerating them")
+	}
+}
+
+func TestRecoveryCodesAlphabet(t *testing.T) {
+	codes, err := recoverycodes.Generate(200)
+	if err != nil {
+		t.Fatalf("The codes must be generated. Error: %s\n", err)
+	}
+	seen := map[rune]bool{}
+	for _, code := range codes {
+		if len(code) != 9 || code[4] != '-' {
+			t.Fatalf("Unexpected code format: %s\n", code)
+		}
+		for _, character := range strings.Replace(code, "-", "", 1) {
+			seen[character] = true
+		}
+	}
+	// Each one of the 31 characters is drawn with the same chance,
+	// so all of them show up among 1600 characters.
+	if len(seen) != 31 || seen['0'] || seen['o'] || seen['1'] || seen['l'] || seen['i'] {
+		t.Errorf("The codes must use the whole alphabet, and only it. Got %d characters\n", len(seen))
+	}
+}
+
+func TestRecoveryCodesAsSecondFactor(t *testing.T) {
+	now := time.Now()
+	factor := totp.NewFactor("Example")
+	factor.Now = func() time.Time { return now }
+	userRealm, broker := MakeTwoFactorExampleInstances(factor, recoverycodes.Factor(0))
+	enrollTOTP(t, userRealm, factor)
+	credential, _ := userRealm.ByIdentifier("U1")
+	codes, _ := userRealm.RegenerateRecoveryCodes(credential, 0)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	required := err.(*realms.SecondFactorRequiredError)
+	if len(required.Factors) != 2 || required.Factors[1] != "recovery_code" {
+		t.Errorf("Unexpected factors: %v\n", required.Factors)
+	}
+	saves := broker.SaveCalls
+	if _, err := userRealm.CompleteLogin(required.Handle, " "+strings.ToUpper(codes[3])+" "); err != nil {
+		t.Fatalf("A recovery code must be accepted. Error: %s\n", err)
+	} else if len(credential.RecoveryCodes()) != recoverycodes.DefaultCount-1 || broker.SaveCalls != saves+1 {
+		t.Errorf("The recovery code must be consumed and saved. Codes left: %d\n", len(credential.RecoveryCodes()))
+	}
+
+	_, err = userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	if _, err := userRealm.CompleteLogin(handle, codes[3]); err != twofactor.ErrBadCode {
+		t.Errorf("A used recovery code must fail with twofactor.ErrBadCode. Error: %v\n", err)
+	}
+
+	// When saving fails, the code is not consumed.
+	broker.Err = errors.New("storage is down")
+	if _, err := userRealm.CompleteLogin(handle, codes[4]); err != broker.Err {
+		t.Errorf("The save error must be returned. Error: %v\n", err)
+	} else if len(credential.RecoveryCodes()) != recoverycodes.DefaultCount-1 {
+		t.Errorf("The recovery code must be kept when saving fails. Codes left: %d\n", len(credential.RecoveryCodes()))
+	}
+}
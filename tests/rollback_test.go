@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/tagged"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/realms"
+	"testing"
+	"time"
+)
+
+var errSaveFailed = errors.New("save failed")
+
+func TestRollbackSetPassword(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	hashed := credential.HashedPassword()
+	broker.Err = errSaveFailed
+	if err := userRealm.SetPassword(credential, "user1$456", nil); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %s\n", err)
+	}
+	if credential.HashedPassword() != hashed {
+		t.Error("The password must be restored when the save fails")
+	}
+}
+
+func TestRollbackUnsetPassword(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	broker.Err = errSaveFailed
+	_ = userRealm.UnsetPassword(credential, nil)
+	broker.Err = nil
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("The password must be restored when the save fails. Error: %s\n", err)
+	}
+}
+
+func TestRollbackPreparePasswordReset(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	broker.Err = errSaveFailed
+	if err := userRealm.PreparePasswordReset(credential, "def456", time.Hour, nil); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %s\n", err)
+	}
+	if token := credential.(recoverable.Recoverable).RecoveryToken(); token != "abc123" {
+		t.Errorf("The previous token must be restored when the save fails. Token: %s\n", token)
+	}
+}
+
+func TestRollbackConfirmPasswordReset(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.Login("U1", "user1$123")
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	expiration := credential.(recoverable.Expiring).RecoveryTokenExpiration()
+	broker.Err = errSaveFailed
+	if err := userRealm.ConfirmPasswordReset(credential, "abc123", "user1$456"); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %s\n", err)
+	}
+	if token := credential.(recoverable.Recoverable).RecoveryToken(); token != "abc123" {
+		t.Errorf("The consumed token must be restored when the save fails. Token: %s\n", token)
+	} else if restored := credential.(recoverable.Expiring).RecoveryTokenExpiration(); restored.Sub(expiration) > time.Second {
+		t.Errorf("The token expiration must be restored. Expected: %s, restored: %s\n", expiration, restored)
+	}
+
+	broker.Err = nil
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("The password must be restored when the save fails. Error: %s\n", err)
+	}
+	if err := userRealm.ConfirmPasswordReset(credential, "abc123", "user1$456"); err != nil {
+		t.Errorf("The restored token must still be usable. Error: %s\n", err)
+	}
+}
+
+func TestRollbackPunishExactly(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.ByIdentifier("U4")
+	punishedOn, punishedFor, reason, by := credential.(deniable.Punishable).PunishedFor()
+	broker.Err = errSaveFailed
+	if err := userRealm.Punish(credential, punishedFor, "Another reason", nil); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %s\n", err)
+	}
+	restoredOn, restoredFor, restoredReason, restoredBy := credential.(deniable.Punishable).PunishedFor()
+	if !restoredOn.Equal(*punishedOn) || *restoredFor != *punishedFor || restoredReason != reason || restoredBy != by {
+		t.Errorf("The punishment must be restored exactly. Got: %v, %v, %v, %v\n", restoredOn, restoredFor, restoredReason, restoredBy)
+	}
+}
+
+func TestRollbackRecoveryTokenExactly(t *testing.T) {
+	userRealm, broker := MakeFailingExampleInstances()
+
+	credential, _ := userRealm.ByIdentifier("U1")
+	_ = userRealm.PreparePasswordReset(credential, "abc123", time.Hour, nil)
+	expiration := credential.(recoverable.Expiring).RecoveryTokenExpiration()
+	broker.Err = errSaveFailed
+	_ = userRealm.CancelPasswordReset(credential, nil)
+	if restored := credential.(recoverable.Expiring).RecoveryTokenExpiration(); !restored.Equal(expiration) {
+		t.Errorf("The token expiration must be restored exactly. Expected: %s, restored: %s\n", expiration, restored)
+	}
+}
+
+func TestRollbackTagged(t *testing.T) {
+	hashed, _ := DummyHasher(0).Hash("tagged$123")
+	user := &TaggedUser{Login: "T1", Password: hashed, Enabled: true}
+	factory := func() credentials.Credential {
+		return tagged.MustWrap(&TaggedUser{}, DummyHasher(0))
+	}
+	broker := &taggedBroker{stored: tagged.MustWrap(user, DummyHasher(0)), err: errSaveFailed}
+	taggedRealm := realms.NewTypedRealm(credentials.NewTypedSource(broker, factory))
+
+	credential, _ := taggedRealm.ByIdentifier("T1")
+	if _, err := taggedRealm.Anonymize(credential, "tombstone"); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error returned instead: %v\n", err)
+	}
+	if user.Login != "T1" || user.Password != hashed || !user.Enabled {
+		t.Errorf("The tagged struct must be restored when the save fails. Got: %+v\n", user)
+	}
+}
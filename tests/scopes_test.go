@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/events"
+	"testing"
+)
+
+func TestGrantAndRevokeScopes(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	adminRealm := sampleRealms[0]
+	recorder := &eventRecorder{}
+	adminRealm.Subscribe(recorder.Handle)
+
+	granter, _ := adminRealm.ByIdentifier("SU")
+	credential, _ := adminRealm.ByIdentifier("S1")
+	admin := credential.(*Admin)
+	previous := admin.Scopes()
+	if err := adminRealm.GrantScope(credential, DummyScope(5), granter); err != nil {
+		t.Fatalf("Granting a scope must not fail. Error: %s\n", err)
+	}
+	if _, ok := admin.Scopes()[DummyScope(5).Key()]; !ok || len(admin.Scopes()) != 3 || len(previous) != 2 {
+		t.Errorf("The scope must be granted in a new map. Got: %v\n", admin.Scopes())
+	}
+	_ = adminRealm.GrantScope(credential, DummyScope(5), granter)
+	if err := adminRealm.RevokeScope(credential, DummyScope(2).Key(), nil); err != nil {
+		t.Fatalf("Revoking a scope must not fail. Error: %s\n", err)
+	}
+	_ = adminRealm.RevokeScope(credential, DummyScope(2).Key(), nil)
+	if _, ok := admin.Scopes()[DummyScope(2).Key()]; ok || len(admin.Scopes()) != 2 {
+		t.Errorf("The scope must be revoked. Got: %v\n", admin.Scopes())
+	}
+
+	published := recorder.Take()
+	if len(published) != 2 {
+		t.Fatalf("Only the changes must publish events. Got: %d\n", len(published))
+	}
+	if granted, ok := published[0].(*events.ScopeGranted); !ok || granted.Credential != credential ||
+		granted.Scope != DummyScope(5).Key() || granted.By != granter {
+		t.Errorf("Granting must publish ScopeGranted. Got: %#v\n", published[0])
+	}
+	if revoked, ok := published[1].(*events.ScopeRevoked); !ok || revoked.Scope != DummyScope(2).Key() || revoked.By != nil {
+		t.Errorf("Revoking must publish ScopeRevoked. Got: %#v\n", published[1])
+	}
+}
+
+func TestGrantScopeRollback(t *testing.T) {
+	broker := MakeUserExampleBroker()
+	adminRealm := realms.NewRealm(credentials.NewSource(broker, &Admin{}))
+	credential, _ := adminRealm.ByIdentifier("S1")
+	broker.Err = errSaveFailed
+	if err := adminRealm.GrantScope(credential, DummyScope(5), nil); err != errSaveFailed {
+		t.Errorf("The save error must be returned. Error: %v\n", err)
+	}
+	if len(credential.(*Admin).Scopes()) != 2 {
+		t.Errorf("The scopes must be restored when the save fails. Got: %v\n", credential.(*Admin).Scopes())
+	}
+}
+
+func TestScopesNotEditable(t *testing.T) {
+	_, sampleRealms := MakeUserExampleInstances()
+	credential, _ := sampleRealms[1].ByIdentifier("U1")
+	if err := sampleRealms[1].GrantScope(credential, DummyScope(5), nil); err != realms.ErrNotScopeEditable {
+		t.Errorf("Granting scopes to a non-scoped credential must fail with realms.ErrNotScopeEditable. Error: %v\n", err)
+	}
+	admin, _ := sampleRealms[0].ByIdentifier("S1")
+	if err := sampleRealms[0].GrantScope(admin, nil, nil); err != realms.ErrNilScope {
+		t.Errorf("Granting a nil scope must fail with realms.ErrNilScope. Error: %v\n", err)
+	}
+}
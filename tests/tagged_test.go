@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/universe-10th/identity/authreqs/staff"
+	"github.com/universe-10th/identity/authreqs/superuser"
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/tagged"
+	"github.com/universe-10th/identity/credentials/traits/deniable"
+	"github.com/universe-10th/identity/credentials/traits/identified"
+	"github.com/universe-10th/identity/credentials/traits/recoverable"
+	"github.com/universe-10th/identity/credentials/traits/scoped"
+	"github.com/universe-10th/identity/credentials/traits/versioned"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+type TaggedUser struct {
+	Login      string                  `identity:"identifier"`
+	Password   string                  `identity:"password"`
+	Enabled    bool                    `identity:"active"`
+	Token      string                  `identity:"recovery_token"`
+	Expiration time.Time               `identity:"recovery_expiration"`
+	BannedOn   *time.Time              `identity:"punished_on"`
+	BannedFor  *time.Duration          `identity:"punished_for"`
+	BanReason  string                  `identity:"punishment_reason"`
+	Superuser  bool                    `identity:"superuser"`
+	Grants     map[string]scoped.Scope `identity:"scopes"`
+	Untagged   int
+}
+
+type BadTaggedUser struct {
+	Password int `identity:"password"`
+}
+
+func TestTaggedTraits(t *testing.T) {
+	credential, err := tagged.Wrap(&TaggedUser{Login: "T1", Superuser: true}, DummyHasher(0))
+	if err != nil {
+		t.Fatalf("Wrapping a well-tagged struct must succeed. Error: %s\n", err)
+	}
+
+	if _, ok := credential.(deniable.Activable); !ok {
+		t.Error("The credential must be activable")
+	}
+	if _, ok := credential.(deniable.Punishable); !ok {
+		t.Error("The credential must be punishable")
+	}
+	if _, ok := credential.(recoverable.Expiring); !ok {
+		t.Error("The credential must be recoverable, with expiring tokens")
+	}
+	if identifiedCred, ok := credential.(identified.Identified); !ok || identifiedCred.Identification() != "T1" {
+		t.Error("The credential must be identified, as T1")
+	}
+	if _, ok := credential.(versioned.Versioned); ok {
+		t.Error("The credential must not be versioned")
+	}
+	if !superuser.RequireSuperuser.SatisfiedBy(credential) {
+		t.Error("The credential must satisfy the superuser requirement")
+	}
+	if staff.RequireStaff.SatisfiedBy(credential) {
+		t.Error("The credential must not satisfy the staff requirement")
+	}
+}
+
+func TestTaggedFields(t *testing.T) {
+	user := &TaggedUser{Login: "T1"}
+	credential := tagged.MustWrap(user, DummyHasher(0))
+	if tagged.Unwrap(credential) != user {
+		t.Error("Unwrapping the credential must return the wrapped struct")
+	}
+
+	credential.SetHashedPassword("abc")
+	credential.(deniable.Activable).SetActive(true)
+	credential.(recoverable.Recoverable).SetRecoveryToken("def", time.Hour)
+	duration := time.Hour
+	credential.(deniable.Punishable).Punish(&duration, "spam", nil)
+	if user.Password != "abc" || !user.Enabled || user.Token != "def" || user.BannedOn == nil ||
+		*user.BannedFor != duration || user.BanReason != "spam" {
+		t.Errorf("The fields must be updated through the traits. Got: %+v\n", user)
+	}
+
+	credential.(recoverable.Recoverable).SetRecoveryToken("def", -time.Hour)
+	if token := credential.(recoverable.Recoverable).RecoveryToken(); token != "" || user.Token != "" {
+		t.Error("An expired token must be cleared")
+	}
+	credential.(deniable.Punishable).Unpunish()
+	if user.BannedOn != nil || user.BannedFor != nil || user.BanReason != "" {
+		t.Errorf("The punishment fields must be cleared. Got: %+v\n", user)
+	}
+
+	credential.(identified.Reidentifiable).SetIdentification("T2")
+	credential.(scoped.Editable).SetScopes(map[string]scoped.Scope{DummyScope(1).Key(): DummyScope(1)})
+	if user.Login != "T2" || len(user.Grants) != 1 {
+		t.Errorf("The identifier and scopes must be updated through the traits. Got: %+v\n", user)
+	}
+	credential.(identified.Reidentifiable).SetIdentification(2)
+	if user.Login != "" {
+		t.Errorf("An identification of another type must clear the identifier. Got: %+v\n", user)
+	}
+}
+
+func TestTaggedCombinationsGenerated(t *testing.T) {
+	command := exec.Command("go", "run", "./internal/gen", "-check")
+	command.Dir = "../credentials/tagged"
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Errorf("The tagged combinations must be up to date. Error: %s, output: %s\n", err, output)
+	}
+}
+
+func TestTaggedErrors(t *testing.T) {
+	if _, err := tagged.Wrap(TaggedUser{}, DummyHasher(0)); err != tagged.ErrNotStructPointer {
+		t.Errorf("Wrapping a non-pointer must fail with tagged.ErrNotStructPointer. Error: %s\n", err)
+	}
+	if _, err := tagged.Wrap(&TaggedUser{}, nil); err != tagged.ErrNoHasher {
+		t.Errorf("Wrapping with no hasher must fail with tagged.ErrNoHasher. Error: %s\n", err)
+	}
+	if _, err := tagged.Wrap(&BadTaggedUser{}, DummyHasher(0)); err == nil {
+		t.Error("Wrapping a struct with a mistyped field must fail")
+	} else if _, ok := err.(*tagged.FieldError); !ok {
+		t.Errorf("Wrapping a struct with a mistyped field must fail with a *tagged.FieldError. Error: %s\n", err)
+	}
+}
+
+func TestTaggedSource(t *testing.T) {
+	hashed, _ := DummyHasher(0).Hash("tagged$123")
+	stored := tagged.MustWrap(&TaggedUser{Login: "T1", Password: hashed, Enabled: true}, DummyHasher(0))
+	source := credentials.NewTypedSource(&taggedBroker{stored: stored}, func() credentials.Credential {
+		return tagged.MustWrap(&TaggedUser{}, DummyHasher(0))
+	})
+	if dummy := source.Dummy(); dummy.HashedPassword() != "" {
+		t.Error("The dummy credential must be usable")
+	}
+	if credential, err := source.ByIdentifier("T1"); err != nil || credential != stored {
+		t.Errorf("The stored credential must be found. Got: %v, %v\n", credential, err)
+	}
+}
+
+func TestTaggedUntypedSource(t *testing.T) {
+	defer func() {
+		if recovered := recover(); recovered != credentials.ErrNoReflectiveDummy {
+			t.Errorf("Creating an untyped source for a tagged credential must panic with "+
+				"credentials.ErrNoReflectiveDummy. Recovered: %v\n", recovered)
+		}
+	}()
+	credentials.NewSource(&taggedBroker{}, tagged.MustWrap(&TaggedUser{}, DummyHasher(0)))
+}
+
+// A user embedding an interface not supplying credential methods.
+type describedUser struct {
+	User
+	fmt.Stringer
+}
+
+// A credential supplied through an embedded pointer to a struct
+// embedding the credential interface.
+type delegatingUser struct {
+	*credentialHolder
+}
+
+type credentialHolder struct {
+	credentials.Credential
+}
+
+func TestUntypedSourceEmbeddedInterfaces(t *testing.T) {
+	source := credentials.NewSource(NewMemoryBroker(&describedUser{}), &describedUser{})
+	if dummy := source.Dummy(); dummy.HashedPassword() != "" {
+		t.Error("The dummy credential must be usable")
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != credentials.ErrNoReflectiveDummy {
+			t.Errorf("Creating an untyped source for a credential supplied by an embedded interface must panic with "+
+				"credentials.ErrNoReflectiveDummy. Recovered: %v\n", recovered)
+		}
+	}()
+	credentials.NewSource(NewMemoryBroker(&delegatingUser{}), &delegatingUser{})
+}
+
+type taggedBroker struct {
+	stored credentials.Credential
+	err    error
+}
+
+func (broker *taggedBroker) Allows(template credentials.Credential) bool {
+	_, ok := tagged.Unwrap(template).(*TaggedUser)
+	return ok
+}
+
+func (broker *taggedBroker) ByIdentifier(identifier interface{}, template credentials.Credential) (credentials.Credential, error) {
+	if broker.stored.(identified.Identified).Identification() == identifier {
+		return broker.stored, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func (broker *taggedBroker) ByIndex(index interface{}, template credentials.Credential) (credentials.Credential, error) {
+	return nil, nil
+}
+
+func (broker *taggedBroker) Save(credential credentials.Credential) error {
+	return broker.err
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"errors"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/twofactor"
+	"github.com/universe-10th/identity/realms/twofactor/totp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPVectors(t *testing.T) {
+	// RFC 6238, appendix B (SHA1 only).
+	secret := []byte("12345678901234567890")
+	factor := &totp.Factor{Period: 30 * time.Second, Digits: 8}
+	for unix, expected := range map[int64]string{
+		59: "94287082", 1111111109: "07081804", 1111111111: "14050471",
+		1234567890: "89005924", 2000000000: "69279037", 20000000000: "65353130",
+	} {
+		if code := totp.Code(secret, factor.Step(time.Unix(unix, 0)), 8); code != expected {
+			t.Errorf("Unexpected code at %d. Expected: %s, got: %s\n", unix, expected, code)
+		}
+	}
+}
+
+// Enrolls the U1 credential and returns its secret.
+func enrollTOTP(t *testing.T, userRealm *realms.TypedRealm[*TwoFactorUser], factor *totp.Factor) []byte {
+	credential, _ := userRealm.ByIdentifier("U1")
+	uri, err := userRealm.EnrollTOTP(credential, factor, "")
+	if err != nil {
+		t.Fatalf("The enrollment must start. Error: %s\n", err)
+	}
+	parsed, _ := url.Parse(uri)
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Example:U1" || parsed.Query().Get("issuer") != "Example" {
+		t.Errorf("Unexpected enrollment URI: %s\n", uri)
+	}
+	secret, enrolled := credential.TOTPSecret()
+	if enrolled {
+		t.Error("The secret must not be enrolled before confirming it")
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("A login must not require a second factor before confirming the enrollment. Error: %s\n", err)
+	}
+	if err := userRealm.ConfirmTOTP(credential, factor, "000000x"); err != twofactor.ErrBadCode {
+		t.Errorf("The enrollment must not be confirmed with a bad code. Error: %v\n", err)
+	}
+	if err := userRealm.ConfirmTOTP(credential, factor, totp.Code(secret, factor.Step(factor.Now()), 6)); err != nil {
+		t.Fatalf("The enrollment must be confirmed. Error: %s\n", err)
+	}
+	return secret
+}
+
+func TestTOTPLogin(t *testing.T) {
+	now := time.Now()
+	factor := totp.NewFactor("Example")
+	factor.Now = func() time.Time { return now }
+	userRealm, _ := MakeTwoFactorExampleInstances(factor)
+	secret := enrollTOTP(t, userRealm, factor)
+
+	now = now.Add(3 * factor.Period)
+	_, err := userRealm.Login("U1", "user1$123")
+	required, ok := err.(*realms.SecondFactorRequiredError)
+	if !ok {
+		t.Fatalf("A login must require the second factor. Error: %v\n", err)
+	} else if len(required.Factors) != 1 || required.Factors[0] != "totp" {
+		t.Errorf("Unexpected factors: %v\n", required.Factors)
+	}
+	if _, err := userRealm.CompleteLogin("bad-handle", "123456"); err != realms.ErrBadChallenge {
+		t.Errorf("An unknown handle must fail with realms.ErrBadChallenge. Error: %v\n", err)
+	}
+	if _, err := userRealm.CompleteLogin(required.Handle, totp.Code(secret, factor.Step(now)-2, 6)); err != twofactor.ErrBadCode {
+		t.Errorf("A code outside the skew must fail with twofactor.ErrBadCode. Error: %v\n", err)
+	}
+	if credential, err := userRealm.CompleteLogin(required.Handle, totp.Code(secret, factor.Step(now)-1, 6)); err != nil {
+		t.Fatalf("A code within the skew must succeed. Error: %s\n", err)
+	} else if credential.TOTPLastStep() != factor.Step(now)-1 {
+		t.Errorf("The accepted step must be recorded. Got: %d\n", credential.TOTPLastStep())
+	}
+	if _, err := userRealm.CompleteLogin(required.Handle, totp.Code(secret, factor.Step(now), 6)); err != realms.ErrBadChallenge {
+		t.Errorf("A redeemed handle must fail with realms.ErrBadChallenge. Error: %v\n", err)
+	}
+
+	// Replays of the same (or an earlier) step are rejected.
+	_, err = userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)-1, 6)); err != twofactor.ErrBadCode {
+		t.Errorf("A replayed code must fail with twofactor.ErrBadCode. Error: %v\n", err)
+	}
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now), 6)); err != nil {
+		t.Errorf("A newer code must succeed. Error: %v\n", err)
+	}
+}
+
+func TestTOTPChallengeLimits(t *testing.T) {
+	now := time.Now()
+	factor := totp.NewFactor("Example")
+	factor.Now = func() time.Time { return now }
+	userRealm, broker := MakeTwoFactorExampleInstances(factor)
+	secret := enrollTOTP(t, userRealm, factor)
+	userRealm.SetChallengeLimits(50*time.Millisecond, 2)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	saves := broker.SaveCalls
+	_, _ = userRealm.CompleteLogin(handle, "bad")
+	_, _ = userRealm.CompleteLogin(handle, "bad")
+	if broker.SaveCalls != saves {
+		t.Error("Failed attempts must not save the credential")
+	}
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != realms.ErrBadChallenge {
+		t.Errorf("A handle must not be redeemed after running out of attempts. Error: %v\n", err)
+	}
+
+	// Logging in again does not grant more attempts.
+	_, err = userRealm.Login("U1", "user1$123")
+	handle = err.(*realms.SecondFactorRequiredError).Handle
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != realms.ErrBadChallenge {
+		t.Errorf("A new handle must not be redeemed while the credential is out of attempts. Error: %v\n", err)
+	}
+
+	_, err = userRealm.Login("U1", "user1$123")
+	handle = err.(*realms.SecondFactorRequiredError).Handle
+	time.Sleep(100 * time.Millisecond)
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != realms.ErrBadChallenge {
+		t.Errorf("An expired handle must fail with realms.ErrBadChallenge. Error: %v\n", err)
+	}
+
+	// Disabling TOTP makes the login single-staged again.
+	credential, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.DisableTOTP(credential, nil); err != nil {
+		t.Fatalf("TOTP must be disabled. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("A login must not require a second factor after disabling it. Error: %s\n", err)
+	}
+}
+
+func TestTOTPDeniedBeforeCompletion(t *testing.T) {
+	now := time.Now()
+	factor := totp.NewFactor("Example")
+	factor.Now = func() time.Time { return now }
+	userRealm, _ := MakeTwoFactorExampleInstances(factor)
+	secret := enrollTOTP(t, userRealm, factor)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	credential, _ := userRealm.ByIdentifier("U1")
+	if err := userRealm.SetActive(credential, false, nil); err != nil {
+		t.Fatalf("The credential must be deactivated. Error: %s\n", err)
+	}
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != realms.ErrLoginFailed {
+		t.Errorf("A credential deactivated after the first stage must fail with realms.ErrLoginFailed. Error: %v\n", err)
+	}
+	_ = userRealm.SetActive(credential, true, nil)
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != realms.ErrBadChallenge {
+		t.Errorf("A denied challenge must be discarded. Error: %v\n", err)
+	}
+}
+
+func TestTOTPFactorDefaults(t *testing.T) {
+	factor := &totp.Factor{Period: time.Millisecond}
+	secret := []byte("12345678901234567890")
+	if step := factor.Step(time.Unix(59, 0)); step != 1 {
+		t.Errorf("Periods under a second must default to totp.DefaultPeriod. Step: %d\n", step)
+	}
+	if code := totp.Code(secret, 1, 0); len(code) != totp.DefaultDigits {
+		t.Errorf("Non-positive digits must default to totp.DefaultDigits. Code: %s\n", code)
+	}
+	if code := totp.Code(secret, 1, 12); len(code) != 12 || !strings.HasPrefix(code, "00") {
+		t.Errorf("Codes with many digits must be padded, not overflow. Code: %s\n", code)
+	}
+	parsed, _ := url.Parse(factor.URI(secret, "U1"))
+	if parsed.Query().Get("digits") != "6" || parsed.Query().Get("period") != "30" {
+		t.Errorf("The URI must tell the default settings. Got: %s\n", parsed.RawQuery)
+	}
+}
+
+func TestTOTPRollback(t *testing.T) {
+	now := time.Now()
+	factor := totp.NewFactor("Example")
+	factor.Now = func() time.Time { return now }
+	userRealm, broker := MakeTwoFactorExampleInstances(factor)
+	secret := enrollTOTP(t, userRealm, factor)
+
+	_, err := userRealm.Login("U1", "user1$123")
+	handle := err.(*realms.SecondFactorRequiredError).Handle
+	broker.Err = errors.New("storage is down")
+	if _, err := userRealm.CompleteLogin(handle, totp.Code(secret, factor.Step(now)+1, 6)); err != broker.Err {
+		t.Errorf("The save error must be returned. Error: %v\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if credential.TOTPLastStep() != factor.Step(now) {
+		t.Errorf("The last step must be rolled back when saving fails. Got: %d\n", credential.TOTPLastStep())
+	}
+	if !strings.HasPrefix(err.Error(), "second factor") {
+		t.Errorf("Unexpected error message: %s\n", err)
+	}
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/credentials/tagged"
+	"github.com/universe-10th/identity/credentials/traits/traittest"
+	"testing"
+)
+
+func TestTraitsContractGenerated(t *testing.T) {
+	traittest.Run(t, func() credentials.Credential { return &GeneratedUser{} })
+	traittest.Run(t, func() credentials.Credential { return &ConcreteUser{} })
+}
+
+func TestTraitsContractTagged(t *testing.T) {
+	traittest.Run(t, func() credentials.Credential { return tagged.MustWrap(&TaggedUser{}, DummyHasher(0)) })
+}
+
+func TestTraitsContractHandWritten(t *testing.T) {
+	traittest.Run(t, func() credentials.Credential { return &User{} })
+	traittest.Run(t, func() credentials.Credential { return &VersionedUser{} })
+}
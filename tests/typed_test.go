@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"github.com/universe-10th/identity/credentials"
+	"github.com/universe-10th/identity/realms"
+	"testing"
+)
+
+func TestTypedLogin(t *testing.T) {
+	userRealm := MakeTypedExampleInstances()
+
+	if user, err := userRealm.Login("U1", "user1$123"); err != nil {
+		t.Errorf("Login for user U1 must succeed. Error: %s\n", err)
+	} else if user.identifier != "U1" {
+		t.Errorf("Login for user U1 must return the U1 user. Returned instead: %s\n", user.identifier)
+	}
+
+	if user, err := userRealm.Login("U9", "user1$123"); err != realms.ErrLoginFailed {
+		t.Errorf("Login for user U9 must fail with realm.ErrLoginFailed. Error: %s\n", err)
+	} else if user != nil {
+		t.Error("A failed login must return the zero value of the credential type")
+	}
+}
+
+func TestTypedSetPassword(t *testing.T) {
+	userRealm := MakeTypedExampleInstances()
+
+	user, _ := userRealm.Login("U1", "user1$123")
+	if err := userRealm.SetPassword(user, "user1$456", nil); err != nil {
+		t.Errorf("Password change must succeed. Error: %s\n", err)
+	}
+	if _, err := userRealm.Login("U1", "user1$456"); err != nil {
+		t.Errorf("After password change, the new password attempt must return no error. Error returned instead: %s\n", err)
+	}
+}
+
+func TestTypedLookups(t *testing.T) {
+	users := credentials.NewTypedSource(MakeUserExampleBroker(), func() *User { return &User{} })
+
+	if user, found, err := users.LookupByIndex(3); err != nil || !found || user.identifier != "U3" {
+		t.Errorf("Lookup by index 3 must find U3. Got: %v, %v, %v\n", user, found, err)
+	}
+	if user, found, err := users.LookupByIndex(9); err != nil || found || user != nil {
+		t.Errorf("Lookup by index 9 must find nothing. Got: %v, %v, %v\n", user, found, err)
+	}
+	if dummy := users.Dummy(); dummy == nil || dummy.identifier != "" {
+		t.Error("The dummy must be a new, empty, user")
+	}
+	if err := users.Save(nil); err != credentials.ErrNilValueOnSave {
+		t.Errorf("Saving nil must fail with credentials.ErrNilValueOnSave. Error: %s\n", err)
+	}
+}
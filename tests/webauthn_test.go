@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/universe-10th/identity/realms"
+	"github.com/universe-10th/identity/realms/lockout"
+	"github.com/universe-10th/identity/realms/login"
+	"github.com/universe-10th/identity/realms/ratelimit"
+	"github.com/universe-10th/identity/realms/webauthn"
+	"github.com/universe-10th/identity/realms/webauthn/cbor"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tells whether the options allow the public key credential.
+func allows(options *webauthn.RequestOptions, id []byte) bool {
+	for _, descriptor := range options.Allow {
+		if bytes.Equal(descriptor.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registers a new public key credential of the authenticator for
+// the given user, and returns its ID.
+func registerWebAuthn(t *testing.T, userRealm *realms.TypedRealm[*TwoFactorUser], relyingParty *webauthn.RelyingParty,
+	authenticator *SoftwareAuthenticator, identifier string) []byte {
+	credential, _ := userRealm.ByIdentifier(identifier)
+	options, err := userRealm.BeginWebAuthnRegistration(credential, relyingParty)
+	if err != nil {
+		t.Fatalf("The registration must start. Error: %s\n", err)
+	}
+	response := authenticator.Register(options)
+	if registration, err := userRealm.FinishWebAuthnRegistration(credential, relyingParty, response); err != nil {
+		t.Fatalf("The registration must succeed. Error: %s\n", err)
+	} else if !bytes.Equal(registration.Credential.ID, response.ID) {
+		t.Errorf("Unexpected registered ID: %x\n", registration.Credential.ID)
+	}
+	return response.ID
+}
+
+func TestWebAuthnCeremonies(t *testing.T) {
+	for _, authenticator := range []*SoftwareAuthenticator{
+		NewSoftwareAuthenticator(webauthn.ES256, "none"),
+		NewSoftwareAuthenticator(webauthn.ES256, "packed"),
+		NewSoftwareAuthenticator(webauthn.EdDSA, "packed"),
+		NewSoftwareAuthenticator(webauthn.ES256, "packed-x5c"),
+	} {
+		userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+		id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+		options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+		if len(options.Allow) != webauthn.DefaultAllowListSize || !allows(options, id) {
+			t.Errorf("The registered credential must be allowed, padded with fake ones. Got: %v\n", options.Allow)
+		}
+		for _, descriptor := range options.Allow {
+			if len(descriptor.ID) != len(id) {
+				t.Errorf("The fake credentials must be like the registered ones. Got: %v\n", options.Allow)
+			}
+		}
+		if credential, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != nil {
+			t.Errorf("The login must succeed (%d, %s). Error: %s\n", authenticator.Algorithm, authenticator.Format, err)
+		} else if credential.WebAuthnCredentials()[0].SignCount != authenticator.Counter {
+			t.Errorf("The signature counter must be updated. Got: %d\n", credential.WebAuthnCredentials()[0].SignCount)
+		}
+	}
+}
+
+func TestWebAuthnAttestationCertificates(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "packed-x5c")
+	credential, _ := userRealm.ByIdentifier("U1")
+	options, _ := userRealm.BeginWebAuthnRegistration(credential, relyingParty)
+	if registration, err := userRealm.FinishWebAuthnRegistration(credential, relyingParty, authenticator.Register(options)); err != nil {
+		t.Fatalf("The registration must succeed. Error: %s\n", err)
+	} else if len(registration.Certificates) != 1 || !bytes.Equal(registration.AAGUID, authenticator.AAGUID) {
+		t.Errorf("The attestation certificate and AAGUID must be returned. Got: %v\n", registration)
+	}
+
+}
+
+func TestWebAuthnSignCountRegression(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	_, _ = userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id))
+
+	// A cloned authenticator reports an older counter.
+	authenticator.Counter = 0
+	options, _ = userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != webauthn.ErrSignCountRegression {
+		t.Errorf("A counter regression must fail with webauthn.ErrSignCountRegression. Error: %v\n", err)
+	}
+	credential, _ := userRealm.ByIdentifier("U1")
+	if credential.WebAuthnCredentials()[0].SignCount != 1 {
+		t.Errorf("The stored counter must be kept. Got: %d\n", credential.WebAuthnCredentials()[0].SignCount)
+	}
+}
+
+func TestWebAuthnRejections(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	authenticator := NewSoftwareAuthenticator(webauthn.EdDSA, "packed")
+	id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+	// Replayed responses.
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	response := authenticator.Login(options, id)
+	_, _ = userRealm.FinishWebAuthnLogin(relyingParty, response)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, response); err != webauthn.ErrUnknownCeremony {
+		t.Errorf("A replayed response must fail with webauthn.ErrUnknownCeremony. Error: %v\n", err)
+	}
+
+	// Tampered signatures.
+	options, _ = userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	response = authenticator.Login(options, id)
+	response.Signature[0] ^= 0xff
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, response); err != webauthn.ErrBadSignature {
+		t.Errorf("A tampered signature must fail with webauthn.ErrBadSignature. Error: %v\n", err)
+	}
+
+	// Other origins and relying parties.
+	options, _ = userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	authenticator.Origin = "https://evil.example.net"
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != webauthn.ErrBadOrigin {
+		t.Errorf("Another origin must fail with webauthn.ErrBadOrigin. Error: %v\n", err)
+	}
+	authenticator.Origin, authenticator.RPID = "https://example.com", "evil.example.net"
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != webauthn.ErrBadRelyingParty {
+		t.Errorf("Another relying party must fail with webauthn.ErrBadRelyingParty. Error: %v\n", err)
+	}
+	authenticator.RPID = "example.com"
+
+	// Unknown credentials and users.
+	other := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	otherID := registerWebAuthn(t, userRealm, relyingParty, other, "U2")
+	options, _ = userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, other.Login(options, otherID)); err != realms.ErrLoginFailed {
+		t.Errorf("A credential of another user must fail with realms.ErrLoginFailed. Error: %v\n", err)
+	}
+	options, _ = userRealm.BeginWebAuthnLogin("U9", relyingParty)
+	if len(options.Allow) != webauthn.DefaultAllowListSize {
+		t.Fatalf("An unknown user must get options allowing fake credentials. Got: %d\n", len(options.Allow))
+	}
+	if again, _ := userRealm.BeginWebAuthnLogin("U9", relyingParty); !reflect.DeepEqual(again.Allow, options.Allow) {
+		t.Error("An unknown user must get the same fake credentials each time")
+	}
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, other.Login(options, otherID)); err != realms.ErrLoginFailed {
+		t.Errorf("An unknown user must fail with realms.ErrLoginFailed. Error: %v\n", err)
+	}
+
+	// The pipeline still applies.
+	options, _ = userRealm.BeginWebAuthnLogin("U2", relyingParty)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, other.Login(options, otherID)); err != realms.ErrLoginFailed {
+		t.Errorf("An inactive user must fail to login. Error: %v\n", err)
+	}
+}
+
+func TestWebAuthnRegistrationMismatch(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	first, _ := userRealm.ByIdentifier("U1")
+	second, _ := userRealm.ByIdentifier("U2")
+	options, _ := userRealm.BeginWebAuthnRegistration(first, relyingParty)
+	if _, err := userRealm.FinishWebAuthnRegistration(second, relyingParty, authenticator.Register(options)); err != realms.ErrWebAuthnUserMismatch {
+		t.Errorf("A registration of another user must fail with realms.ErrWebAuthnUserMismatch. Error: %v\n", err)
+	} else if len(second.WebAuthnCredentials()) != 0 {
+		t.Error("The credential must not be registered")
+	}
+
+	_, err := userRealm.BeginWebAuthnRegistration(first, relyingParty)
+	if err != nil {
+		t.Errorf("A WebAuthn capable credential must start registrations. Error: %s\n", err)
+	}
+	_, realmsList := MakeUserExampleInstances()
+	admin, _ := realmsList[0].ByIdentifier("S1")
+	if _, err := realmsList[0].BeginWebAuthnRegistration(admin, relyingParty); err != realms.ErrNotWebAuthnCapable {
+		t.Errorf("A non WebAuthn capable credential must fail with realms.ErrNotWebAuthnCapable. Error: %v\n", err)
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	value := map[interface{}]interface{}{
+		int64(1): int64(2), int64(-1): int64(-300), "text": "value", "bytes": []byte{1, 2, 3},
+		"list": []interface{}{true, false, nil, int64(1 << 40)},
+	}
+	if encoded, err := cbor.Encode(value); err != nil {
+		t.Fatalf("The value must be encoded. Error: %s\n", err)
+	} else if decoded, err := cbor.Decode(encoded); err != nil {
+		t.Fatalf("The value must be decoded. Error: %s\n", err)
+	} else if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("The decoded value must match. Got: %v\n", decoded)
+	} else if _, err := cbor.Decode(encoded[:len(encoded)-1]); err != cbor.ErrMalformed {
+		t.Errorf("Truncated data must fail with cbor.ErrMalformed. Error: %v\n", err)
+	}
+}
+
+func TestWebAuthnPaddedCredentials(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	relyingParty.AllowListSize = 2
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	first := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+	second := registerWebAuthn(t, userRealm, relyingParty, NewSoftwareAuthenticator(webauthn.ES256, "none"), "U1")
+	third := registerWebAuthn(t, userRealm, relyingParty, NewSoftwareAuthenticator(webauthn.ES256, "none"), "U1")
+
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if len(options.Allow) != 4 || !allows(options, first) || !allows(options, second) || !allows(options, third) {
+		t.Errorf("The registered credentials must be padded to a multiple of the allow list size. Got: %v\n", options.Allow)
+	}
+	if options, _ = userRealm.BeginWebAuthnLogin("U9", relyingParty); len(options.Allow) != 2 {
+		t.Errorf("An unknown user must get as many fake credentials as the allow list size. Got: %d\n", len(options.Allow))
+	}
+}
+
+func TestWebAuthnZeroRelyingParty(t *testing.T) {
+	userRealm, _, _ := MakeWebAuthnExampleInstances()
+	relyingParty := &webauthn.RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if options.Timeout != webauthn.DefaultTimeout.Milliseconds() {
+		t.Errorf("The default timeout must be used. Got: %d\n", options.Timeout)
+	}
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != nil {
+		t.Errorf("The ceremonies must not expire at once. Error: %s\n", err)
+	}
+}
+
+func TestWebAuthnRateLimit(t *testing.T) {
+	userRealm, broker, relyingParty := MakeWebAuthnExampleInstances()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	limiter.PerIdentifier = &ratelimit.Limit{Burst: 1, Interval: time.Minute}
+	userRealm.SetRateLimiter(limiter)
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err != nil {
+		t.Fatalf("The first login must not be limited. Error: %s\n", err)
+	}
+	options, _ = userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	calls := broker.ByIdentifierCalls
+	if _, err := userRealm.FinishWebAuthnLoginAttempt(relyingParty, authenticator.Login(options, id), &login.Attempt{}); err == nil {
+		t.Error("The second login must be limited")
+	} else if _, ok := err.(*ratelimit.RateLimitedError); !ok {
+		t.Errorf("The second login must fail with a *ratelimit.RateLimitedError. Error: %v\n", err)
+	}
+	if broker.ByIdentifierCalls != calls {
+		t.Error("Limited logins must not look the credential up")
+	}
+}
+
+func TestWebAuthnLockout(t *testing.T) {
+	userRealm, _, relyingParty := MakeWebAuthnExampleInstances()
+	userRealm.SetLockout(lockout.NewPolicy(2, time.Minute))
+	authenticator := NewSoftwareAuthenticator(webauthn.ES256, "none")
+	id := registerWebAuthn(t, userRealm, relyingParty, authenticator, "U1")
+
+	for attempt := 0; attempt < 2; attempt++ {
+		options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+		response := authenticator.Login(options, id)
+		response.Signature[0] ^= 0xff
+		_, _ = userRealm.FinishWebAuthnLogin(relyingParty, response)
+	}
+	options, _ := userRealm.BeginWebAuthnLogin("U1", relyingParty)
+	if _, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id)); err == nil {
+		t.Error("A locked out credential must fail to login")
+	} else if _, ok := err.(*lockout.LockedOutError); !ok {
+		t.Errorf("A locked out credential must fail with a *lockout.LockedOutError. Error: %v\n", err)
+	}
+
+	// Unknown users get locked out alike, on the failure
+	// reaching the threshold.
+	for attempt := 0; attempt < 3; attempt++ {
+		options, _ = userRealm.BeginWebAuthnLogin("U9", relyingParty)
+		_, err := userRealm.FinishWebAuthnLogin(relyingParty, authenticator.Login(options, id))
+		if _, ok := err.(*lockout.LockedOutError); ok != (attempt > 0) {
+			t.Errorf("Unexpected error for the unknown user (attempt %d): %v\n", attempt, err)
+		}
+	}
+}